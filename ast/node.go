@@ -38,12 +38,21 @@ type Node struct {
 	Children   []*Node  `json:",omitempty"` // 所有子节点
 	Tokens     []byte   `json:",omitempty"` // 词法分析结果 Tokens，语法分析阶段会继续操作这些 Tokens
 
+	// 源码位置
+
+	StartPos Position // 在原始输入中的起始位置
+	EndPos   Position // 在原始输入中的结束位置（不包含）
+
 	// 解析过程标识
 
 	Close           bool `json:",omitempty"` // 标识是否关闭
 	LastLineBlank   bool `json:",omitempty"` // 标识最后一行是否是空行
 	LastLineChecked bool `json:",omitempty"` // 标识最后一行是否检查过
 
+	// 用户自定义块
+
+	BlockExt BlockExt `json:"-"` // 生成该节点的用户自定义块级语法，由解析器设置
+
 	// 代码
 
 	CodeMarkerLen int `json:",omitempty"` // ` 个数，1 或 2
@@ -74,22 +83,29 @@ type Node struct {
 	// 表
 
 	TableAligns              []int  `json:",omitempty"` // 从左到右每个表格节点的对齐方式，0：默认对齐，1：左对齐，2：居中对齐，3：右对齐
+	TableColumnWidths        []int  `json:",omitempty"` // 从左到右每列的内容最大宽度
 	TableCellAlign           int    `json:",omitempty"` // 表的单元格对齐方式
 	TableCellContentWidth    int    `json:",omitempty"` // 表的单元格内容宽度（字节数）
 	TableCellContentMaxWidth int    `json:",omitempty"` // 表的单元格内容最大宽度
 	TableCellContent         []byte `json:",omitempty"` // 表的单元格内容
 	TableCellMaxWidthContent []byte `json:",omitempty"` // 表的单元格最大宽度格的内容
+	TableCellColspan         int    `json:",omitempty"` // 表的单元格跨列数，大于 1 时有效
+	TableCellRowspan         int    `json:",omitempty"` // 表的单元格跨行数，大于 1 时有效
+	TableCellMerged          bool   `json:",omitempty"` // 表的单元格是否是被上方单元格跨行合并的占位单元格 ^^
+	TableCaption             []byte `json:",omitempty"` // 表的标题
 
 	// 链接
 
 	LinkType     int    `json:",omitempty"` // 链接类型，0：内联链接 [foo](/bar)，1：链接引用定义 [foo]: /bar，2：自动链接，3：链接引用 [foo]
 	LinkRefLabel []byte `json:",omitempty"` // 链接引用 label，[label] 或者 [text][label] 形式，[label] 情况下 text 和 label 相同
+	LinkInlined  bool   `json:"-"`          // 图片地址是否是渲染完整文档时由本地图片内联生成的 data URI
 
 	// 标题
 
 	HeadingLevel        int    `json:",omitempty"` // 1~6
 	HeadingSetext       bool   `json:",omitempty"` // 是否为 Setext
 	HeadingNormalizedID string `json:",omitempty"` // 规范化后的 ID
+	HeadingNumber       string `json:",omitempty"` // 标题编号，开启标题编号时有效，比如 1.、1.1、1.1.2
 
 	// 数学公式块
 
@@ -101,6 +117,16 @@ type Node struct {
 	FootnotesRefId    string  `json:",omitempty"` // 脚注 id
 	FootnotesRefs     []*Node `json:",omitempty"` // 脚注引用
 
+	// 提示块
+
+	AdmonitionType     string `json:",omitempty"` // 提示块类型，比如 note、tip、warning
+	AdmonitionTitle    []byte `json:",omitempty"` // 提示块标题
+	AdmonitionFenceLen int    `json:",omitempty"` // 提示块标记符 : 个数，为 0 的话说明是 GitHub 风格的 > [!NOTE]
+
+	// Front Matter
+
+	FrontMatterType int `json:",omitempty"` // Front Matter 类型，0：YAML ---，1：TOML +++，2：JSON { }
+
 	// HTML 实体
 
 	HtmlEntityTokens []byte `json:",omitempty"` // 原始输入的实体 tokens，&amp;
@@ -109,6 +135,13 @@ type Node struct {
 	KramdownIAL [][]string
 }
 
+// Position 描述了节点在原始输入中的位置。
+type Position struct {
+	Line   int // 行号，从 1 开始
+	Column int // 列号，从 1 开始，按字节计
+	Offset int // 字节偏移，从 0 开始
+}
+
 // ListData 用于记录列表或列表项节点的附加信息。
 type ListData struct {
 	Typ          int    `json:",omitempty"` // 0：无序列表，1：有序列表，3：任务列表
@@ -134,6 +167,24 @@ func NewNodeID() string {
 	return now.Format("20060102150405") + "-" + randStr(7)
 }
 
+// IsNodeIDPattern 判断 str 是否符合 NewNodeID 生成的 ID 格式，即 14 位时间戳、连字符和 7 位小写字母或数字。
+func IsNodeIDPattern(str string) bool {
+	if len("20060102150405-1a2b3c4") != len(str) || '-' != str[14] {
+		return false
+	}
+	for i := 0; i < 14; i++ {
+		if '0' > str[i] || '9' < str[i] {
+			return false
+		}
+	}
+	for i := 15; i < len(str); i++ {
+		if c := str[i]; ('0' > c || '9' < c) && ('a' > c || 'z' < c) {
+			return false
+		}
+	}
+	return true
+}
+
 func init() {
 	rand.Seed(time.Now().UTC().UnixNano())
 }
@@ -344,10 +395,10 @@ func (n *Node) IsBlock() bool {
 	switch n.Type {
 	case NodeDocument, NodeParagraph, NodeHeading, NodeThematicBreak, NodeBlockquote, NodeList, NodeListItem, NodeHTMLBlock,
 		NodeCodeBlock, NodeTable, NodeMathBlock, NodeFootnotesDef, NodeToC, NodeYamlFrontMatter, NodeBlockEmbed, NodeBlockQueryEmbed,
-		NodeKramdownBlockIAL:
+		NodeKramdownBlockIAL, NodeAdmonition, NodeDefinitionList, NodeDefinitionTerm, NodeDefinitionDescription, NodeLinkRefDefBlock:
 		return true
 	}
-	return false
+	return nil != n.BlockExt
 }
 
 // AcceptLines 判断是否节点是否可以接受更多的文本行。比如 HTML 块、代码块和段落是可以接受更多的文本行的。
@@ -356,6 +407,9 @@ func (n *Node) AcceptLines() bool {
 	case NodeParagraph, NodeCodeBlock, NodeHTMLBlock, NodeTable, NodeMathBlock, NodeYamlFrontMatter, NodeBlockEmbed, NodeBlockQueryEmbed:
 		return true
 	}
+	if nil != n.BlockExt {
+		return n.BlockExt.AcceptLines()
+	}
 	return false
 }
 
@@ -363,16 +417,31 @@ func (n *Node) AcceptLines() bool {
 // 块引用节点（块级容器）可以包含任意节点；段落节点（叶子块节点）不能包含任何其他块级节点。
 func (n *Node) CanContain(nodeType NodeType) bool {
 	switch n.Type {
-	case NodeCodeBlock, NodeHTMLBlock, NodeParagraph, NodeThematicBreak, NodeTable, NodeMathBlock, NodeYamlFrontMatter:
+	case NodeCodeBlock, NodeHTMLBlock, NodeParagraph, NodeThematicBreak, NodeTable, NodeMathBlock, NodeYamlFrontMatter, NodeDefinitionTerm:
 		return false
 	case NodeList:
 		return NodeListItem == nodeType
+	case NodeDefinitionList:
+		return NodeDefinitionTerm == nodeType || NodeDefinitionDescription == nodeType
 	case NodeFootnotesDef:
 		return NodeFootnotesDef != nodeType // 脚注不能包含脚注
 	}
+	if nil != n.BlockExt {
+		return n.BlockExt.CanContain(nodeType)
+	}
 	return NodeListItem != nodeType
 }
 
+// BlockExt 描述了用户自定义块级节点的结构特性，节点设置了 BlockExt 后 IsBlock、AcceptLines 和 CanContain 会按此判断。
+//
+// 自定义块级语法注册在各个引擎上，解析时由生成节点的语法设置到节点上，所以不同引擎的注册互不影响。
+type BlockExt interface {
+	// AcceptLines 判断是否可以接受更多的文本行，叶子块返回 true，容器块返回 false。
+	AcceptLines() bool
+	// CanContain 判断是否能够包含 nodeType 指定类型的节点。
+	CanContain(nodeType NodeType) bool
+}
+
 //go:generate stringer -type=NodeType
 type NodeType int
 
@@ -433,6 +502,10 @@ const (
 	NodeLinkTitle                 NodeType = 42 // 链接标题
 	NodeLinkSpace                 NodeType = 43 // 链接地址和链接标题之间的空格
 	NodeHTMLEntity                NodeType = 44 // HTML 实体
+	NodeOpenBrace                 NodeType = 45 // {
+	NodeCloseBrace                NodeType = 46 // }
+	NodeLinkRefDefBlock           NodeType = 47 // 链接引用定义块
+	NodeLinkRefDef                NodeType = 48 // 链接引用定义 [label]: /dest "title"
 
 	// GFM
 
@@ -531,5 +604,15 @@ const (
 	NodeBlockQueryEmbed       NodeType = 465 // 内容块查询嵌入节点
 	NodeBlockQueryEmbedScript NodeType = 466 // 内容块查询嵌入脚本
 
+	// 提示块（Admonition） :::tip 标题 或者 > [!NOTE]
+
+	NodeAdmonition NodeType = 470 // 提示块
+
+	// 定义列表（Definition List） https://michelf.ca/projects/php-markdown/extra/#def-list
+
+	NodeDefinitionList        NodeType = 475 // 定义列表
+	NodeDefinitionTerm        NodeType = 476 // 定义术语
+	NodeDefinitionDescription NodeType = 477 // 定义描述
+
 	NodeTypeMaxVal NodeType = 1024 // 节点类型最大值
 )
@@ -53,6 +53,10 @@ func _() {
 	_ = x[NodeLinkTitle-42]
 	_ = x[NodeLinkSpace-43]
 	_ = x[NodeHTMLEntity-44]
+	_ = x[NodeOpenBrace-45]
+	_ = x[NodeCloseBrace-46]
+	_ = x[NodeLinkRefDefBlock-47]
+	_ = x[NodeLinkRefDef-48]
 	_ = x[NodeTaskListItemMarker-100]
 	_ = x[NodeStrikethrough-101]
 	_ = x[NodeStrikethrough1OpenMarker-102]
@@ -105,10 +109,14 @@ func _() {
 	_ = x[NodeTagCloseMarker-462]
 	_ = x[NodeBlockQueryEmbed-465]
 	_ = x[NodeBlockQueryEmbedScript-466]
+	_ = x[NodeAdmonition-470]
+	_ = x[NodeDefinitionList-475]
+	_ = x[NodeDefinitionTerm-476]
+	_ = x[NodeDefinitionDescription-477]
 	_ = x[NodeTypeMaxVal-1024]
 }
 
-const _NodeType_name = "NodeDocumentNodeParagraphNodeHeadingNodeHeadingC8hMarkerNodeThematicBreakNodeBlockquoteNodeBlockquoteMarkerNodeListNodeListItemNodeHTMLBlockNodeInlineHTMLNodeCodeBlockNodeCodeBlockFenceOpenMarkerNodeCodeBlockFenceCloseMarkerNodeCodeBlockFenceInfoMarkerNodeCodeBlockCodeNodeTextNodeEmphasisNodeEmA6kOpenMarkerNodeEmA6kCloseMarkerNodeEmU8eOpenMarkerNodeEmU8eCloseMarkerNodeStrongNodeStrongA6kOpenMarkerNodeStrongA6kCloseMarkerNodeStrongU8eOpenMarkerNodeStrongU8eCloseMarkerNodeCodeSpanNodeCodeSpanOpenMarkerNodeCodeSpanContentNodeCodeSpanCloseMarkerNodeHardBreakNodeSoftBreakNodeLinkNodeImageNodeBangNodeOpenBracketNodeCloseBracketNodeOpenParenNodeCloseParenNodeLinkTextNodeLinkDestNodeLinkTitleNodeLinkSpaceNodeHTMLEntityNodeTaskListItemMarkerNodeStrikethroughNodeStrikethrough1OpenMarkerNodeStrikethrough1CloseMarkerNodeStrikethrough2OpenMarkerNodeStrikethrough2CloseMarkerNodeTableNodeTableHeadNodeTableRowNodeTableCellNodeEmojiNodeEmojiUnicodeNodeEmojiImgNodeEmojiAliasNodeMathBlockNodeMathBlockOpenMarkerNodeMathBlockContentNodeMathBlockCloseMarkerNodeInlineMathNodeInlineMathOpenMarkerNodeInlineMathContentNodeInlineMathCloseMarkerNodeBackslashNodeBackslashContentNodeVditorCaretNodeFootnotesDefNodeFootnotesRefNodeToCNodeHeadingIDNodeYamlFrontMatterNodeYamlFrontMatterOpenMarkerNodeYamlFrontMatterContentNodeYamlFrontMatterCloseMarkerNodeBlockRefNodeBlockRefIDNodeBlockRefSpaceNodeBlockRefTextNodeBlockEmbedNodeBlockEmbedIDNodeBlockEmbedSpaceNodeBlockEmbedTextNodeMarkNodeMark1OpenMarkerNodeMark1CloseMarkerNodeMark2OpenMarkerNodeMark2CloseMarkerNodeKramdownBlockIALNodeTagNodeTagOpenMarkerNodeTagCloseMarkerNodeBlockQueryEmbedNodeBlockQueryEmbedScriptNodeTypeMaxVal"
+const _NodeType_name = "NodeDocumentNodeParagraphNodeHeadingNodeHeadingC8hMarkerNodeThematicBreakNodeBlockquoteNodeBlockquoteMarkerNodeListNodeListItemNodeHTMLBlockNodeInlineHTMLNodeCodeBlockNodeCodeBlockFenceOpenMarkerNodeCodeBlockFenceCloseMarkerNodeCodeBlockFenceInfoMarkerNodeCodeBlockCodeNodeTextNodeEmphasisNodeEmA6kOpenMarkerNodeEmA6kCloseMarkerNodeEmU8eOpenMarkerNodeEmU8eCloseMarkerNodeStrongNodeStrongA6kOpenMarkerNodeStrongA6kCloseMarkerNodeStrongU8eOpenMarkerNodeStrongU8eCloseMarkerNodeCodeSpanNodeCodeSpanOpenMarkerNodeCodeSpanContentNodeCodeSpanCloseMarkerNodeHardBreakNodeSoftBreakNodeLinkNodeImageNodeBangNodeOpenBracketNodeCloseBracketNodeOpenParenNodeCloseParenNodeLinkTextNodeLinkDestNodeLinkTitleNodeLinkSpaceNodeHTMLEntityNodeOpenBraceNodeCloseBraceNodeLinkRefDefBlockNodeLinkRefDefNodeTaskListItemMarkerNodeStrikethroughNodeStrikethrough1OpenMarkerNodeStrikethrough1CloseMarkerNodeStrikethrough2OpenMarkerNodeStrikethrough2CloseMarkerNodeTableNodeTableHeadNodeTableRowNodeTableCellNodeEmojiNodeEmojiUnicodeNodeEmojiImgNodeEmojiAliasNodeMathBlockNodeMathBlockOpenMarkerNodeMathBlockContentNodeMathBlockCloseMarkerNodeInlineMathNodeInlineMathOpenMarkerNodeInlineMathContentNodeInlineMathCloseMarkerNodeBackslashNodeBackslashContentNodeVditorCaretNodeFootnotesDefNodeFootnotesRefNodeToCNodeHeadingIDNodeYamlFrontMatterNodeYamlFrontMatterOpenMarkerNodeYamlFrontMatterContentNodeYamlFrontMatterCloseMarkerNodeBlockRefNodeBlockRefIDNodeBlockRefSpaceNodeBlockRefTextNodeBlockEmbedNodeBlockEmbedIDNodeBlockEmbedSpaceNodeBlockEmbedTextNodeMarkNodeMark1OpenMarkerNodeMark1CloseMarkerNodeMark2OpenMarkerNodeMark2CloseMarkerNodeKramdownBlockIALNodeTagNodeTagOpenMarkerNodeTagCloseMarkerNodeBlockQueryEmbedNodeBlockQueryEmbedScriptNodeAdmonitionNodeDefinitionListNodeDefinitionTermNodeDefinitionDescriptionNodeTypeMaxVal"
 
 var _NodeType_map = map[NodeType]string{
 	0:    _NodeType_name[0:12],
@@ -156,59 +164,67 @@ var _NodeType_map = map[NodeType]string{
 	42:   _NodeType_name[680:693],
 	43:   _NodeType_name[693:706],
 	44:   _NodeType_name[706:720],
-	100:  _NodeType_name[720:742],
-	101:  _NodeType_name[742:759],
-	102:  _NodeType_name[759:787],
-	103:  _NodeType_name[787:816],
-	104:  _NodeType_name[816:844],
-	105:  _NodeType_name[844:873],
-	106:  _NodeType_name[873:882],
-	107:  _NodeType_name[882:895],
-	108:  _NodeType_name[895:907],
-	109:  _NodeType_name[907:920],
-	200:  _NodeType_name[920:929],
-	201:  _NodeType_name[929:945],
-	202:  _NodeType_name[945:957],
-	203:  _NodeType_name[957:971],
-	300:  _NodeType_name[971:984],
-	301:  _NodeType_name[984:1007],
-	302:  _NodeType_name[1007:1027],
-	303:  _NodeType_name[1027:1051],
-	304:  _NodeType_name[1051:1065],
-	305:  _NodeType_name[1065:1089],
-	306:  _NodeType_name[1089:1110],
-	307:  _NodeType_name[1110:1135],
-	400:  _NodeType_name[1135:1148],
-	401:  _NodeType_name[1148:1168],
-	405:  _NodeType_name[1168:1183],
-	410:  _NodeType_name[1183:1199],
-	411:  _NodeType_name[1199:1215],
-	415:  _NodeType_name[1215:1222],
-	420:  _NodeType_name[1222:1235],
-	425:  _NodeType_name[1235:1254],
-	426:  _NodeType_name[1254:1283],
-	427:  _NodeType_name[1283:1309],
-	428:  _NodeType_name[1309:1339],
-	430:  _NodeType_name[1339:1351],
-	431:  _NodeType_name[1351:1365],
-	432:  _NodeType_name[1365:1382],
-	433:  _NodeType_name[1382:1398],
-	440:  _NodeType_name[1398:1412],
-	441:  _NodeType_name[1412:1428],
-	442:  _NodeType_name[1428:1447],
-	443:  _NodeType_name[1447:1465],
-	450:  _NodeType_name[1465:1473],
-	451:  _NodeType_name[1473:1492],
-	452:  _NodeType_name[1492:1512],
-	453:  _NodeType_name[1512:1531],
-	454:  _NodeType_name[1531:1551],
-	455:  _NodeType_name[1551:1571],
-	460:  _NodeType_name[1571:1578],
-	461:  _NodeType_name[1578:1595],
-	462:  _NodeType_name[1595:1613],
-	465:  _NodeType_name[1613:1632],
-	466:  _NodeType_name[1632:1657],
-	1024: _NodeType_name[1657:1671],
+	45:   _NodeType_name[720:733],
+	46:   _NodeType_name[733:747],
+	47:   _NodeType_name[747:766],
+	48:   _NodeType_name[766:780],
+	100:  _NodeType_name[780:802],
+	101:  _NodeType_name[802:819],
+	102:  _NodeType_name[819:847],
+	103:  _NodeType_name[847:876],
+	104:  _NodeType_name[876:904],
+	105:  _NodeType_name[904:933],
+	106:  _NodeType_name[933:942],
+	107:  _NodeType_name[942:955],
+	108:  _NodeType_name[955:967],
+	109:  _NodeType_name[967:980],
+	200:  _NodeType_name[980:989],
+	201:  _NodeType_name[989:1005],
+	202:  _NodeType_name[1005:1017],
+	203:  _NodeType_name[1017:1031],
+	300:  _NodeType_name[1031:1044],
+	301:  _NodeType_name[1044:1067],
+	302:  _NodeType_name[1067:1087],
+	303:  _NodeType_name[1087:1111],
+	304:  _NodeType_name[1111:1125],
+	305:  _NodeType_name[1125:1149],
+	306:  _NodeType_name[1149:1170],
+	307:  _NodeType_name[1170:1195],
+	400:  _NodeType_name[1195:1208],
+	401:  _NodeType_name[1208:1228],
+	405:  _NodeType_name[1228:1243],
+	410:  _NodeType_name[1243:1259],
+	411:  _NodeType_name[1259:1275],
+	415:  _NodeType_name[1275:1282],
+	420:  _NodeType_name[1282:1295],
+	425:  _NodeType_name[1295:1314],
+	426:  _NodeType_name[1314:1343],
+	427:  _NodeType_name[1343:1369],
+	428:  _NodeType_name[1369:1399],
+	430:  _NodeType_name[1399:1411],
+	431:  _NodeType_name[1411:1425],
+	432:  _NodeType_name[1425:1442],
+	433:  _NodeType_name[1442:1458],
+	440:  _NodeType_name[1458:1472],
+	441:  _NodeType_name[1472:1488],
+	442:  _NodeType_name[1488:1507],
+	443:  _NodeType_name[1507:1525],
+	450:  _NodeType_name[1525:1533],
+	451:  _NodeType_name[1533:1552],
+	452:  _NodeType_name[1552:1572],
+	453:  _NodeType_name[1572:1591],
+	454:  _NodeType_name[1591:1611],
+	455:  _NodeType_name[1611:1631],
+	460:  _NodeType_name[1631:1638],
+	461:  _NodeType_name[1638:1655],
+	462:  _NodeType_name[1655:1673],
+	465:  _NodeType_name[1673:1692],
+	466:  _NodeType_name[1692:1717],
+	470:  _NodeType_name[1717:1731],
+	475:  _NodeType_name[1731:1749],
+	476:  _NodeType_name[1749:1767],
+	477:  _NodeType_name[1767:1792],
+	1024: _NodeType_name[1792:1806],
 }
 
 func (i NodeType) String() string {
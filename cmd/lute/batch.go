@@ -0,0 +1,159 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// input 描述了一个待处理的输入文件。
+type input struct {
+	path string // 文件路径，"-" 表示标准输入
+	rel  string // 相对于输入参数的路径，用于在输出目录中生成同样的目录结构
+}
+
+// collectInputs 展开参数 args 中的 glob 模式，recursive 为 true 时递归查找目录中扩展名为 exts 的文件。没有参数时读取标准输入。
+func collectInputs(args []string, exts []string, recursive bool) (ret []*input, err error) {
+	if 1 > len(args) {
+		return []*input{{path: "-", rel: "-"}}, nil
+	}
+
+	for _, arg := range args {
+		if "-" == arg {
+			ret = append(ret, &input{path: "-", rel: "-"})
+			continue
+		}
+
+		paths := []string{arg}
+		if strings.ContainsAny(arg, "*?[") {
+			if paths, err = filepath.Glob(arg); nil != err {
+				return
+			}
+			if 1 > len(paths) {
+				return nil, fmt.Errorf("%s: no matching files", arg)
+			}
+		}
+
+		for _, path := range paths {
+			info, statErr := os.Stat(path)
+			if nil != statErr {
+				return nil, statErr
+			}
+			if !info.IsDir() {
+				ret = append(ret, &input{path: path, rel: filepath.Base(path)})
+				continue
+			}
+			if !recursive {
+				return nil, fmt.Errorf("%s: is a directory (use -r to process it recursively)", path)
+			}
+
+			root := path
+			err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+				if nil != err {
+					return err
+				}
+				if info.IsDir() || !hasExt(path, exts) {
+					return nil
+				}
+				rel, err := filepath.Rel(root, path)
+				if nil != err {
+					return err
+				}
+				ret = append(ret, &input{path: path, rel: rel})
+				return nil
+			})
+			if nil != err {
+				return
+			}
+		}
+	}
+	return
+}
+
+func hasExt(path string, exts []string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range exts {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// result 描述了一个输入文件的处理结果。
+type result struct {
+	output []byte
+	failed bool // 处理成功但是需要以非零状态码退出，比如检查出错误
+	err    error
+}
+
+// processAll 使用 parallel 个协程并发处理 inputs，每个协程使用 newWorker 创建自己的处理函数，结果按输入顺序返回。
+func processAll(inputs []*input, parallel int, newWorker func() func(in *input) *result) (ret []*result) {
+	ret = make([]*result, len(inputs))
+	if parallel > len(inputs) {
+		parallel = len(inputs)
+	}
+	if 1 > parallel {
+		parallel = 1
+	}
+
+	jobs := make(chan int)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			process := newWorker()
+			for j := range jobs {
+				ret[j] = process(inputs[j])
+			}
+		}()
+	}
+	for i := range inputs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return
+}
+
+// readInput 读取输入文件 in 的内容。
+func readInput(in *input) ([]byte, error) {
+	if "-" == in.path {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(in.path)
+}
+
+// outputPath 返回输入文件 in 在输出目录 dir 中对应的路径，ext 不为空时替换扩展名。
+func outputPath(dir string, in *input, ext string) string {
+	rel := in.rel
+	if "-" == in.path {
+		rel = "stdin.md"
+	}
+	if "" != ext {
+		rel = strings.TrimSuffix(rel, filepath.Ext(rel)) + ext
+	}
+	return filepath.Join(dir, rel)
+}
+
+// writeOutput 将 output 写入文件 path，必要时创建上级目录。
+func writeOutput(path string, output []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); nil != err {
+		return err
+	}
+	return ioutil.WriteFile(path, output, 0644)
+}
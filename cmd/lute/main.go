@@ -0,0 +1,295 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+// lute 是 Lute 引擎的命令行工具，用法：
+//
+//	lute <command> [flags] [path ...]
+//
+// 支持的子命令有 md2html、format、html2md、textbundle、echarts 和 lint。path 可以是文件、glob 模式或者目录（需要指定 -r），
+// 没有指定 path 或者 path 为 - 时读取标准输入。解析渲染选项通过和 parse.Options 字段同名的参数配置，比如 -SoftBreak2HardBreak=false。
+//
+// format 和 gofmt 一样支持 -l 列出未格式化的文件、-d 输出统一差异格式的修改，此时存在未格式化的文件（没有同时指定 -w 覆盖）会以状态码 1 退出，可用于 CI 检查：
+//
+//	lute format -l -d -r docs
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+)
+
+// command 描述了一个子命令。
+type command struct {
+	name      string
+	summary   string
+	inputExts []string // 递归处理目录时查找的文件扩展名
+	outputExt string   // 输出到目录时使用的扩展名，为空的话所有结果合并输出到一个文件或者标准输出
+	run       func(engine *lute.Lute, in *input, data []byte) *result
+}
+
+var markdownExts = []string{".md", ".markdown"}
+
+var commands = []*command{
+	{"md2html", "render Markdown to HTML", markdownExts, ".html", md2html},
+	{"format", "format Markdown", markdownExts, ".md", format},
+	{"html2md", "convert HTML to Markdown", []string{".html", ".htm"}, ".md", html2md},
+	{"textbundle", "rewrite links for TextBundle packaging", markdownExts, ".md", textBundle},
+	{"echarts", "render Markdown to ECharts mind map JSON", markdownExts, ".json", echarts},
+	{"lint", "report Markdown parse diagnostics", markdownExts, "", lint},
+}
+
+// 子命令参数。
+var (
+	output       string      // 输出文件或者目录
+	write        bool        // format 直接覆盖原文件
+	listFiles    bool        // format 列出未格式化的文件
+	showDiff     bool        // format 输出原文到格式化结果的差异
+	linkPrefixes stringsFlag // textbundle 需要处理的链接前缀
+	listLinks    bool        // textbundle 输出原始链接列表
+	document     bool        // md2html 输出完整 HTML 文档
+	templateFile string      // md2html 完整 HTML 文档模板文件
+	inlineImages bool        // md2html 将本地图片内联为 data URI
+	absImages    bool        // md2html 内联绝对路径引用的本地图片
+
+	documentTemplate *template.Template
+)
+
+func main() {
+	if 2 > len(os.Args) {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	if "help" == name || "-h" == name || "-help" == name || "--help" == name {
+		usage()
+		return
+	}
+	var cmd *command
+	for _, c := range commands {
+		if name == c.name {
+			cmd = c
+		}
+	}
+	if nil == cmd {
+		fmt.Fprintf(os.Stderr, "lute: unknown command %q\n", name)
+		usage()
+		os.Exit(2)
+	}
+
+	proto := lute.New()
+	flags := flag.NewFlagSet("lute "+cmd.name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: lute %s [flags] [path ...]\n\n%s.\n\nflags:\n", cmd.name, cmd.summary)
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&output, "o", "", "output file, or output directory when processing multiple files")
+	recursive := flags.Bool("r", false, "process directories recursively")
+	parallel := flags.Int("j", runtime.NumCPU(), "number of files processed in parallel")
+	switch cmd.name {
+	case "format":
+		flags.BoolVar(&write, "w", false, "write result to (source) file instead of stdout")
+		flags.BoolVar(&listFiles, "l", false, "list files whose formatting differs from lute's")
+		flags.BoolVar(&showDiff, "d", false, "display diffs instead of formatted output")
+		bindOptions(flags, proto.FormatOptions, "render.FormatOptions.")
+	case "md2html":
+		flags.BoolVar(&document, "document", false, "output a complete standalone HTML document")
+		flags.StringVar(&templateFile, "template", "", "html/template file wrapping the document (implies -document)")
+		flags.BoolVar(&inlineImages, "inline-images", false, "inline local images as data URIs (implies -document)")
+		flags.BoolVar(&absImages, "inline-absolute-images", false, "also inline images referenced by absolute paths, not only those under the input file's directory")
+	case "textbundle":
+		flags.Var(&linkPrefixes, "link-prefix", "link prefix to rewrite (repeatable)")
+		flags.BoolVar(&listLinks, "links", false, "list the original links instead of the rewritten Markdown")
+	}
+	bindOptions(flags, proto.Options, "parse.Options.")
+	flags.Parse(os.Args[2:])
+	if "" != templateFile {
+		var err error
+		if documentTemplate, err = template.ParseFiles(templateFile); nil != err {
+			fmt.Fprintln(os.Stderr, "lute:", err)
+			os.Exit(2)
+		}
+	}
+
+	inputs, err := collectInputs(flags.Args(), cmd.inputExts, *recursive)
+	if nil != err {
+		fmt.Fprintln(os.Stderr, "lute:", err)
+		os.Exit(2)
+	}
+
+	// 多个输入文件或者输出到已有目录时按输入的相对路径写入输出目录
+	outputDir := false
+	if "" != output && "" != cmd.outputExt && !formatChecking() {
+		info, statErr := os.Stat(output)
+		outputDir = 1 < len(inputs) || *recursive || (nil == statErr && info.IsDir()) || strings.HasSuffix(output, string(os.PathSeparator))
+	}
+
+	results := processAll(inputs, *parallel, func() func(in *input) *result {
+		engine := lute.New()
+		options, formatOptions := *proto.Options, *proto.FormatOptions
+		engine.Options, engine.FormatOptions = &options, &formatOptions
+		return func(in *input) *result {
+			data, err := readInput(in)
+			if nil != err {
+				return &result{err: err}
+			}
+			ret := cmd.run(engine, in, data)
+			if nil != ret.err {
+				return ret
+			}
+
+			dest := ""
+			if write && "-" != in.path && !formatChecking() {
+				dest = in.path
+			} else if outputDir {
+				dest = outputPath(output, in, cmd.outputExt)
+			}
+			if "" != dest {
+				ret.err = writeOutput(dest, ret.output)
+				ret.output = nil
+			}
+			return ret
+		}
+	})
+
+	var out []byte
+	status := 0
+	for i, result := range results {
+		if nil != result.err {
+			fmt.Fprintf(os.Stderr, "lute: %s: %s\n", inputs[i].path, result.err)
+			status = 2
+			continue
+		}
+		if result.failed && 0 == status {
+			status = 1
+		}
+		out = append(out, result.output...)
+	}
+
+	if "" != output && !outputDir {
+		if err = writeOutput(output, out); nil != err {
+			fmt.Fprintln(os.Stderr, "lute:", err)
+			status = 2
+		}
+	} else {
+		os.Stdout.Write(out)
+	}
+	os.Exit(status)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: lute <command> [flags] [path ...]\n\ncommands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s%s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun 'lute <command> -h' for the flags of a command.")
+}
+
+func md2html(engine *lute.Lute, in *input, data []byte) *result {
+	if !document && nil == documentTemplate && !inlineImages {
+		return &result{output: engine.Markdown(in.path, data)}
+	}
+
+	options := &render.DocumentOptions{Template: documentTemplate, InlineImages: inlineImages, AllowAbsoluteImagePaths: absImages}
+	if "-" != in.path {
+		options.ImageDir = filepath.Dir(in.path)
+	}
+	html, err := engine.MarkdownDocument(in.path, data, options)
+	return &result{output: html, err: err}
+}
+
+func format(engine *lute.Lute, in *input, data []byte) (ret *result) {
+	if !formatChecking() {
+		return &result{output: engine.Format(in.path, data)}
+	}
+
+	// 检查模式下输出未格式化的文件路径或者差异，指定了 -w 的话同时覆盖原文件
+	ret = &result{}
+	path := in.path
+	if "-" == path {
+		path = "<stdin>"
+	}
+	check := engine.FormatCheck(filepath.ToSlash(path), data)
+	if check.Formatted {
+		return
+	}
+	if listFiles {
+		ret.output = append(ret.output, path+"\n"...)
+	}
+	if showDiff {
+		ret.output = append(ret.output, check.Diff...)
+	}
+	if write && "-" != in.path {
+		ret.err = writeOutput(in.path, check.Output)
+	} else {
+		ret.failed = true
+	}
+	return
+}
+
+// formatChecking 判断 format 是否处于检查模式（指定了 -l 或者 -d）。
+func formatChecking() bool {
+	return listFiles || showDiff
+}
+
+func html2md(engine *lute.Lute, in *input, data []byte) *result {
+	markdown, err := engine.HTML2Markdown(string(data))
+	return &result{output: []byte(markdown), err: err}
+}
+
+func textBundle(engine *lute.Lute, in *input, data []byte) *result {
+	textbundle, originalLinks := engine.TextBundle(in.path, data, linkPrefixes)
+	if listLinks {
+		var links []byte
+		for _, link := range originalLinks {
+			links = append(links, link+"\n"...)
+		}
+		return &result{output: links}
+	}
+	return &result{output: textbundle}
+}
+
+func echarts(engine *lute.Lute, in *input, data []byte) *result {
+	return &result{output: []byte(engine.RenderEChartsJSON(string(data)) + "\n")}
+}
+
+func lint(engine *lute.Lute, in *input, data []byte) (ret *result) {
+	ret = &result{}
+	path := in.path
+	if "-" == path {
+		path = "<stdin>"
+	}
+	for _, diagnostic := range engine.Lint(in.path, data) {
+		ret.output = append(ret.output, path+":"+diagnostic.String()+"\n"...)
+		ret.failed = ret.failed || parse.SeverityError == diagnostic.Severity
+	}
+	return
+}
+
+// stringsFlag 是可重复的字符串参数。
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
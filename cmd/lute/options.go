@@ -0,0 +1,120 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"reflect"
+	"strings"
+)
+
+// bindOptions 为结构体指针 options 的每个可配置字段注册同名命令行参数，参数默认值为字段的当前值。
+//
+// 布尔、字符串和整数字段直接设置；byte 字段接受单个字符；[]string 字段接受逗号分隔的列表，替换原有的元素；map[string]string 字段接受可重复的 key=value 参数，合并到原有映射中。
+// 其他类型的字段（比如自定义语法和查询解析器）无法通过命令行配置，直接跳过。
+func bindOptions(flags *flag.FlagSet, options interface{}, usagePrefix string) {
+	v := reflect.ValueOf(options).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		if "" != field.PkgPath {
+			continue
+		}
+
+		name, usage := field.Name, usagePrefix+field.Name
+		switch field.Type.Kind() {
+		case reflect.Bool:
+			flags.BoolVar(value.Addr().Interface().(*bool), name, value.Bool(), usage)
+		case reflect.String:
+			flags.StringVar(value.Addr().Interface().(*string), name, value.String(), usage)
+		case reflect.Int:
+			flags.IntVar(value.Addr().Interface().(*int), name, int(value.Int()), usage)
+		case reflect.Uint8:
+			flags.Var((*byteValue)(value.Addr().Interface().(*byte)), name, usage+" (a single character)")
+		case reflect.Slice:
+			if reflect.String == field.Type.Elem().Kind() {
+				flags.Var(&sliceValue{value}, name, usage+" (comma-separated)")
+			}
+		case reflect.Map:
+			if reflect.String == field.Type.Key().Kind() && reflect.String == field.Type.Elem().Kind() {
+				flags.Var(&mapValue{value}, name, usage+" (repeatable key=value)")
+			}
+		}
+	}
+}
+
+// byteValue 是单个字符的命令行参数值，为空表示零值。
+type byteValue byte
+
+func (b *byteValue) String() string {
+	if nil == b || 0 == *b {
+		return ""
+	}
+	return string([]byte{byte(*b)})
+}
+
+func (b *byteValue) Set(s string) error {
+	switch len(s) {
+	case 0:
+		*b = 0
+	case 1:
+		*b = byteValue(s[0])
+	default:
+		return errors.New("expected a single character")
+	}
+	return nil
+}
+
+// sliceValue 是 []string 字段的命令行参数值，使用逗号分隔多个元素，设置时替换原有的元素，为空表示清空。
+type sliceValue struct {
+	s reflect.Value
+}
+
+func (s *sliceValue) String() string {
+	if nil == s || !s.s.IsValid() {
+		return ""
+	}
+	return strings.Join(s.s.Interface().([]string), ",")
+}
+
+func (s *sliceValue) Set(value string) error {
+	var elems []string
+	for _, elem := range strings.Split(value, ",") {
+		if elem = strings.TrimSpace(elem); "" != elem {
+			elems = append(elems, elem)
+		}
+	}
+	s.s.Set(reflect.ValueOf(elems))
+	return nil
+}
+
+// mapValue 是 map[string]string 字段的命令行参数值，每次设置合并一个 key=value。
+type mapValue struct {
+	m reflect.Value
+}
+
+// String 不输出映射内容，内置的映射（比如 Emoji 别名）通常很大。
+func (m *mapValue) String() string {
+	return ""
+}
+
+func (m *mapValue) Set(s string) error {
+	parts := strings.SplitN(s, "=", 2)
+	if 2 != len(parts) {
+		return errors.New("expected key=value")
+	}
+	if m.m.IsNil() {
+		m.m.Set(reflect.MakeMap(m.m.Type()))
+	}
+	m.m.SetMapIndex(reflect.ValueOf(parts[0]), reflect.ValueOf(parts[1]))
+	return nil
+}
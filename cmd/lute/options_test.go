@@ -0,0 +1,52 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"reflect"
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+func TestBindOptions(t *testing.T) {
+	engine := lute.New()
+	flags := flag.NewFlagSet("lute", flag.ContinueOnError)
+	bindOptions(flags, engine.FormatOptions, "render.FormatOptions.")
+	bindOptions(flags, engine.Options, "parse.Options.")
+
+	args := []string{"-HardWrapColumn", "80", "-FenceChar", "~", "-Sanitize", "-LinkSchemes", "http, https,mailto", "-InternalLinkHosts", "b3log.org", "-Terms", "golang=Go"}
+	if err := flags.Parse(args); nil != err {
+		t.Fatalf("parse flags failed: %s", err)
+	}
+	if 80 != engine.FormatOptions.HardWrapColumn || '~' != engine.FormatOptions.FenceChar || !engine.Sanitize {
+		t.Fatalf("unexpected scalar options %d %q %v", engine.FormatOptions.HardWrapColumn, engine.FormatOptions.FenceChar, engine.Sanitize)
+	}
+	if expected := []string{"http", "https", "mailto"}; !reflect.DeepEqual(expected, engine.LinkSchemes) {
+		t.Fatalf("expected link schemes %v, got %v", expected, engine.LinkSchemes)
+	}
+	if expected := []string{"b3log.org"}; !reflect.DeepEqual(expected, engine.InternalLinkHosts) {
+		t.Fatalf("expected internal link hosts %v, got %v", expected, engine.InternalLinkHosts)
+	}
+	if "Go" != engine.Terms["golang"] {
+		t.Fatalf("expected term golang=Go, got %q", engine.Terms["golang"])
+	}
+
+	// 输出参数默认值时不能出错
+	buf := &bytes.Buffer{}
+	flags.SetOutput(buf)
+	flags.PrintDefaults()
+	if !bytes.Contains(buf.Bytes(), []byte("-LinkSchemes value")) {
+		t.Fatalf("usage of LinkSchemes is missing\n%s", buf.String())
+	}
+}
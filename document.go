@@ -0,0 +1,36 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+// +build !javascript
+
+package lute
+
+import (
+	"html/template"
+
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+)
+
+// MarkdownDocument 将 markdown 文本字节数组渲染为完整的 HTML 文档，适合导出离线分享的单文件。
+//
+// 文档的 title、description 和 lang 取自 YAML Front Matter，开启代码块语法高亮（非内联样式）时会嵌入 CodeSyntaxHighlightStyleName 对应的样式，
+// options 可以指定自定义模板以及是否将本地图片内联为 data URI，传入 nil 的话使用默认配置。
+func (lute *Lute) MarkdownDocument(name string, markdown []byte, options *render.DocumentOptions) (html []byte, err error) {
+	if nil == options {
+		options = render.NewDocumentOptions()
+	}
+
+	tree := parse.Parse(name, markdown, lute.Options)
+	doc := render.NewDocument(tree, options)
+	body := lute.renderHTML(lute.newHtmlRenderer(tree))
+	doc.Body = template.HTML(body)
+	return doc.Render(options.Template)
+}
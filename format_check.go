@@ -0,0 +1,86 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"github.com/88250/lute/util"
+)
+
+// FormatCheckResult 描述了格式化检查的结果。
+type FormatCheckResult struct {
+	Formatted    bool         // 原文是否已经是格式化后的结果
+	Diff         string       // 原文和格式化结果的统一差异格式文本，已经格式化的话为空
+	ChangedLines []*LineRange // 原文中需要修改的行范围
+	Output       []byte       // 格式化结果
+}
+
+// LineRange 描述了文本中的行范围，行号从 1 开始，包含 Start 和 End。
+type LineRange struct {
+	Start, End int
+}
+
+// FormatCheck 检查 markdown 是否已经格式化，不会修改原文。
+//
+// 没有格式化的话返回原文到格式化结果的统一差异格式文本，以及原文中需要修改的行范围，仅插入新行的话范围为插入位置所在的行。
+func (lute *Lute) FormatCheck(name string, markdown []byte) (ret *FormatCheckResult) {
+	formatted := lute.Format(name, markdown)
+	ret = &FormatCheckResult{Output: formatted}
+	original, output := string(markdown), string(formatted)
+	if original == output {
+		ret.Formatted = true
+		return
+	}
+
+	ret.Diff = util.UnifiedDiff("a/"+name, "b/"+name, original, output, 3)
+	ret.ChangedLines = changedLines(util.DiffLines(util.SplitLines(original), util.SplitLines(output)), len(util.SplitLines(original)))
+	return
+}
+
+// changedLines 根据编辑脚本 diff 计算原文中连续修改的行范围，lines 为原文行数。
+func changedLines(diff []util.DiffLine, lines int) (ret []*LineRange) {
+	line := 1
+	var cur *LineRange
+	for _, d := range diff {
+		switch d.Op {
+		case util.DiffEqual:
+			cur = nil
+			line++
+		case util.DiffDelete:
+			if nil == cur {
+				cur = &LineRange{Start: line}
+				ret = append(ret, cur)
+			} else if cur.End < cur.Start {
+				cur.Start = line // 先插入后删除时以删除的行为准
+			}
+			cur.End = line
+			line++
+		case util.DiffInsert:
+			if nil == cur {
+				cur = &LineRange{Start: line, End: line - 1}
+				ret = append(ret, cur)
+			}
+		}
+	}
+
+	// 仅插入新行的范围使用插入位置所在的行
+	for _, r := range ret {
+		if r.End < r.Start {
+			if lines < r.Start {
+				r.Start = lines
+			}
+			if 1 > r.Start {
+				r.Start = 1
+			}
+			r.End = r.Start
+		}
+	}
+	return
+}
@@ -12,6 +12,8 @@ package lute
 
 import (
 	"bytes"
+	"errors"
+	"strconv"
 	"strings"
 
 	"github.com/88250/lute/ast"
@@ -22,28 +24,90 @@ import (
 	"github.com/88250/lute/util"
 )
 
-// HTML2Markdown 将 HTML 转换为 Markdown。
+// HTML2Markdown 将 HTML 转换为 Markdown，HTML 解析失败的话返回 *HTMLParseError。
 func (lute *Lute) HTML2Markdown(htmlStr string) (markdown string, err error) {
 	// 将字符串解析为 DOM 树
-	tree := lute.HTML2Tree(htmlStr)
+	tree, err := lute.html2Tree(htmlStr)
+	if nil != err {
+		return
+	}
 
 	// 将 AST 进行 Markdown 格式化渲染
 	var formatted []byte
-	renderer := render.NewFormatRenderer(tree)
-	for nodeType, rendererFunc := range lute.HTML2MdRendererFuncs {
-		renderer.ExtRendererFuncs[nodeType] = rendererFunc
-	}
+	renderer := lute.newHTML2MdRenderer(tree)
 	formatted = renderer.Render()
 	markdown = util.BytesToStr(formatted)
 	return
 }
 
-// HTML2Tree 将 HTML 转换为 AST。
+// HTML2MarkdownE 和 HTML2Markdown 一样将 HTML 转换为 Markdown，但是会恢复转换过程中发生的 panic 并返回错误。
+//
+// HTML 解析或者转换为语法树失败返回 *HTMLParseError，渲染失败返回 *render.RenderError，其中包含了引发 panic 的节点类型，超出输出大小限制返回 *parse.LimitError。
+func (lute *Lute) HTML2MarkdownE(htmlStr string) (markdown string, err error) {
+	tree, err := lute.html2TreeE(htmlStr)
+	if nil != err {
+		return
+	}
+
+	renderer := lute.newHTML2MdRenderer(tree)
+	defer renderer.Recover(&err)
+	markdown = util.BytesToStr(renderer.Render())
+	if err = renderer.Err(); nil != err {
+		markdown = ""
+	}
+	return
+}
+
+// HTMLParseError 描述了 HTML 解析失败或者将 DOM 转换为语法树时发生 panic 导致的错误。
+type HTMLParseError struct {
+	Err   error  // 具体的错误
+	Stack string // 发生 panic 时的调用栈，解析失败的话为空
+}
+
+func (e *HTMLParseError) Error() string {
+	return "parse HTML failed: " + e.Err.Error()
+}
+
+// Unwrap 返回具体的错误。
+func (e *HTMLParseError) Unwrap() error {
+	return e.Err
+}
+
+// newHTML2MdRenderer 构造用于将语法树 tree 渲染为 Markdown 的渲染器，并注册用户自定义的渲染函数。
+func (lute *Lute) newHTML2MdRenderer(tree *parse.Tree) (ret *render.FormatRenderer) {
+	ret = render.NewFormatRenderer(tree)
+	for nodeType, rendererFunc := range lute.HTML2MdRendererFuncs {
+		ret.ExtRendererFuncs[nodeType] = rendererFunc
+	}
+	return
+}
+
+// HTML2Tree 将 HTML 转换为 AST，HTML 解析失败的话返回 nil。
 func (lute *Lute) HTML2Tree(dom string) (ret *parse.Tree) {
+	ret, _ = lute.html2Tree(dom)
+	return
+}
+
+// html2TreeE 和 html2Tree 一样将 HTML 转换为 AST，但是会恢复转换过程中发生的 panic 并返回 *HTMLParseError。
+func (lute *Lute) html2TreeE(dom string) (ret *parse.Tree, err error) {
+	defer func() {
+		if e := recover(); nil != e {
+			ret = nil
+			err = &HTMLParseError{Err: errors.New(util.PanicMessage(e)), Stack: util.PanicStack()}
+		}
+	}()
+
+	ret, err = lute.html2Tree(dom)
+	return
+}
+
+// html2Tree 将 HTML 转换为 AST，HTML 解析失败的话返回 *HTMLParseError。
+func (lute *Lute) html2Tree(dom string) (ret *parse.Tree, err error) {
 	reader := strings.NewReader(dom)
 	htmlRoot := &html.Node{Type: html.ElementNode}
 	htmlNodes, err := html.ParseFragment(reader, htmlRoot)
 	if nil != err {
+		err = &HTMLParseError{Err: err}
 		return
 	}
 
@@ -187,6 +251,33 @@ func (lute *Lute) genASTByDOM(n *html.Node, tree *parse.Tree) {
 		tree.Context.Tip.AppendChild(node)
 		tree.Context.Tip = node
 		defer tree.Context.ParentTip()
+	case atom.Dl:
+		node.Type = ast.NodeDefinitionList
+		node.ListData = &ast.ListData{Tight: true}
+		for dd := n.FirstChild; nil != dd; dd = dd.NextSibling {
+			if atom.Dd != dd.DataAtom {
+				continue
+			}
+			for c := dd.FirstChild; nil != c; c = c.NextSibling {
+				if atom.P == c.DataAtom { // 描述中包含段落的话说明是松散的定义列表
+					node.Tight = false
+				}
+			}
+		}
+		tree.Context.Tip.AppendChild(node)
+		tree.Context.Tip = node
+		defer tree.Context.ParentTip()
+	case atom.Dt:
+		node.Type = ast.NodeDefinitionTerm
+		tree.Context.Tip.AppendChild(node)
+		tree.Context.Tip = node
+		defer tree.Context.ParentTip()
+	case atom.Dd:
+		node.Type = ast.NodeDefinitionDescription
+		node.ListData = &ast.ListData{Marker: []byte(":")}
+		tree.Context.Tip.AppendChild(node)
+		tree.Context.Tip = node
+		defer tree.Context.ParentTip()
 	case atom.Pre:
 		firstc := n.FirstChild
 		if nil != firstc {
@@ -331,17 +422,28 @@ func (lute *Lute) genASTByDOM(n *html.Node, tree *parse.Tree) {
 	case atom.Table:
 		node.Type = ast.NodeTable
 		var tableAligns []int
-		for th := n.FirstChild.FirstChild.FirstChild; nil != th; th = th.NextSibling {
-			align := lute.domAttrValue(th, "align")
-			switch align {
+		var firstRow *html.Node
+		if firstRow = lute.domTableFirstRow(n); nil == firstRow {
+			return
+		}
+		for th := firstRow.FirstChild; nil != th; th = th.NextSibling {
+			if html.ElementNode != th.Type {
+				continue
+			}
+			var align int
+			switch lute.domAttrValue(th, "align") {
 			case "left":
-				tableAligns = append(tableAligns, 1)
+				align = 1
 			case "center":
-				tableAligns = append(tableAligns, 2)
+				align = 2
 			case "right":
-				tableAligns = append(tableAligns, 3)
-			default:
-				tableAligns = append(tableAligns, 0)
+				align = 3
+			}
+			tableAligns = append(tableAligns, align)
+			if lute.Options.ExtendedTable {
+				for span := lute.domTableCellSpan(th, "colspan"); 1 < span; span-- {
+					tableAligns = append(tableAligns, align)
+				}
 			}
 		}
 		node.TableAligns = tableAligns
@@ -354,10 +456,16 @@ func (lute *Lute) genASTByDOM(n *html.Node, tree *parse.Tree) {
 		tree.Context.Tip = node
 		defer tree.Context.ParentTip()
 	case atom.Tbody:
+	case atom.Caption:
+		if !lute.Options.ExtendedTable {
+			break
+		}
+		tree.Context.Tip.TableCaption = bytes.TrimSpace(util.StrToBytes(lute.domText(n)))
+		return
 	case atom.Tr:
 		table := n.Parent.Parent
 		node.Type = ast.NodeTableRow
-		if atom.Thead != table.FirstChild.DataAtom && n == n.Parent.FirstChild {
+		if atom.Thead != n.Parent.DataAtom && n == lute.domTableFirstRow(table) {
 			// 补全 thread 节点
 			thead := &ast.Node{Type: ast.NodeTableHead}
 			tree.Context.Tip.AppendChild(thead)
@@ -382,6 +490,14 @@ func (lute *Lute) genASTByDOM(n *html.Node, tree *parse.Tree) {
 			tableAlign = 0
 		}
 		node.TableCellAlign = tableAlign
+		if lute.Options.ExtendedTable {
+			if colspan := lute.domTableCellSpan(n, "colspan"); 1 < colspan {
+				node.TableCellColspan = colspan
+			}
+			if rowspan := lute.domTableCellSpan(n, "rowspan"); 1 < rowspan {
+				node.TableCellRowspan = rowspan
+			}
+		}
 		tree.Context.Tip.AppendChild(node)
 		tree.Context.Tip = node
 		defer tree.Context.ParentTip()
@@ -435,5 +551,81 @@ func (lute *Lute) genASTByDOM(n *html.Node, tree *parse.Tree) {
 		node.AppendChild(&ast.Node{Type: ast.NodeMark1CloseMarker, Tokens: util.StrToBytes(marker)})
 	case atom.Details:
 		tree.Context.Tip.AppendChild(&ast.Node{Type: ast.NodeHTMLBlock, Tokens: []byte("</details>")})
+	case atom.Table:
+		if lute.Options.ExtendedTable {
+			lute.fillTableRowspans(node)
+		}
+	}
+}
+
+// domTableFirstRow 返回表格 table 的第一行，跳过表格标题 caption 等非行节点。
+func (lute *Lute) domTableFirstRow(table *html.Node) *html.Node {
+	for c := table.FirstChild; nil != c; c = c.NextSibling {
+		switch c.DataAtom {
+		case atom.Tr:
+			return c
+		case atom.Thead, atom.Tbody, atom.Tfoot:
+			if ret := lute.domTableFirstRow(c); nil != ret {
+				return ret
+			}
+		}
+	}
+	return nil
+}
+
+// domTableCellSpan 返回单元格 cell 的跨列 colspan 或者跨行 rowspan 数，未设置或者非法的话返回 1。
+func (lute *Lute) domTableCellSpan(cell *html.Node, attrName string) int {
+	span, err := strconv.Atoi(strings.TrimSpace(lute.domAttrValue(cell, attrName)))
+	if nil != err || 1 > span {
+		return 1
+	}
+	return span
+}
+
+// fillTableRowspans 根据表头和表体中单元格的跨行数在后续行对应的列上补全 ^^ 占位单元格。
+func (lute *Lute) fillTableRowspans(table *ast.Node) {
+	var rows []*ast.Node
+	for c := table.FirstChild; nil != c; c = c.Next {
+		switch c.Type {
+		case ast.NodeTableHead:
+			for row := c.FirstChild; nil != row; row = row.Next {
+				rows = append(rows, row)
+			}
+		case ast.NodeTableRow:
+			rows = append(rows, c)
+		}
+	}
+
+	remains := map[int]int{}  // 列 -> 还需要补全占位单元格的行数
+	colspans := map[int]int{} // 列 -> 发起跨行的单元格的跨列数
+	for _, row := range rows {
+		col := 0
+		for cell := row.FirstChild; ; {
+			if 0 < remains[col] {
+				merged := &ast.Node{Type: ast.NodeTableCell, TableCellMerged: true, TableCellColspan: colspans[col]}
+				if nil != cell {
+					cell.InsertBefore(merged)
+				} else {
+					row.AppendChild(merged)
+				}
+				remains[col]--
+				col += colspans[col]
+				continue
+			}
+			if nil == cell {
+				break
+			}
+
+			span := cell.TableCellColspan
+			if 1 > span {
+				span = 1
+			}
+			if 1 < cell.TableCellRowspan {
+				remains[col] = cell.TableCellRowspan - 1
+				colspans[col] = span
+			}
+			col += span
+			cell = cell.Next
+		}
 	}
 }
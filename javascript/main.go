@@ -20,7 +20,9 @@ import (
 
 func New(options map[string]map[string]*js.Object) *js.Object {
 	engine := lute.New()
-	engine.SetJSRenderers(options)
+	if err := engine.SetJSRenderers(options); nil != err {
+		panic(err) // 在 JavaScript 端抛出异常
+	}
 	return js.MakeWrapper(engine)
 }
 
@@ -10,7 +10,10 @@
 
 package lex
 
-import "unicode/utf8"
+import (
+	"sort"
+	"unicode/utf8"
+)
 
 // Lexer 描述了词法分析器结构。
 type Lexer struct {
@@ -18,10 +21,19 @@ type Lexer struct {
 	length int    // 输入的文本字节数组的长度
 	offset int    // 当前读取字节位置
 	width  int    // 最新一个字符的长度（字节数）
+
+	originalOffset int    // 当前读取位置在原始输入中的字节偏移
+	lineStarts     []int  // 每行行首在原始输入中的字节偏移
+	lineEnds       []int  // 每行内容（不含换行符）结尾在原始输入中的字节偏移
+	lineBlanks     []bool // 每行是否是空行
+	expands        []int  // 最近一行中 \u0000 被替换为 \uFFFD 的位置（相对行首）
 }
 
 // NewLexer 创建一个词法分析器。
+//
+// 分词时会就地替换 \r 和 \u0000，所以这里先复制一份 input，避免修改调用方传入的数据。
 func NewLexer(input []byte) (ret *Lexer) {
+	input = append(make([]byte, 0, len(input)+1), input...)
 	ret = &Lexer{input: input, length: len(input)}
 	if 0 < ret.length && ItemNewline != ret.input[ret.length-1] {
 		// 以 \n 结尾预处理
@@ -37,6 +49,8 @@ func (l *Lexer) NextLine() (ret []byte) {
 		return
 	}
 
+	l.expands = l.expands[:0]
+	removedCR := 0
 	var b, nb byte
 	i := l.offset
 	for ; i < l.length; i += l.width {
@@ -50,6 +64,7 @@ func (l *Lexer) NextLine() (ret []byte) {
 				if ItemNewline == nb { // \r\n
 					l.input = append(l.input[:i], l.input[i+1:]...) // 移除 \r，依靠下一个的 \n 切行
 					l.length--                                      // 重新计算总长
+					removedCR++
 				} else { // \rX
 					l.input[i] = ItemNewline // 将 \r 替换为 \n
 				}
@@ -66,6 +81,7 @@ func (l *Lexer) NextLine() (ret []byte) {
 			l.input[i], l.input[i+1], l.input[i+2] = '\xEF', '\xBF', '\xBD'
 			l.length += 2 // 重新计算总长
 			l.width = 3
+			l.expands = append(l.expands, i-l.offset)
 			continue
 		}
 
@@ -77,5 +93,68 @@ func (l *Lexer) NextLine() (ret []byte) {
 	}
 	ret = l.input[l.offset:i]
 	l.offset = i
+
+	// 记录该行在原始输入中的范围，替换 \u0000 时每处多出两个字节，移除 \r 时少一个字节
+	originalLen := len(ret) - 2*len(l.expands) + removedCR
+	l.lineStarts = append(l.lineStarts, l.originalOffset)
+	l.lineEnds = append(l.lineEnds, l.originalOffset+originalLen-1-removedCR)
+	l.lineBlanks = append(l.lineBlanks, IsBlankLine(ret))
+	l.originalOffset += originalLen
+	return
+}
+
+// OriginalOffset 返回最近一次 NextLine 返回的行中下标 i 处的字节在原始输入中的字节偏移。
+func (l *Lexer) OriginalOffset(i int) int {
+	ret := i
+	for _, e := range l.expands {
+		if i <= e {
+			break
+		}
+		if i < e+3 { // 落在 \uFFFD 中间的话就归到 \u0000 上
+			ret -= i - e
+			break
+		}
+		ret -= 2
+	}
+	return l.lineStarts[len(l.lineStarts)-1] + ret
+}
+
+// Remains 返回还没有读取的输入。
+func (l *Lexer) Remains() []byte {
+	return l.input[l.offset:]
+}
+
+// Expands 返回最近一次 NextLine 返回的行中 \u0000 被替换为 \uFFFD 的位置（相对行首）。
+func (l *Lexer) Expands() []int {
+	return l.expands
+}
+
+// LineStart 返回第 lineNum 行（从 1 开始）行首在原始输入中的字节偏移。
+func (l *Lexer) LineStart(lineNum int) int {
+	return l.lineStarts[lineNum-1]
+}
+
+// LineEnd 返回第 lineNum 行（从 1 开始）内容（不含换行符）结尾在原始输入中的字节偏移。
+func (l *Lexer) LineEnd(lineNum int) int {
+	return l.lineEnds[lineNum-1]
+}
+
+// IsBlankLine 判断第 lineNum 行（从 1 开始）是否是空行。
+func (l *Lexer) IsBlankLine(lineNum int) bool {
+	return l.lineBlanks[lineNum-1]
+}
+
+// Lines 返回已经读取的行数。
+func (l *Lexer) Lines() int {
+	return len(l.lineStarts)
+}
+
+// Position 返回原始输入中字节偏移 offset 处所在的行号和列号（均从 1 开始，列号按字节计）。
+func (l *Lexer) Position(offset int) (line, column int) {
+	line = sort.Search(len(l.lineStarts), func(i int) bool { return l.lineStarts[i] > offset })
+	if 1 > line {
+		return 1, offset + 1
+	}
+	column = offset - l.lineStarts[line-1] + 1
 	return
 }
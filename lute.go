@@ -12,6 +12,10 @@
 package lute
 
 import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
 	"strings"
 
 	"github.com/88250/lute/ast"
@@ -24,6 +28,9 @@ import (
 const Version = "1.6.5"
 
 // Lute 描述了 Lute 引擎的顶层使用入口。
+//
+// 引擎配置完成后可以在多个协程中并发使用，各个方法会在选项副本上切换编辑模式，不会修改引擎本身。设置方法（SetXXX、PutXXX、RegisterXXX）不是并发安全的，
+// 需要在共享引擎之前调用，按调用覆盖选项请使用 With。
 type Lute struct {
 	*parse.Options // 解析和渲染选项配置
 
@@ -37,6 +44,8 @@ type Lute struct {
 	Md2VditorIRDOMRendererFuncs        map[ast.NodeType]render.ExtRendererFunc // 用户自定义的 Md2VditorIRDOM 渲染器函数
 	Md2VditorIRBlockDOMRendererFuncs   map[ast.NodeType]render.ExtRendererFunc // 用户自定义的 Md2VditorIRBlockDOM 渲染器函数
 	Md2VditorSVDOMRendererFuncs        map[ast.NodeType]render.ExtRendererFunc // 用户自定义的 Md2VditorSVDOM 渲染器函数
+	FormatRendererFuncs                map[ast.NodeType]render.ExtRendererFunc // 用户自定义的 Format 渲染器函数
+	FormatOptions                      *render.FormatOptions                   // 格式化输出风格配置
 }
 
 // New 创建一个新的 Lute 引擎，默认启用：
@@ -66,9 +75,69 @@ func New(opts ...Option) (ret *Lute) {
 	ret.Md2VditorIRDOMRendererFuncs = map[ast.NodeType]render.ExtRendererFunc{}
 	ret.Md2VditorIRBlockDOMRendererFuncs = map[ast.NodeType]render.ExtRendererFunc{}
 	ret.Md2VditorSVDOMRendererFuncs = map[ast.NodeType]render.ExtRendererFunc{}
+	ret.FormatRendererFuncs = map[ast.NodeType]render.ExtRendererFunc{}
+	ret.FormatOptions = render.NewFormatOptions()
 	return ret
 }
 
+// With 返回使用 opts 覆盖选项后的引擎副本，副本拥有独立的选项和渲染器函数，对其进行设置不会影响 lute。
+//
+// 引擎的各个方法不会修改选项，所以配置完成后同一个引擎可以在多个协程中并发使用，需要按调用覆盖选项时通过 With 派生副本：
+//
+//     html := engine.With(func(l *lute.Lute) { l.SetToC(true) }).MarkdownStr("", markdown)
+func (lute *Lute) With(opts ...Option) (ret *Lute) {
+	ret = lute.fork()
+	ret.HTML2MdRendererFuncs = copyRendererFuncs(lute.HTML2MdRendererFuncs)
+	ret.HTML2VditorDOMRendererFuncs = copyRendererFuncs(lute.HTML2VditorDOMRendererFuncs)
+	ret.HTML2VditorIRDOMRendererFuncs = copyRendererFuncs(lute.HTML2VditorIRDOMRendererFuncs)
+	ret.HTML2VditorIRBlockDOMRendererFuncs = copyRendererFuncs(lute.HTML2VditorIRBlockDOMRendererFuncs)
+	ret.HTML2VditorSVDOMRendererFuncs = copyRendererFuncs(lute.HTML2VditorSVDOMRendererFuncs)
+	ret.Md2HTMLRendererFuncs = copyRendererFuncs(lute.Md2HTMLRendererFuncs)
+	ret.Md2VditorDOMRendererFuncs = copyRendererFuncs(lute.Md2VditorDOMRendererFuncs)
+	ret.Md2VditorIRDOMRendererFuncs = copyRendererFuncs(lute.Md2VditorIRDOMRendererFuncs)
+	ret.Md2VditorIRBlockDOMRendererFuncs = copyRendererFuncs(lute.Md2VditorIRBlockDOMRendererFuncs)
+	ret.Md2VditorSVDOMRendererFuncs = copyRendererFuncs(lute.Md2VditorSVDOMRendererFuncs)
+	ret.FormatRendererFuncs = copyRendererFuncs(lute.FormatRendererFuncs)
+	formatOptions := *lute.FormatOptions
+	ret.FormatOptions = &formatOptions
+	if nil != lute.InlineSyntaxes {
+		ret.InlineSyntaxes = make(map[byte][]*parse.InlineSyntax, len(lute.InlineSyntaxes))
+		for trigger, syntaxes := range lute.InlineSyntaxes {
+			ret.InlineSyntaxes[trigger] = append([]*parse.InlineSyntax{}, syntaxes...)
+		}
+	}
+	ret.BlockSyntaxes = append([]parse.BlockSyntax{}, lute.BlockSyntaxes...)
+	for _, opt := range opts {
+		opt(ret)
+	}
+	return
+}
+
+// fork 返回一个选项副本的浅拷贝引擎，用于在调用时切换编辑模式等选项而不修改共享的 lute。
+func (lute *Lute) fork() (ret *Lute) {
+	options := *lute.Options
+	ret = &Lute{}
+	*ret = *lute
+	ret.Options = &options
+	return
+}
+
+func copyRendererFuncs(rendererFuncs map[ast.NodeType]render.ExtRendererFunc) (ret map[ast.NodeType]render.ExtRendererFunc) {
+	ret = make(map[ast.NodeType]render.ExtRendererFunc, len(rendererFuncs))
+	for nodeType, rendererFunc := range rendererFuncs {
+		ret[nodeType] = rendererFunc
+	}
+	return
+}
+
+func copyStrMap(m map[string]string) (ret map[string]string) {
+	ret = make(map[string]string, len(m))
+	for k, v := range m {
+		ret[k] = v
+	}
+	return
+}
+
 func NewOptions() *parse.Options {
 	emojis, emoji := parse.NewEmojis()
 	return &parse.Options{
@@ -84,6 +153,9 @@ func NewOptions() *parse.Options {
 		CodeSyntaxHighlightStyleName:   "github",
 		Footnotes:                      true,
 		ToC:                            false,
+		ToCDepth:                       0,
+		ToCList:                        "",
+		HeadingNumbering:               false,
 		HeadingID:                      true,
 		AutoSpace:                      true,
 		FixTermTypo:                    true,
@@ -100,6 +172,8 @@ func NewOptions() *parse.Options {
 		RenderListStyle:                false,
 		ChineseParagraphBeginningSpace: false,
 		YamlFrontMatter:                true,
+		TomlFrontMatter:                false,
+		JSONFrontMatter:                false,
 		BlockRef:                       false,
 		Mark:                           false,
 		KramdownIAL:                    false,
@@ -109,13 +183,47 @@ func NewOptions() *parse.Options {
 // Markdown 将 markdown 文本字节数组处理为相应的 html 字节数组。name 参数仅用于标识文本，比如可传入 id 或者标题，也可以传入 ""。
 func (lute *Lute) Markdown(name string, markdown []byte) (html []byte) {
 	tree := parse.Parse(name, markdown, lute.Options)
-	renderer := render.NewHtmlRenderer(tree)
+	html = lute.renderHTML(lute.newHtmlRenderer(tree))
+	return
+}
+
+// MarkdownE 和 Markdown 一样将 markdown 处理为 HTML，但是会恢复处理过程中发生的 panic 并返回错误。
+//
+// 解析失败返回 *parse.ParseError，渲染失败返回 *render.RenderError，其中包含了引发 panic 的节点类型，超出资源限制返回 *parse.LimitError。
+func (lute *Lute) MarkdownE(name string, markdown []byte) (html []byte, err error) {
+	return lute.MarkdownContext(context.Background(), name, markdown)
+}
+
+// MarkdownContext 和 MarkdownE 一样将 markdown 处理为 HTML，ctx 被取消或者超时的话中止处理并返回 ctx.Err()。
+func (lute *Lute) MarkdownContext(ctx context.Context, name string, markdown []byte) (html []byte, err error) {
+	tree, err := parse.ParseContext(ctx, name, markdown, lute.Options)
+	if nil != err {
+		return
+	}
+
+	renderer := lute.newHtmlRenderer(tree)
+	defer renderer.Recover(&err)
+	html = lute.renderHTML(renderer)
+	if err = renderer.Err(); nil != err {
+		html = nil
+	}
+	return
+}
+
+// newHtmlRenderer 构造用于渲染语法树 tree 的 HTML 渲染器，并注册用户自定义的渲染函数。
+func (lute *Lute) newHtmlRenderer(tree *parse.Tree) (ret *render.HtmlRenderer) {
+	ret = render.NewHtmlRenderer(tree)
 	for nodeType, rendererFunc := range lute.Md2HTMLRendererFuncs {
-		renderer.ExtRendererFuncs[nodeType] = rendererFunc
+		ret.ExtRendererFuncs[nodeType] = rendererFunc
 	}
+	return
+}
+
+// renderHTML 使用渲染器 renderer 渲染 HTML，开启脚注的话会在末尾追加脚注定义。
+func (lute *Lute) renderHTML(renderer *render.HtmlRenderer) (html []byte) {
 	html = renderer.Render()
-	if lute.Options.Footnotes && 0 < len(tree.Context.FootnotesDefs) {
-		html = renderer.RenderFootnotesDefs(tree.Context)
+	if lute.Options.Footnotes && 0 < len(renderer.Tree.Context.FootnotesDefs) && nil == renderer.Err() {
+		html = renderer.RenderFootnotesDefs(renderer.Tree.Context)
 	}
 	return
 }
@@ -127,11 +235,70 @@ func (lute *Lute) MarkdownStr(name, markdown string) (html string) {
 	return
 }
 
+// MarkdownTo 从 r 读取 markdown 文本，将渲染得到的 HTML 按块依次写入 w。
+//
+// 和 Markdown 不同，渲染过程中每渲染完一个顶层块就会写入 w 一次，不会在内存中累积整个 HTML 输出，适合导出大文档或者在 HTTP 处理中流式响应。
+func (lute *Lute) MarkdownTo(w io.Writer, name string, r io.Reader) (err error) {
+	markdown, err := ioutil.ReadAll(r)
+	if nil != err {
+		return
+	}
+
+	tree := parse.Parse(name, markdown, lute.Options)
+	renderer := lute.newHtmlRenderer(tree)
+	if err = renderer.RenderTo(w); nil != err {
+		return
+	}
+	if lute.Options.Footnotes && 0 < len(tree.Context.FootnotesDefs) {
+		renderer.RenderFootnotesDefs(tree.Context)
+		err = renderer.Flush(w)
+	}
+	return
+}
+
 // Format 将 markdown 文本字节数组进行格式化。
 func (lute *Lute) Format(name string, markdown []byte) (formatted []byte) {
 	tree := parse.Parse(name, markdown, lute.Options)
-	renderer := render.NewFormatRenderer(tree)
+	formatted = lute.FormatTree(tree)
+	return
+}
+
+// FormatTree 将语法树 tree 格式化为 markdown 文本字节数组，可用于修改语法树（比如通过 tree.SetFrontMatter 更新元数据）后重新输出。
+func (lute *Lute) FormatTree(tree *parse.Tree) (formatted []byte) {
+	formatted = lute.newFormatRenderer(tree).Render()
+	return
+}
+
+// FormatE 和 Format 一样格式化 markdown，但是会恢复处理过程中发生的 panic 并返回错误。
+//
+// 解析失败返回 *parse.ParseError，渲染失败返回 *render.RenderError，其中包含了引发 panic 的节点类型，超出资源限制返回 *parse.LimitError。
+func (lute *Lute) FormatE(name string, markdown []byte) (formatted []byte, err error) {
+	return lute.FormatContext(context.Background(), name, markdown)
+}
+
+// FormatContext 和 FormatE 一样格式化 markdown，ctx 被取消或者超时的话中止处理并返回 ctx.Err()。
+func (lute *Lute) FormatContext(ctx context.Context, name string, markdown []byte) (formatted []byte, err error) {
+	tree, err := parse.ParseContext(ctx, name, markdown, lute.Options)
+	if nil != err {
+		return
+	}
+
+	renderer := lute.newFormatRenderer(tree)
+	defer renderer.Recover(&err)
 	formatted = renderer.Render()
+	if err = renderer.Err(); nil != err {
+		formatted = nil
+	}
+	return
+}
+
+// newFormatRenderer 构造用于格式化语法树 tree 的渲染器，并设置格式化风格和注册用户自定义的渲染函数。
+func (lute *Lute) newFormatRenderer(tree *parse.Tree) (ret *render.FormatRenderer) {
+	ret = render.NewFormatRenderer(tree)
+	ret.FormatOptions = lute.FormatOptions
+	for nodeType, rendererFunc := range lute.FormatRendererFuncs {
+		ret.ExtRendererFuncs[nodeType] = rendererFunc
+	}
 	return
 }
 
@@ -142,6 +309,52 @@ func (lute *Lute) FormatStr(name, markdown string) (formatted string) {
 	return
 }
 
+// FormatTo 从 r 读取 markdown 文本，将格式化结果按块依次写入 w。
+func (lute *Lute) FormatTo(w io.Writer, name string, r io.Reader) (err error) {
+	markdown, err := ioutil.ReadAll(r)
+	if nil != err {
+		return
+	}
+
+	tree := parse.Parse(name, markdown, lute.Options)
+	err = lute.newFormatRenderer(tree).RenderTo(w)
+	return
+}
+
+// RegisterInlineSyntax 注册用户自定义的行级语法 syntax。
+//
+// 解析时遇到 syntax.Trigger 字节会先尝试自定义语法再尝试内置语法，生成的 syntax.NodeType 节点需要在用到的渲染器函数中注册渲染函数，
+// 比如 Md2HTMLRendererFuncs、Md2VditorIRDOMRendererFuncs，格式化时没有注册渲染函数的话会原样输出该节点对应的 Markdown 原文。
+func (lute *Lute) RegisterInlineSyntax(syntax *parse.InlineSyntax) {
+	if nil == lute.InlineSyntaxes {
+		lute.InlineSyntaxes = map[byte][]*parse.InlineSyntax{}
+	}
+	lute.InlineSyntaxes[syntax.Trigger] = append(lute.InlineSyntaxes[syntax.Trigger], syntax)
+}
+
+// RegisterBlockSyntax 注册用户自定义的块级语法 syntax。
+//
+// 解析时会先尝试自定义语法再尝试内置语法，生成的 syntax.NodeType() 节点需要在用到的渲染器函数中注册渲染函数，
+// 格式化时没有注册渲染函数的话会原样输出该节点对应的 Markdown 原文。
+func (lute *Lute) RegisterBlockSyntax(syntax parse.BlockSyntax) {
+	// 限制容量使 append 总是分配新数组，避免写入与其他引擎共享的底层数组
+	lute.BlockSyntaxes = append(lute.BlockSyntaxes[:len(lute.BlockSyntaxes):len(lute.BlockSyntaxes)], syntax)
+}
+
+// Lint 解析 markdown 文本字节数组并返回解析过程中发现的诊断信息，比如没有闭合的围栏代码块、没有定义的链接引用和脚注、重复的标题 ID 等。
+func (lute *Lute) Lint(name string, markdown []byte) (diagnostics []*parse.Diagnostic) {
+	tree := parse.Parse(name, markdown, lute.Options)
+	diagnostics = tree.Diagnostics
+	return
+}
+
+// Outline 解析 markdown 文本字节数组并返回文档大纲，大纲中的标题按照级别嵌套。
+func (lute *Lute) Outline(markdown []byte) (outline []*render.OutlineHeading) {
+	tree := parse.Parse("", markdown, lute.Options)
+	outline = render.Outline(tree)
+	return
+}
+
 // TextBundle 将 markdown 文本字节数组进行 TextBundle 处理。
 func (lute *Lute) TextBundle(name string, markdown []byte, linkPrefixes []string) (textbundle []byte, originalLinks []string) {
 	tree := parse.Parse(name, markdown, lute.Options)
@@ -186,10 +399,13 @@ func (lute *Lute) GetEmojis() (ret map[string]string) {
 
 // PutEmojis 将指定的 emojiMap 合并覆盖已有的 Emoji 字典。
 func (lute *Lute) PutEmojis(emojiMap map[string]string) {
+	// 合并到字典副本中，避免修改其他引擎副本正在使用的字典
+	aliasEmoji, emojiAlias := copyStrMap(lute.AliasEmoji), copyStrMap(lute.EmojiAlias)
 	for k, v := range emojiMap {
-		lute.AliasEmoji[k] = v
-		lute.EmojiAlias[v] = k
+		aliasEmoji[k] = v
+		emojiAlias[v] = k
 	}
+	lute.AliasEmoji, lute.EmojiAlias = aliasEmoji, emojiAlias
 }
 
 // GetTerms 返回术语字典。
@@ -199,9 +415,11 @@ func (lute *Lute) GetTerms() map[string]string {
 
 // PutTerms 将制定的 termMap 合并覆盖已有的术语字典。
 func (lute *Lute) PutTerms(termMap map[string]string) {
+	terms := copyStrMap(lute.Terms)
 	for k, v := range termMap {
-		lute.Terms[k] = v
+		terms[k] = v
 	}
+	lute.Terms = terms
 }
 
 // Option 描述了解析渲染选项设置函数签名。
@@ -261,6 +479,18 @@ func (lute *Lute) SetToC(b bool) {
 	lute.ToC = b
 }
 
+func (lute *Lute) SetToCDepth(depth int) {
+	lute.ToCDepth = depth
+}
+
+func (lute *Lute) SetToCList(tag string) {
+	lute.ToCList = tag
+}
+
+func (lute *Lute) SetHeadingNumbering(b bool) {
+	lute.HeadingNumbering = b
+}
+
 func (lute *Lute) SetHeadingID(b bool) {
 	lute.HeadingID = b
 }
@@ -289,6 +519,10 @@ func (lute *Lute) SetEmojiSite(emojiSite string) {
 	lute.EmojiSite = emojiSite
 }
 
+func (lute *Lute) SetHeadingIDSlugger(slugger parse.Slugger) {
+	lute.HeadingIDSlugger = slugger
+}
+
 func (lute *Lute) SetHeadingAnchor(b bool) {
 	lute.HeadingAnchor = b
 }
@@ -317,6 +551,26 @@ func (lute *Lute) GetLinkBase() string {
 	return lute.LinkBase
 }
 
+func (lute *Lute) SetLinkSchemes(schemes []string) {
+	lute.LinkSchemes = schemes
+}
+
+func (lute *Lute) SetExternalLinkTarget(b bool) {
+	lute.ExternalLinkTarget = b
+}
+
+func (lute *Lute) SetInternalLinkHosts(hosts []string) {
+	lute.InternalLinkHosts = hosts
+}
+
+func (lute *Lute) SetExternalLinkRedirect(redirect string) {
+	lute.ExternalLinkRedirect = redirect
+}
+
+func (lute *Lute) SetLinkCallback(callback parse.LinkCallback) {
+	lute.LinkCallback = callback
+}
+
 func (lute *Lute) SetVditorCodeBlockPreview(b bool) {
 	lute.VditorCodeBlockPreview = b
 }
@@ -333,6 +587,16 @@ func (lute *Lute) SetSanitize(b bool) {
 	lute.Sanitize = b
 }
 
+// SetSanitizePolicy 设置安全过滤策略并启用安全过滤，policy 为 nil 的话恢复使用默认的 render.TrustedPolicy。
+func (lute *Lute) SetSanitizePolicy(policy *render.SanitizePolicy) {
+	if nil == policy {
+		lute.Sanitizer = nil
+		return
+	}
+	lute.Sanitizer = policy
+	lute.Sanitize = true
+}
+
 func (lute *Lute) SetImageLazyLoading(dataSrc string) {
 	lute.ImageLazyLoading = dataSrc
 }
@@ -345,10 +609,24 @@ func (lute *Lute) SetYamlFrontMatter(b bool) {
 	lute.YamlFrontMatter = b
 }
 
+func (lute *Lute) SetTomlFrontMatter(b bool) {
+	lute.TomlFrontMatter = b
+}
+
+func (lute *Lute) SetJSONFrontMatter(b bool) {
+	lute.JSONFrontMatter = b
+}
+
 func (lute *Lute) SetBlockRef(b bool) {
 	lute.BlockRef = b
 }
 
+// SetBlockQueryEmbedResolver 设置内容块查询嵌入 !{{ script }} 的查询结果解析器 resolver。
+// 为 ast.NodeBlockQueryEmbed 注册了自定义渲染函数的话不会调用 resolver，详见 parse.BlockQueryEmbedResolver。
+func (lute *Lute) SetBlockQueryEmbedResolver(resolver parse.BlockQueryEmbedResolver) {
+	lute.BlockQueryEmbedResolver = resolver
+}
+
 func (lute *Lute) SetMark(b bool) {
 	lute.Mark = b
 }
@@ -361,13 +639,55 @@ func (lute *Lute) SetTag(b bool) {
 	lute.Tag = b
 }
 
-func (lute *Lute) SetJSRenderers(options map[string]map[string]*js.Object) {
+func (lute *Lute) SetAdmonition(b bool) {
+	lute.Admonition = b
+}
+
+func (lute *Lute) SetDefinitionList(b bool) {
+	lute.DefinitionList = b
+}
+
+func (lute *Lute) SetExtendedTable(b bool) {
+	lute.ExtendedTable = b
+}
+
+// SetFormatOptions 设置格式化输出风格 options，比如列表标记符、强调标记符和标题风格等，传入 nil 的话恢复默认风格。
+func (lute *Lute) SetFormatOptions(options *render.FormatOptions) {
+	if nil == options {
+		options = render.NewFormatOptions()
+	}
+	lute.FormatOptions = options
+}
+
+// SetKeepLinkRefDefs 设置是否在原位保留链接引用定义，格式化时不再将其统一追加到文档末尾。
+func (lute *Lute) SetKeepLinkRefDefs(b bool) {
+	lute.KeepLinkRefDefs = b
+}
+
+func (lute *Lute) SetMaxBytes(max int) {
+	lute.MaxBytes = max
+}
+
+func (lute *Lute) SetMaxNestingDepth(max int) {
+	lute.MaxNestingDepth = max
+}
+
+func (lute *Lute) SetMaxNodes(max int) {
+	lute.MaxNodes = max
+}
+
+func (lute *Lute) SetMaxOutputBytes(max int) {
+	lute.MaxOutputBytes = max
+}
+
+// SetJSRenderers 注册 JavaScript 端传入的自定义渲染函数，渲染器类型或者格式不正确的话返回错误。
+func (lute *Lute) SetJSRenderers(options map[string]map[string]*js.Object) (err error) {
 	for rendererType, extRenderer := range options["renderers"] {
 		switch extRenderer.Interface().(type) { // 稍微进行一点格式校验
 		case map[string]interface{}:
 			break
 		default:
-			panic("invalid type [" + rendererType + "]")
+			return errors.New("invalid type [" + rendererType + "]")
 		}
 
 		var rendererFuncs map[ast.NodeType]render.ExtRendererFunc
@@ -392,7 +712,7 @@ func (lute *Lute) SetJSRenderers(options map[string]map[string]*js.Object) {
 		} else if "Md2VditorSVDOM" == rendererType {
 			rendererFuncs = lute.Md2VditorSVDOMRendererFuncs
 		} else {
-			panic("unknown ext renderer func [" + rendererType + "]")
+			return errors.New("unknown ext renderer func [" + rendererType + "]")
 		}
 
 		renderFuncs := extRenderer.Interface().(map[string]interface{})
@@ -406,4 +726,5 @@ func (lute *Lute) SetJSRenderers(options map[string]map[string]*js.Object) {
 			}
 		}
 	}
+	return
 }
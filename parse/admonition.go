@@ -0,0 +1,87 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/lex"
+)
+
+// AdmonitionGFMTypes 定义了 GitHub 风格提示块 > [!NOTE] 支持的类型。
+var AdmonitionGFMTypes = []string{"note", "tip", "important", "warning", "caution"}
+
+func AdmonitionContinue(admonition *ast.Node, context *Context) int {
+	if 0 == admonition.AdmonitionFenceLen {
+		// GitHub 风格的提示块和块引用一样需要 > 接续
+		return BlockquoteContinue(admonition, context)
+	}
+
+	if context.indented || !context.isAdmonitionClose(context.currentLine[context.nextNonspace:], admonition.AdmonitionFenceLen) {
+		return 0
+	}
+	for c := admonition.LastChild; nil != c && !c.Close; c = c.LastChild {
+		if (ast.NodeAdmonition == c.Type && 0 < c.AdmonitionFenceLen) || (ast.NodeParagraph != c.Type && c.AcceptLines()) {
+			// 闭合标记符留给嵌套的提示块或者代码块等叶子块处理
+			return 0
+		}
+	}
+
+	for nil != context.Tip && admonition != context.Tip {
+		context.finalize(context.Tip, context.lineNum-1)
+	}
+	context.finalize(admonition, context.lineNum)
+	return 2
+}
+
+// parseAdmonition 解析 :::type title 形式的提示块开始标记符。
+func (t *Tree) parseAdmonition() (ok bool, fenceLen int, typ string, title []byte) {
+	tokens := t.Context.currentLine[t.Context.nextNonspace:]
+	for ; fenceLen < len(tokens) && lex.ItemColon == tokens[fenceLen]; fenceLen++ {
+	}
+	if 3 > fenceLen {
+		return
+	}
+
+	tokens = tokens[fenceLen:]
+	i := 0
+	for ; i < len(tokens) && lex.IsASCIILetterNumHyphen(tokens[i]); i++ {
+	}
+	if 1 > i || (i < len(tokens) && !lex.IsWhitespace(tokens[i])) {
+		return
+	}
+	return true, fenceLen, strings.ToLower(string(tokens[:i])), lex.TrimWhitespace(tokens[i:])
+}
+
+// parseGFMAdmonition 解析块引用标记符 > 后的 [!TYPE] 提示块类型标记。
+func (t *Tree) parseGFMAdmonition() (ok bool, typ string) {
+	tokens := lex.TrimWhitespace(t.Context.currentLine[t.Context.offset:])
+	if !bytes.HasPrefix(tokens, []byte("[!")) || !bytes.HasSuffix(tokens, []byte("]")) {
+		return
+	}
+
+	typ = strings.ToLower(string(tokens[2 : len(tokens)-1]))
+	for _, gfmType := range AdmonitionGFMTypes {
+		if gfmType == typ {
+			return true, typ
+		}
+	}
+	return false, ""
+}
+
+func (context *Context) isAdmonitionClose(tokens []byte, fenceLen int) bool {
+	closeLen := 0
+	for ; closeLen < len(tokens) && lex.ItemColon == tokens[closeLen]; closeLen++ {
+	}
+	return fenceLen <= closeLen && lex.IsBlankLine(tokens[closeLen:])
+}
@@ -123,38 +123,188 @@ func (t *Tree) parseBlockEmbed() (ret *ast.Node) {
 	return
 }
 
-func (t *Tree) parseBlockQueryEmbed() (ret *ast.Node) {
-	tokens := t.Context.currentLine[t.Context.nextNonspace:]
-	chinese := bytes.HasPrefix(tokens, []byte("！{{"))
-	startCaret := bytes.HasPrefix(tokens, []byte(util.Caret+"!{{"))
-	if 6 > t.Context.currentLineLen || (!bytes.HasPrefix(tokens, []byte("!{{")) && !chinese && !startCaret) {
-		return
+// BlockQueryEmbedResolver 用于宿主应用提供内容块查询嵌入的查询结果，参数 script 为查询语句，返回查询结果的 Markdown 文本。
+// 查询结果会在渲染时解析并渲染到查询嵌入所在的位置。
+//
+// 解析器只由内置的 HTML 渲染器和 Vditor 渲染器在渲染 NodeBlockQueryEmbed 节点时调用，格式化等输出 Markdown 的渲染器不调用。
+// 通过 Md2HTMLRendererFuncs 等为 NodeBlockQueryEmbed 注册了自定义渲染函数的话，内置渲染被替换，解析器也不会被调用，
+// 需要在自定义渲染函数中自行调用解析器，查询语句可以通过 BlockQueryEmbedScript 获取。
+type BlockQueryEmbedResolver func(script string) (markdown string, err error)
+
+// blockQueryEmbedOpenMarkers 内容块查询嵌入的开始标记符，包括中文感叹号以及 Vditor 插入符开头的形式。
+var blockQueryEmbedOpenMarkers = [][]byte{[]byte("!{{"), []byte("！{{"), []byte(util.Caret + "!{{")}
+
+// isBlockQueryEmbedStart 判断当前行是否以内容块查询嵌入开始标记符 !{{ 开头。
+func (t *Tree) isBlockQueryEmbedStart() bool {
+	return isBlockQueryEmbedOpen(t.Context.currentLine[t.Context.nextNonspace:])
+}
+
+// isBlockQueryEmbedOpen 判断 tokens 是否以内容块查询嵌入开始标记符 !{{ 开头。
+func isBlockQueryEmbedOpen(tokens []byte) bool {
+	for _, marker := range blockQueryEmbedOpenMarkers {
+		if bytes.HasPrefix(tokens, marker) {
+			return true
+		}
 	}
-	if chinese {
-		tokens = bytes.Replace(tokens, []byte("！{{"), []byte("!{{"), 1)
+	return false
+}
+
+// isBlockQueryEmbedClosed 判断当前行开始的内容块查询嵌入是否能够闭合，即当前行或者后续的某一行以闭合标记符 }} 结尾。
+//
+// 不能闭合的话开始标记符所在行作为段落处理，避免吞掉文档剩余的所有内容。
+func (t *Tree) isBlockQueryEmbedClosed() bool {
+	line := t.Context.currentLine[t.Context.nextNonspace:]
+	if _, _, _, closed := splitBlockQueryEmbed(line); closed {
+		return true
 	}
-	if startCaret {
-		tokens = bytes.Replace(tokens, []byte(util.Caret+"!{{"), []byte("!{{"), 1)
+
+	if !t.Context.noBlockQueryEmbedClose {
+		for remains := t.lexer.Remains(); 0 < len(remains); {
+			next := remains
+			if end := bytes.IndexByte(remains, lex.ItemNewline); 0 <= end {
+				next, remains = remains[:end], remains[end+1:]
+			} else {
+				remains = nil
+			}
+			next = bytes.TrimRight(next, " \t\r")
+			next = bytes.TrimRight(bytes.TrimSuffix(next, util.CaretTokens), " \t")
+			if isBlockQueryEmbedClose(next) {
+				return !isBlockQueryEmbedOpen(lex.TrimWhitespace(next)) // 闭合标记符属于后面另一个查询嵌入的话不能闭合
+			}
+			if isBlockQueryEmbedOpen(lex.TrimWhitespace(next)) {
+				break
+			}
+			if 0 == len(remains) {
+				// 后续开始的查询嵌入只会看到更少的剩余输入，不用再查找
+				t.Context.noBlockQueryEmbedClose = true
+			}
+		}
 	}
 
-	tokens = tokens[3:]
-	tokens = tokens[:len(tokens)-1] // 去掉结尾换行
+	end := len(bytes.TrimRight(t.Context.currentLine, " \t\n"))
+	t.Diagnostics = append(t.Diagnostics, &Diagnostic{Severity: SeverityError, Code: RuleUnclosedBlockQueryEmbed, Message: "block query embed is not closed with }}",
+		StartPos: t.Context.pos(t.Context.nextNonspace), EndPos: t.Context.pos(end)})
+	return false
+}
 
-	// TODO: Script
-	script := "SELECT * FROM blocks"
+// containsBlockQueryEmbedOpen 判断 tokens 中是否包含内容块查询嵌入开始标记符 !{{。
+func containsBlockQueryEmbedOpen(tokens []byte) bool {
+	for _, marker := range blockQueryEmbedOpenMarkers[:2] { // 插入符开头的形式也包含 !{{
+		if bytes.Contains(tokens, marker) {
+			return true
+		}
+	}
+	return false
+}
 
-	endCaret := bytes.HasSuffix(tokens, util.CaretTokens)
-	tokens = bytes.TrimSuffix(tokens, util.CaretTokens)
+// splitBlockQueryEmbed 剔除内容块查询嵌入 tokens 开头的 !{{ 和结尾的空白、插入符以及闭合标记符 }}，返回查询脚本原文。
+func splitBlockQueryEmbed(tokens []byte) (script []byte, startCaret, endCaret, closed bool) {
+	for i, marker := range blockQueryEmbedOpenMarkers {
+		if bytes.HasPrefix(tokens, marker) {
+			tokens = tokens[len(marker):]
+			startCaret = 2 == i
+			break
+		}
+	}
 
-	ret = &ast.Node{Type: ast.NodeBlockQueryEmbed}
-	ret.AppendChild(&ast.Node{Type: ast.NodeBang})
-	ret.AppendChild(&ast.Node{Type: ast.NodeOpenBracket})
-	ret.AppendChild(&ast.Node{Type: ast.NodeOpenBracket})
-	ret.AppendChild(&ast.Node{Type: ast.NodeBlockQueryEmbedScript, Tokens: []byte(script)})
-	ret.AppendChild(&ast.Node{Type: ast.NodeCloseBracket})
-	ret.AppendChild(&ast.Node{Type: ast.NodeCloseBracket})
-	if endCaret || startCaret {
-		ret.AppendChild(&ast.Node{Type: ast.NodeText, Tokens: util.CaretTokens})
+	tokens = bytes.TrimRight(tokens, " \t\n")
+	if endCaret = bytes.HasSuffix(tokens, util.CaretTokens); endCaret {
+		tokens = bytes.TrimRight(tokens[:len(tokens)-len(util.CaretTokens)], " \t")
 	}
+	if closed = isBlockQueryEmbedClose(tokens); closed {
+		tokens = tokens[:len(tokens)-2]
+	}
+	script = tokens
 	return
 }
+
+// isBlockQueryEmbedClose 判断 tokens 是否以没有被转义的闭合标记符 }} 结尾。
+func isBlockQueryEmbedClose(tokens []byte) bool {
+	length := len(tokens)
+	if 2 > length || lex.ItemCloseCurlyBrace != tokens[length-1] || lex.ItemCloseCurlyBrace != tokens[length-2] {
+		return false
+	}
+	backslashes := 0
+	for i := length - 3; 0 <= i && lex.ItemBackslash == tokens[i]; i-- {
+		backslashes++
+	}
+	return 0 == backslashes%2
+}
+
+// BlockQueryEmbedScript 返回内容块查询嵌入脚本原文 script 对应的查询语句：去掉 Vditor 插入符，
+// 将转义序列 \} 和 \\ 还原为 } 和 \，并去掉首尾空白。
+func BlockQueryEmbedScript(script []byte) string {
+	script = bytes.ReplaceAll(script, util.CaretTokens, nil)
+	ret := make([]byte, 0, len(script))
+	for i := 0; i < len(script); i++ {
+		if lex.ItemBackslash == script[i] && i+1 < len(script) && (lex.ItemCloseCurlyBrace == script[i+1] || lex.ItemBackslash == script[i+1]) {
+			i++
+		}
+		ret = append(ret, script[i])
+	}
+	return string(lex.TrimWhitespace(ret))
+}
+
+// blockQueryEmbedFinalize 将内容块查询嵌入 embed 累积的行拆分为标记符和查询脚本节点，并校验查询脚本。
+func (context *Context) blockQueryEmbedFinalize(embed *ast.Node) {
+	script, startCaret, endCaret, closed := splitBlockQueryEmbed(embed.Tokens)
+	if !closed {
+		context.diagnose(embed, SeverityError, RuleUnclosedBlockQueryEmbed, "block query embed is not closed with }}")
+	}
+	if msg := validateBlockQueryEmbedScript(BlockQueryEmbedScript(script)); "" != msg {
+		context.diagnose(embed, SeverityWarning, RuleInvalidBlockQueryEmbed, msg)
+	}
+
+	embed.Tokens = nil
+	embed.AppendChild(&ast.Node{Type: ast.NodeBang})
+	embed.AppendChild(&ast.Node{Type: ast.NodeOpenBrace})
+	embed.AppendChild(&ast.Node{Type: ast.NodeOpenBrace})
+	embed.AppendChild(&ast.Node{Type: ast.NodeBlockQueryEmbedScript, Tokens: script})
+	if closed {
+		embed.AppendChild(&ast.Node{Type: ast.NodeCloseBrace})
+		embed.AppendChild(&ast.Node{Type: ast.NodeCloseBrace})
+	}
+	if startCaret || endCaret {
+		embed.AppendChild(&ast.Node{Type: ast.NodeText, Tokens: util.CaretTokens})
+	}
+}
+
+// validateBlockQueryEmbedScript 校验查询语句 script，校验通过的话返回空字符串，否则返回校验失败的原因。
+func validateBlockQueryEmbedScript(script string) string {
+	if "" == script {
+		return "block query embed script is empty"
+	}
+
+	var quote byte
+	parens := 0
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		if 0 != quote {
+			if c == quote {
+				if i+1 < len(script) && quote == script[i+1] { // SQL 中连续两个引号表示引号本身
+					i++
+					continue
+				}
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case lex.ItemSinglequote, lex.ItemDoublequote, lex.ItemBacktick:
+			quote = c
+		case lex.ItemOpenParen:
+			parens++
+		case lex.ItemCloseParen:
+			if parens--; 0 > parens {
+				return "block query embed script has unbalanced parentheses"
+			}
+		}
+	}
+	if 0 != quote {
+		return "block query embed script has an unclosed quote " + string(quote)
+	}
+	if 0 != parens {
+		return "block query embed script has unbalanced parentheses"
+	}
+	return ""
+}
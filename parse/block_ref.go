@@ -75,6 +75,9 @@ func (t *Tree) parseBlockRef(ctx *InlineContext) *ast.Node {
 		break
 	}
 	if !matched {
+		if 0 < len(id) && ast.IsNodeIDPattern(util.BytesToStr(id)) {
+			ctx.diagnose(SeverityWarning, RuleUnclosedBlockRef, "block ref (("+string(id)+" is not closed with ))", savePos, savePos+2+len(id))
+		}
 		ctx.pos = savePos + 1
 		return &ast.Node{Type: ast.NodeText, Tokens: []byte("(")}
 	}
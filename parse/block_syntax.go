@@ -0,0 +1,115 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/lex"
+)
+
+// BlockSyntax 描述了用户自定义的块级语法，比如 :::note 提示块和 +++ TOML Front Matter。
+//
+// 叶子块（AcceptLines 返回 true）会将后续的文本行追加到节点 Tokens 上，容器块会继续将后续的文本行解析为子块。
+type BlockSyntax interface {
+	ast.BlockExt
+
+	// NodeType 返回生成的节点类型，需要在各个渲染器中注册对应的渲染函数。
+	NodeType() ast.NodeType
+
+	// TryStart 判断是否起始该块，line 为当前行去掉缩进后的内容（包含结尾换行），container 为当前匹配到的块节点。
+	// 匹配成功的话返回生成的节点以及起始标记符消费的字节数，余下的内容会作为该块的第一行；匹配失败的话返回 nil。缩进代码块所在行不会调用该函数。
+	TryStart(container *ast.Node, line []byte) (ret *ast.Node, consumed int)
+
+	// Continue 判断块 n 是否可以继续处理当前行，line 为当前行还未消费的内容（包含结尾换行），consumed 为标记符消费的字节数。
+	// 可以继续处理返回 0，不能继续处理返回 1，返回 2 的话说明该行是块的闭合行，该块及其未闭合的子块会被最终化，然后处理下一行。
+	Continue(n *ast.Node, line []byte) (ret, consumed int)
+
+	// Finalize 执行块 n 的最终化处理，比如剔除叶子块 Tokens 中的闭合标记符。
+	Finalize(n *ast.Node)
+}
+
+// parseBlockSyntax 使用用户自定义的块级语法判断当前行是否起始一个块，返回值同 blockStarts。
+func (t *Tree) parseBlockSyntax(container *ast.Node) int {
+	if t.Context.indented {
+		return 0
+	}
+
+	for _, syntax := range t.Context.Option.BlockSyntaxes {
+		line := t.Context.currentLine[t.Context.nextNonspace:]
+		node, consumed := syntax.TryStart(container, line)
+		if nil == node {
+			continue
+		}
+
+		node.Type = syntax.NodeType()
+		node.BlockExt = syntax
+		node.StartPos = t.Context.pos(t.Context.nextNonspace)
+		t.Context.closeUnmatchedBlocks()
+		for !t.Context.Tip.CanContain(node.Type) {
+			t.Context.finalize(t.Context.Tip, t.Context.lineNum-1) // 注意调用 finalize 会向父节点方向进行迭代
+		}
+		t.Context.Tip.AppendChild(node)
+		t.Context.Tip = node
+		t.Context.advanceNextNonspace()
+		t.Context.advanceOffset(blockSyntaxConsumed(line, consumed), false)
+		if syntax.AcceptLines() {
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+// blockSyntaxContinue 判断用户自定义块 n 是否可以继续处理，返回值同 _continue。n 不是用户自定义块的话返回 0。
+func blockSyntaxContinue(n *ast.Node, context *Context) int {
+	syntax := context.blockSyntax(n.Type)
+	if nil == syntax {
+		return 0
+	}
+
+	line := context.currentLine[context.offset:]
+	ret, consumed := syntax.Continue(n, line)
+	switch ret {
+	case 0:
+		context.advanceOffset(blockSyntaxConsumed(line, consumed), false)
+	case 2:
+		for nil != context.Tip && n != context.Tip {
+			context.finalize(context.Tip, context.lineNum-1)
+		}
+		context.finalize(n, context.lineNum)
+	}
+	return ret
+}
+
+// blockSyntaxConsumed 修正用户自定义块级语法消费的字节数，结尾换行需要留给后续处理。
+func blockSyntaxConsumed(line []byte, consumed int) int {
+	max := len(line)
+	if 0 < max && lex.ItemNewline == line[max-1] {
+		max--
+	}
+	if 0 > consumed {
+		return 0
+	}
+	if consumed > max {
+		return max
+	}
+	return consumed
+}
+
+// blockSyntax 返回节点类型 nodeType 对应的用户自定义块级语法，没有的话返回 nil。
+func (context *Context) blockSyntax(nodeType ast.NodeType) BlockSyntax {
+	for _, syntax := range context.Option.BlockSyntaxes {
+		if nodeType == syntax.NodeType() {
+			return syntax
+		}
+	}
+	return nil
+}
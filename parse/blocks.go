@@ -22,8 +22,14 @@ func (t *Tree) parseBlocks() {
 	t.Context.Tip = t.Root
 	t.Context.LinkRefDefs = map[string]*ast.Node{}
 	t.Context.FootnotesDefs = []*ast.Node{}
+	t.Context.sources = map[*ast.Node]*source{}
+	t.Root.StartPos = ast.Position{Line: 1, Column: 1}
 	lines := 0
 	for line := t.lexer.NextLine(); nil != line; line = t.lexer.NextLine() {
+		if t.canceled() {
+			break
+		}
+
 		if t.Context.Option.VditorWYSIWYG || t.Context.Option.VditorIR || t.Context.Option.VditorSV {
 			if !bytes.Equal(line, util.CaretNewlineTokens) && t.Context.Tip.ParentIs(ast.NodeListItem) && bytes.HasPrefix(line, util.CaretTokens) {
 				// 插入符在开头的话移动到上一行结尾，处理 https://github.com/Vanessa219/vditor/issues/633 中的一些情况
@@ -67,6 +73,9 @@ func (t *Tree) incorporateLine(line []byte) {
 	container = t.Root
 	lastChild := container.LastChild
 	for ; nil != lastChild && !lastChild.Close; lastChild = container.LastChild {
+		if t.interrupted() {
+			return
+		}
 		container = lastChild
 		t.Context.findNextNonspace()
 
@@ -96,10 +105,17 @@ func (t *Tree) incorporateLine(line []byte) {
 	for !matchedLeaf {
 		t.Context.findNextNonspace()
 
+		if t.interrupted() {
+			// 超出资源限制或者被取消的话不再起始新的块级节点，处理完该行后中止解析
+			t.Context.advanceNextNonspace()
+			break
+		}
+
 		// 如果不由潜在的节点标记符开头 ^[#`~*+_=<>0-9-${]，则说明不用继续迭代生成子节点
 		// 这里仅做简单判断的话可以提升一些性能
 		maybeMarker := t.Context.currentLine[t.Context.nextNonspace]
 		if !t.Context.indented && // 缩进代码块
+			1 > len(t.Context.Option.BlockSyntaxes) && // 用户自定义块级语法
 			lex.ItemHyphen != maybeMarker && lex.ItemAsterisk != maybeMarker && lex.ItemPlus != maybeMarker && // 无序列表
 			!lex.IsDigit(maybeMarker) && // 有序列表
 			lex.ItemBacktick != maybeMarker && lex.ItemTilde != maybeMarker && // 代码块
@@ -108,6 +124,7 @@ func (t *Tree) incorporateLine(line []byte) {
 			lex.ItemLess != maybeMarker && // HTML 块
 			lex.ItemUnderscore != maybeMarker && lex.ItemEqual != maybeMarker && // Setext 标题
 			lex.ItemDollar != maybeMarker && // 数学公式
+			lex.ItemColon != maybeMarker && // 提示块、定义列表
 			lex.ItemOpenBracket != maybeMarker && // 脚注
 			lex.ItemOpenCurlyBrace != maybeMarker && // kramdown 内联属性列表
 			lex.ItemBang != maybeMarker && "！"[0] != maybeMarker && // 内容块嵌入
@@ -116,12 +133,21 @@ func (t *Tree) incorporateLine(line []byte) {
 			break
 		}
 
+		// 优先尝试用户自定义块级语法
+		if res := t.parseBlockSyntax(container); 0 != res {
+			container = t.Context.Tip
+			t.checkNestingDepth(container)
+			matchedLeaf = 2 == res
+			continue
+		}
+
 		// 逐个尝试是否可以起始一个块级节点
 		i := 0
 		for i < startsLen {
 			res := blockStarts[i](t, container)
 			if res == 1 { // 匹配到容器块，继续迭代下降过程
 				container = t.Context.Tip
+				t.checkNestingDepth(container)
 				break
 			} else if res == 2 { // 匹配到叶子块，跳出迭代下降过程
 				container = t.Context.Tip
@@ -160,7 +186,9 @@ func (t *Tree) incorporateLine(line []byte) {
 				typ == ast.NodeBlockquote || // 块引用行肯定不会是空行因为至少有一个 >
 				(typ == ast.NodeCodeBlock && isFenced) || // 围栏代码块不计入空行判断
 				(typ == ast.NodeMathBlock) || // 数学公式块不计入空行判断
-				(typ == ast.NodeListItem && nil == container.FirstChild)) // 内容为空的列表项也不计入空行判断
+				(typ == ast.NodeBlockQueryEmbed) || // 内容块查询嵌入不计入空行判断
+				(typ == ast.NodeListItem && nil == container.FirstChild) || // 内容为空的列表项也不计入空行判断
+				(typ == ast.NodeAdmonition && nil == container.FirstChild)) // 提示块开始标记符所在行不计入空行判断
 		// 因为列表是块级容器（可进行嵌套），所以需要在父节点方向上传播 LastLineBlank
 		// LastLineBlank 目前仅在判断列表紧凑模式上使用
 		for cont := container; nil != cont; cont = cont.Parent {
@@ -187,6 +215,11 @@ func (t *Tree) incorporateLine(line []byte) {
 						bytes.HasSuffix(container.Tokens, MathBlockMarkerCaretNewline)) {
 					t.Context.finalize(container, t.Context.lineNum)
 				}
+			case ast.NodeBlockQueryEmbed:
+				// 查询脚本以 }} 结尾时闭合
+				if _, _, _, closed := splitBlockQueryEmbed(container.Tokens); closed {
+					t.Context.finalize(container, t.Context.lineNum)
+				}
 			}
 		} else if t.Context.offset < t.Context.currentLineLen && !t.Context.blank {
 			// 普通段落开始
@@ -228,6 +261,15 @@ var blockStarts = []blockStartFunc{
 			markers = append(markers, whitespace)
 		}
 		t.Context.closeUnmatchedBlocks()
+		if t.Context.Option.Admonition {
+			// GitHub 风格的提示块 > [!NOTE]
+			if ok, typ := t.parseGFMAdmonition(); ok {
+				admonition := t.Context.addChild(ast.NodeAdmonition, t.Context.nextNonspace)
+				admonition.AdmonitionType = typ
+				t.Context.advanceOffset(len(bytes.TrimRight(t.Context.currentLine[t.Context.offset:], "\n")), false)
+				return 1
+			}
+		}
 		t.Context.addChild(ast.NodeBlockquote, t.Context.nextNonspace)
 		t.Context.addChildMarker(ast.NodeBlockquoteMarker, markers)
 		return 1
@@ -246,7 +288,11 @@ var blockStarts = []blockStartFunc{
 			heading := t.Context.addChild(ast.NodeHeading, t.Context.nextNonspace)
 			heading.HeadingLevel = level
 			heading.Tokens = content
+			if src := t.Context.recordSource(heading, 0, t.Context.nextNonspace); nil != src {
+				src.tokens = t.Context.currentLine[t.Context.nextNonspace:]
+			}
 			crosshatchMarker := &ast.Node{Type: ast.NodeHeadingC8hMarker, Tokens: markers}
+			crosshatchMarker.StartPos, crosshatchMarker.EndPos = heading.StartPos, t.Context.pos(t.Context.nextNonspace+len(markers))
 			heading.AppendChild(crosshatchMarker)
 			t.Context.advanceOffset(t.Context.currentLineLen-t.Context.offset, false)
 			return 2
@@ -300,6 +346,7 @@ var blockStarts = []blockStartFunc{
 				// 将该段落节点转成表节点
 				container.Type = ast.NodeTable
 				container.TableAligns = table.TableAligns
+				container.TableColumnWidths = table.TableColumnWidths
 				for tr := table.FirstChild; nil != tr; {
 					nextTr := tr.Next
 					container.AppendChild(tr)
@@ -312,17 +359,12 @@ var blockStarts = []blockStartFunc{
 
 		t.Context.closeUnmatchedBlocks()
 		// 解析链接引用定义
-		for tokens := container.Tokens; 0 < len(tokens) && lex.ItemOpenBracket == tokens[0]; tokens = container.Tokens {
-			if remains := t.Context.parseLinkRefDef(tokens); nil != remains {
-				container.Tokens = remains
-			} else {
-				break
-			}
-		}
+		t.Context.parseLinkRefDefs(container)
 
 		if 0 < len(container.Tokens) {
-			child := &ast.Node{Type: ast.NodeHeading, HeadingLevel: level, HeadingSetext: true}
+			child := &ast.Node{Type: ast.NodeHeading, HeadingLevel: level, HeadingSetext: true, StartPos: container.StartPos}
 			child.Tokens = lex.TrimWhitespace(container.Tokens)
+			t.Context.shareSource(container, child)
 			container.InsertAfter(child)
 			container.Unlink()
 			t.Context.Tip = child
@@ -352,14 +394,14 @@ var blockStarts = []blockStartFunc{
 		return 0
 	},
 
-	// 判断 YAML Front Matter（---）是否开始。
+	// 判断 Front Matter（--- +++ {）是否开始。
 	func(t *Tree, container *ast.Node) int {
-		if !t.Context.Option.YamlFrontMatter || t.Context.indented || nil != t.Root.FirstChild {
+		if t.Context.indented || nil != t.Root.FirstChild {
 			return 0
 		}
 
-		if t.parseYamlFrontMatter() {
-			node := &ast.Node{Type: ast.NodeYamlFrontMatter}
+		if typ := t.parseFrontMatter(); 0 <= typ {
+			node := &ast.Node{Type: ast.NodeYamlFrontMatter, FrontMatterType: typ, StartPos: t.Context.pos(t.Context.nextNonspace)}
 			t.Root.AppendChild(node)
 			t.Context.Tip = node
 			return 2
@@ -433,6 +475,46 @@ var blockStarts = []blockStartFunc{
 		return 0
 	},
 
+	// 判断提示块（:::tip 标题）是否开始。
+	func(t *Tree, container *ast.Node) int {
+		if !t.Context.Option.Admonition || t.Context.indented {
+			return 0
+		}
+
+		if ok, fenceLen, typ, title := t.parseAdmonition(); ok {
+			t.Context.closeUnmatchedBlocks()
+			admonition := t.Context.addChild(ast.NodeAdmonition, t.Context.nextNonspace)
+			admonition.AdmonitionFenceLen = fenceLen
+			admonition.AdmonitionType = typ
+			admonition.AdmonitionTitle = title
+			t.Context.advanceNextNonspace()
+			t.Context.advanceOffset(len(bytes.TrimRight(t.Context.currentLine[t.Context.offset:], "\n")), false)
+			return 1
+		}
+		return 0
+	},
+
+	// 判断定义描述（: 描述）是否开始。
+	func(t *Tree, container *ast.Node) int {
+		if !t.Context.Option.DefinitionList || t.Context.indented || !t.isDefinitionMarker() {
+			return 0
+		}
+
+		if ast.NodeParagraph != container.Type && ast.NodeDefinitionList != container.Type {
+			return 0
+		}
+
+		t.Context.closeUnmatchedBlocks()
+		if ast.NodeParagraph == container.Type && !t.Context.paragraph2DefinitionTerms(container) {
+			return 0
+		}
+
+		data := t.parseDefinitionMarker()
+		description := t.Context.addChild(ast.NodeDefinitionDescription, t.Context.nextNonspace)
+		description.ListData = data
+		return 1
+	},
+
 	// 判断缩进代码块（    code）是否开始。
 	func(t *Tree, container *ast.Node) int {
 		if !t.Context.indented {
@@ -529,6 +611,7 @@ var blockStarts = []blockStartFunc{
 		if nil == node {
 			return 0
 		}
+		node.StartPos = t.Context.pos(t.Context.nextNonspace)
 
 		t.Context.closeUnmatchedBlocks()
 
@@ -540,24 +623,18 @@ var blockStarts = []blockStartFunc{
 		return 2
 	},
 
-	// 判断内容块查询嵌入（!{{ SELECT * FROM blocks WHERE content LIKE '%待办%' }}）是否开始。
+	// 判断内容块查询嵌入（!{{ SELECT * FROM blocks WHERE content LIKE '%待办%' }}）是否开始，查询脚本可以跨越多行。
 	func(t *Tree, container *ast.Node) int {
 		if !t.Context.Option.BlockRef || t.Context.indented {
 			return 0
 		}
 
-		node := t.parseBlockQueryEmbed()
-		if nil == node {
+		if !t.isBlockQueryEmbedStart() || !t.isBlockQueryEmbedClosed() {
 			return 0
 		}
-
 		t.Context.closeUnmatchedBlocks()
-
-		for !t.Context.Tip.CanContain(ast.NodeBlockQueryEmbed) {
-			t.Context.finalize(t.Context.Tip, t.Context.lineNum-1) // 注意调用 finalize 会向父节点方向进行迭代
-		}
-		t.Context.Tip.AppendChild(node)
-		t.Context.Tip = node
+		t.Context.addChild(ast.NodeBlockQueryEmbed, t.Context.nextNonspace)
+		t.Context.advanceNextNonspace()
 		return 2
 	},
 }
@@ -569,9 +646,14 @@ func (t *Tree) addLine() {
 		t.Context.offset++ // skip over tab
 		// add space characters:
 		charsToTab := 4 - (t.Context.column % 4)
+		t.Context.recordSource(t.Context.Tip, len(t.Context.Tip.Tokens), t.Context.offset-1)
 		t.Context.Tip.AppendTokens(bytes.Repeat(util.StrToBytes(" "), charsToTab))
 	}
+	src := t.Context.recordSource(t.Context.Tip, len(t.Context.Tip.Tokens), t.Context.offset)
 	t.Context.Tip.AppendTokens(t.Context.currentLine[t.Context.offset:])
+	if nil != src {
+		src.tokens = t.Context.Tip.Tokens
+	}
 }
 
 // _continue 判断节点是否可以继续处理，比如块引用需要 >，缩进代码块需要 4 空格，围栏代码块需要 ```。
@@ -584,12 +666,16 @@ func _continue(n *ast.Node, context *Context) int {
 		return HtmlBlockContinue(n, context)
 	case ast.NodeParagraph:
 		return ParagraphContinue(n, context)
-	case ast.NodeListItem:
+	case ast.NodeListItem, ast.NodeDefinitionDescription:
 		return ListItemContinue(n, context)
 	case ast.NodeBlockquote:
 		return BlockquoteContinue(n, context)
 	case ast.NodeMathBlock:
 		return MathBlockContinue(n, context)
+	case ast.NodeBlockQueryEmbed:
+		return 0 // 未闭合的内容块查询嵌入接受后续所有行直到 }}
+	case ast.NodeAdmonition:
+		return AdmonitionContinue(n, context)
 	case ast.NodeYamlFrontMatter:
 		return YamlFrontMatterContinue(n, context)
 	case ast.NodeFootnotesDef:
@@ -597,5 +683,8 @@ func _continue(n *ast.Node, context *Context) int {
 	case ast.NodeHeading, ast.NodeThematicBreak, ast.NodeKramdownBlockIAL, ast.NodeBlockEmbed:
 		return 1
 	}
+	if 0 < len(context.Option.BlockSyntaxes) {
+		return blockSyntaxContinue(n, context)
+	}
 	return 0
 }
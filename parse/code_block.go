@@ -54,6 +54,9 @@ func CodeBlockContinue(codeBlock *ast.Node, context *Context) int {
 
 func (context *Context) codeBlockFinalize(codeBlock *ast.Node) {
 	if codeBlock.IsFencedCodeBlock {
+		if nil == codeBlock.CodeBlockCloseFence {
+			context.diagnose(codeBlock, SeverityError, RuleUnclosedCodeBlock, "fenced code block is not closed")
+		}
 		content := codeBlock.Tokens
 		length := len(content)
 		if 1 > length {
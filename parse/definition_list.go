@@ -0,0 +1,102 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"bytes"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/lex"
+)
+
+func (context *Context) definitionListFinalize(list *ast.Node) {
+	// 检查描述前或者描述内部是否包含空行，包含的话说明该定义列表是松散的。术语前的空行仅用于分隔，不影响紧凑模式
+	for item := list.FirstChild; nil != item; item = item.Next {
+		if endsWithBlankLine(item) && nil != item.Next && ast.NodeDefinitionDescription == item.Next.Type {
+			list.Tight = false
+			return
+		}
+
+		for subitem := item.FirstChild; nil != subitem; subitem = subitem.Next {
+			if endsWithBlankLine(subitem) && nil != subitem.Next {
+				list.Tight = false
+				return
+			}
+		}
+	}
+}
+
+// isDefinitionMarker 判断当前行是否以定义描述标记符 : 开头，标记符后面必须是空白并且描述内容不能为空。
+func (t *Tree) isDefinitionMarker() bool {
+	ln := t.Context.currentLine
+	if lex.ItemColon != lex.Peek(ln, t.Context.nextNonspace) {
+		return false
+	}
+	token := lex.Peek(ln, t.Context.nextNonspace+1)
+	return (lex.ItemSpace == token || lex.ItemTab == token) && !lex.IsBlankLine(ln[t.Context.nextNonspace+1:])
+}
+
+// parseDefinitionMarker 解析定义描述标记符 : 并计算内部缩进空格数，计算规则和列表项一致。
+func (t *Tree) parseDefinitionMarker() *ast.ListData {
+	data := &ast.ListData{Marker: []byte{lex.ItemColon}, MarkerOffset: t.Context.indent}
+
+	t.Context.advanceNextNonspace()
+	t.Context.advanceOffset(1, true)
+	spacesStartCol := t.Context.column
+	spacesStartOffset := t.Context.offset
+	for {
+		t.Context.advanceOffset(1, true)
+		token := lex.Peek(t.Context.currentLine, t.Context.offset)
+		if t.Context.column-spacesStartCol >= 5 || (lex.ItemSpace != token && lex.ItemTab != token) {
+			break
+		}
+	}
+
+	if spacesAfterMarker := t.Context.column - spacesStartCol; spacesAfterMarker >= 5 {
+		// 标记符后面的空白超过 4 个的话说明描述内容是缩进代码块
+		data.Padding = 2
+		t.Context.column = spacesStartCol
+		t.Context.offset = spacesStartOffset
+		t.Context.advanceOffset(1, true)
+	} else {
+		data.Padding = 1 + spacesAfterMarker
+	}
+	return data
+}
+
+// paragraph2DefinitionTerms 将段落 paragraph 的每一行转换为定义术语并挂到紧邻的前一个定义列表上，没有的话新建一个定义列表。
+// 段落内容全部是链接引用定义的话不进行转换并返回 false。
+func (context *Context) paragraph2DefinitionTerms(paragraph *ast.Node) bool {
+	// 解析链接引用定义
+	context.parseLinkRefDefs(paragraph)
+	if lex.IsBlankLine(paragraph.Tokens) {
+		return false
+	}
+
+	list := paragraph.Previous
+	if nil == list || ast.NodeDefinitionList != list.Type {
+		list = &ast.Node{Type: ast.NodeDefinitionList, ListData: &ast.ListData{Tight: true}, StartPos: paragraph.StartPos}
+		paragraph.InsertBefore(list)
+	}
+	list.Close = false // 空行后的术语需要继续挂到前一个定义列表上
+
+	for _, line := range bytes.Split(paragraph.Tokens, []byte{lex.ItemNewline}) {
+		if line = lex.TrimWhitespace(line); 1 > len(line) {
+			continue
+		}
+		term := &ast.Node{Type: ast.NodeDefinitionTerm, Tokens: line, Close: true}
+		context.shareSource(paragraph, term)
+		list.AppendChild(term)
+	}
+	paragraph.Unlink()
+	context.Tip = list
+	return true
+}
@@ -93,6 +93,10 @@ func (t *Tree) processEmphasis(stackBottom *delimiter, ctx *InlineContext) {
 
 	// move forward, looking for closers, and handling each
 	for nil != closer {
+		if t.interrupted() {
+			return
+		}
+
 		var closercc = closer.typ
 		if !closer.canClose {
 			closer = closer.next
@@ -232,6 +236,10 @@ func (t *Tree) processEmphasis(stackBottom *delimiter, ctx *InlineContext) {
 			emStrongDelMark.PrependChild(openMarker) // 插入起始标记符
 			emStrongDelMark.AppendChild(closeMarker) // 插入结束标记符
 			openerInl.InsertAfter(emStrongDelMark)
+			t.checkInlineDepth(emStrongDelMark)
+			if nil != t.Err {
+				return
+			}
 
 			// remove elts between opener and closer in delimiters stack
 			if opener.next != closer {
@@ -0,0 +1,151 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/88250/lute/ast"
+)
+
+// Severity 描述了诊断信息的严重程度。
+type Severity int
+
+const (
+	SeverityError   Severity = iota // 错误
+	SeverityWarning                 // 警告
+	SeverityInfo                    // 提示
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	}
+	return "Severity(" + strconv.Itoa(int(s)) + ")"
+}
+
+// 诊断规则代码。
+const (
+	RuleUnclosedCodeBlock  = "unclosed-code-block"  // 围栏代码块没有闭合
+	RuleUnclosedMathBlock  = "unclosed-math-block"  // 数学公式块 $$ 没有闭合
+	RuleUnclosedBlockRef   = "unclosed-block-ref"   // 块引用 ((id)) 没有闭合
+	RuleUndefinedLinkRef   = "undefined-link-ref"   // 链接引用 [foo] 没有对应的定义
+	RuleUndefinedFootnote  = "undefined-footnote"   // 脚注引用 [^foo] 没有对应的定义
+	RuleEmptyHeadingID     = "empty-heading-id"     // 标题自定义 ID {} 为空
+	RuleDuplicateHeadingID = "duplicate-heading-id" // 标题 ID 重复
+
+	RuleUnclosedBlockQueryEmbed = "unclosed-block-query-embed" // 内容块查询嵌入 !{{ 没有闭合
+	RuleInvalidBlockQueryEmbed  = "invalid-block-query-embed"  // 内容块查询嵌入的查询脚本为空或者引号、括号不配对
+	RuleInvalidYamlFrontMatter  = "invalid-yaml-front-matter"  // YAML Front Matter 语法错误
+)
+
+// Diagnostic 描述了一条解析诊断信息。
+type Diagnostic struct {
+	Severity Severity     // 严重程度
+	Code     string       // 规则代码
+	Message  string       // 诊断消息
+	StartPos ast.Position // 起始位置
+	EndPos   ast.Position // 结束位置（不包含）
+
+	node       *ast.Node // 关联的节点，用于在源码位置计算完成后确定诊断位置
+	start, end int       // 行级诊断对应的 Tokens 下标范围 [start, end)
+}
+
+// String 返回形如 "line:column: severity: message [code]" 的诊断描述。
+func (d *Diagnostic) String() string {
+	return strconv.Itoa(d.StartPos.Line) + ":" + strconv.Itoa(d.StartPos.Column) + ": " + d.Severity.String() + ": " + d.Message + " [" + d.Code + "]"
+}
+
+// diagnose 记录一条关联到块节点 node 的诊断信息，位置在解析完成后确定。
+func (context *Context) diagnose(node *ast.Node, severity Severity, code, message string) {
+	context.Tree.Diagnostics = append(context.Tree.Diagnostics, &Diagnostic{Severity: severity, Code: code, Message: message, node: node})
+}
+
+// diagnose 记录一条对应 Tokens 下标范围 [start, end) 的行级诊断信息，位置在行级节点源码位置计算完成后确定。
+func (ctx *InlineContext) diagnose(severity Severity, code, message string, start, end int) {
+	ctx.diagnostics = append(ctx.diagnostics, &Diagnostic{Severity: severity, Code: code, Message: message, start: start, end: end})
+}
+
+// inlineDiagnostics 计算块节点 block 行级诊断信息的位置并添加到树上，index 为 ctx.tokens 在来源 src 中的起始下标。
+func (t *Tree) inlineDiagnostics(block *ast.Node, ctx *InlineContext, src *source, index int) {
+	for _, d := range ctx.diagnostics {
+		if nil != src {
+			d.StartPos = t.Context.srcPos(src, index+d.start)
+			d.EndPos = t.Context.srcEndPos(src, index+d.start, index+d.end)
+		} else {
+			d.node = block
+		}
+		t.Diagnostics = append(t.Diagnostics, d)
+	}
+}
+
+// finalizeDiagnostics 检查重复的标题 ID，然后确定块级诊断信息的位置并按位置排序。
+func (t *Tree) finalizeDiagnostics() {
+	t.duplicateHeadingIDs()
+	t.resolveDiagnostics()
+	sort.SliceStable(t.Diagnostics, func(i, j int) bool {
+		return t.Diagnostics[i].StartPos.Offset < t.Diagnostics[j].StartPos.Offset
+	})
+}
+
+// resolveDiagnostics 使用关联节点的源码位置确定块级诊断信息的位置。
+func (t *Tree) resolveDiagnostics() {
+	for _, d := range t.Diagnostics {
+		if nil != d.node {
+			d.StartPos, d.EndPos = d.node.StartPos, d.node.EndPos
+			d.node = nil
+		}
+	}
+}
+
+// duplicateHeadingIDs 检查重复的标题 ID，自定义 ID 重复的话是错误，根据标题文本生成的 ID 重复的话是警告。
+//
+// 标题 ID 的生成规则和渲染时一致：自定义 ID 使用 UnicodeSlugger 规范化，其他标题使用 HeadingIDSlugger 根据标题文本生成。
+func (t *Tree) duplicateHeadingIDs() {
+	slugger := t.Context.Option.HeadingIDSlugger
+	if nil == slugger {
+		slugger = UnicodeSlugger
+	}
+	headings := map[string]*ast.Node{}
+	ast.Walk(t.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering || ast.NodeHeading != n.Type {
+			return ast.WalkContinue
+		}
+
+		severity := SeverityWarning
+		id := ""
+		if headingID := n.ChildByType(ast.NodeHeadingID); nil != headingID {
+			severity = SeverityError
+			id = UnicodeSlugger(HeadingIDText(string(headingID.Tokens)))
+		}
+		if "" == id {
+			severity = SeverityWarning
+			if id = slugger(HeadingIDText(n.Text())); "" != id && "" != n.HeadingNumber {
+				id = HeadingNumberID(n.HeadingNumber) + "-" + id
+			}
+		}
+		if "" == id {
+			return ast.WalkSkipChildren
+		}
+		if first := headings[id]; nil != first {
+			t.Context.diagnose(n, severity, RuleDuplicateHeadingID, "duplicate heading ID ["+id+"], first defined at line "+strconv.Itoa(first.StartPos.Line))
+		} else {
+			headings[id] = n
+		}
+		return ast.WalkSkipChildren
+	})
+}
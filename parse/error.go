@@ -0,0 +1,62 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/88250/lute/util"
+)
+
+// ParseError 描述了解析 markdown 时发生 panic 导致的错误。
+type ParseError struct {
+	Name  string      // 待解析的文档名称
+	Value interface{} // panic 值
+	Stack string      // 发生 panic 时的调用栈
+}
+
+func (e *ParseError) Error() string {
+	return "parse [" + e.Name + "] failed: " + util.PanicMessage(e.Value)
+}
+
+// ParseE 和 Parse 一样解析 markdown 生成语法树，但是解析时发生 panic 的话会恢复并返回 *ParseError，超出资源限制的话返回 *LimitError。
+func ParseE(name string, markdown []byte, options *Options) (tree *Tree, err error) {
+	return ParseContext(context.Background(), name, markdown, options)
+}
+
+// ParseContext 和 ParseE 一样解析 markdown 生成语法树，ctx 被取消或者超时的话中止解析并返回 ctx.Err()。
+//
+// 返回的语法树会关联 ctx，渲染时 ctx 被取消的话也会中止渲染。
+func ParseContext(ctx context.Context, name string, markdown []byte, options *Options) (tree *Tree, err error) {
+	defer func() {
+		if e := recover(); nil != e {
+			tree = nil
+			err = &ParseError{Name: name, Value: e, Stack: util.PanicStack()}
+		}
+	}()
+
+	tree = parse(ctx, name, markdown, options)
+	if err = tree.Err; nil != err {
+		tree = nil
+	}
+	return
+}
+
+// LimitError 描述了超出资源限制导致的错误。
+type LimitError struct {
+	Name  string // 超出的限制选项名称，比如 MaxBytes
+	Limit int    // 限制值
+}
+
+func (e *LimitError) Error() string {
+	return "exceeded limit [" + e.Name + "=" + strconv.Itoa(e.Limit) + "]"
+}
@@ -12,10 +12,38 @@ package parse
 
 import (
 	"bytes"
+	"strings"
+	"unicode"
+
 	"github.com/88250/lute/ast"
+	"github.com/88250/lute/lex"
 	"github.com/88250/lute/util"
 )
 
+// Slugger 用于根据标题文本 text 生成标题 ID，生成的 ID 重复的话会自动追加 -1、-2 等后缀。
+type Slugger func(text string) (slug string)
+
+// UnicodeSlugger 保留所有语言的字母和数字（区分大小写），其他字符都替换为 -，这是默认的标题 ID 生成策略。
+//
+// 解析时检查重复的标题 ID 也需要用到，所以放在 parse 包中，render.UnicodeSlugger 是同一个实现。
+func UnicodeSlugger(text string) (slug string) {
+	buf := &strings.Builder{}
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			buf.WriteRune(r)
+		} else {
+			buf.WriteByte('-')
+		}
+	}
+	return buf.String()
+}
+
+// HeadingIDText 去掉生成标题 ID 时不需要的开头的 # 和 Vditor 插入符。
+func HeadingIDText(text string) string {
+	text = strings.TrimLeft(text, "#")
+	return strings.ReplaceAll(text, util.Caret, "")
+}
+
 var openCurlyBrace = util.StrToBytes("{")
 var closeCurlyBrace = util.StrToBytes("}")
 
@@ -29,6 +57,9 @@ func (t *Tree) parseHeadingID(block *ast.Node, ctx *InlineContext) (ret *ast.Nod
 	content := ctx.tokens[startPos:]
 	curlyBracesEnd := bytes.Index(content, closeCurlyBrace)
 	if 2 > curlyBracesEnd {
+		if 1 == curlyBracesEnd && 1 > len(lex.TrimWhitespace(content[2:])) {
+			ctx.diagnose(SeverityWarning, RuleEmptyHeadingID, "heading ID {} is empty", startPos, startPos+2)
+		}
 		ctx.pos++
 		return &ast.Node{Type: ast.NodeText, Tokens: openCurlyBrace}
 	}
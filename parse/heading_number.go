@@ -0,0 +1,62 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/88250/lute/ast"
+)
+
+// numberHeadings 开启标题编号时为顶级标题（直接挂在根上的）计算编号，比如 1.、1.1、1.1.2。
+//
+// 标题按照大纲结构编号：标题是前面最近的级别更高的标题的下级，同级标题依次递增，所以跳级的标题也能得到连续的编号。
+func (t *Tree) numberHeadings() {
+	if !t.Context.Option.HeadingNumbering {
+		return
+	}
+
+	type parent struct {
+		level, children int
+		number          string
+	}
+	stack := []*parent{{}}
+	for n := t.Root.FirstChild; nil != n; n = n.Next {
+		if ast.NodeHeading != n.Type {
+			continue
+		}
+
+		for 1 < len(stack) && stack[len(stack)-1].level >= n.HeadingLevel {
+			stack = stack[:len(stack)-1]
+		}
+		p := stack[len(stack)-1]
+		p.children++
+		number := strconv.Itoa(p.children)
+		if "" != p.number {
+			number = p.number + "." + number
+		}
+		stack = append(stack, &parent{level: n.HeadingLevel, number: number})
+
+		if 2 == len(stack) {
+			number += "."
+		}
+		if number != n.HeadingNumber {
+			n.HeadingNumber = number
+			n.HeadingNormalizedID = "" // 编号是标题 ID 的一部分，需要重新生成
+		}
+	}
+}
+
+// HeadingNumberID 返回标题编号 number 用作标题 ID 前缀时的形式，比如 1.1.2 返回 1-1-2。
+func HeadingNumberID(number string) string {
+	return strings.ReplaceAll(strings.TrimSuffix(number, "."), ".", "-")
+}
@@ -23,9 +23,18 @@ import (
 
 // parseInline 解析并生成块节点 block 的行级子节点。
 func (t *Tree) parseInline(block *ast.Node, ctx *InlineContext) {
-	for ctx.pos < ctx.tokensLen {
+	for ctx.pos < ctx.tokensLen && !t.interrupted() {
 		token := ctx.tokens[ctx.pos]
+		start, last := ctx.pos, block.LastChild
 		var n *ast.Node
+		if t.isInlineSyntaxTrigger(token) {
+			if n = t.parseInlineSyntax(ctx); nil != n {
+				block.AppendChild(n)
+				ctx.setSpan(n, start, ctx.pos)
+				continue
+			}
+		}
+
 		switch token {
 		case lex.ItemBackslash:
 			n = t.parseBackslash(block, ctx)
@@ -61,6 +70,14 @@ func (t *Tree) parseInline(block *ast.Node, ctx *InlineContext) {
 
 		if nil != n {
 			block.AppendChild(n)
+			ctx.setSpan(n, start, ctx.pos)
+		} else if last != block.LastChild {
+			n = block.LastChild
+			ctx.setSpan(n, start, ctx.pos)
+		}
+		if nil != n && ast.NodeText != n.Type {
+			ctx.nodes++
+			t.checkNodes(ctx.nodes)
 		}
 	}
 	block.Tokens = nil
@@ -140,6 +157,7 @@ func (t *Tree) parseCloseBracket(ctx *InlineContext) *ast.Node {
 	}
 
 	isImage := opener.image
+	openerStart, _, _ := ctx.spanOf(opener.node)
 
 	// 检查是否满足链接或者图片规则
 
@@ -231,12 +249,14 @@ func (t *Tree) parseCloseBracket(ctx *InlineContext) *ast.Node {
 
 	var reflabel []byte
 	var linkType int
+	var fullRef bool
 	if !matched {
 		// 尝试解析链接 label
 		var beforelabel = ctx.pos
 		n, _, label := t.Context.parseLinkLabel(ctx.tokens[beforelabel:])
 		if 2 < n { // label 解析出来的话说明满足格式 [text][label]
 			reflabel = label
+			fullRef = true
 			ctx.pos += n
 		} else if !opener.bracketAfter {
 			// [text][] 格式，将 text 视为 label 进行解析
@@ -266,6 +286,7 @@ func (t *Tree) parseCloseBracket(ctx *InlineContext) *ast.Node {
 					}
 					ref := &ast.Node{Type: ast.NodeFootnotesRef, Tokens: bytes.ToLower(reflabel), FootnotesRefId: refId, FootnotesRefLabel: reflabel}
 					footnotesDef.FootnotesRefs = append(footnotesDef.FootnotesRefs, ref)
+					ctx.setSpan(ref, openerStart, ctx.pos)
 					return ref
 				}
 			}
@@ -313,7 +334,9 @@ func (t *Tree) parseCloseBracket(ctx *InlineContext) *ast.Node {
 			node.AppendChild(&ast.Node{Type: ast.NodeLinkTitle, Tokens: title})
 		}
 		node.AppendChild(&ast.Node{Type: ast.NodeCloseParen, Tokens: closeParen})
+		ctx.setSpan(node, openerStart, ctx.pos)
 		t.processEmphasis(opener.previousDelimiter, ctx)
+		t.checkInlineDepth(node)
 		t.removeBracket(ctx)
 		opener.node.Unlink()
 
@@ -332,12 +355,35 @@ func (t *Tree) parseCloseBracket(ctx *InlineContext) *ast.Node {
 
 		return node
 	} else { // 没有匹配到
+		if nil != reflabel && 0 < len(lex.TrimWhitespace(reflabel)) {
+			t.undefinedRef(ctx, reflabel, fullRef, openerStart, ctx.pos)
+		}
 		t.removeBracket(ctx)
 		ctx.pos = startPos
 		return &ast.Node{Type: ast.NodeText, Tokens: closeBracket}
 	}
 }
 
+// undefinedRef 记录引用标签 reflabel 没有对应定义的诊断信息，[start, end) 为引用在 Tokens 中的下标范围。
+//
+// 形如 [foo] 的简写引用也可能只是普通文本，所以只作为警告；形如 [text][foo] 的完整引用和脚注引用没有定义的话则是错误。
+func (t *Tree) undefinedRef(ctx *InlineContext, reflabel []byte, fullRef bool, start, end int) {
+	if t.Context.Option.Footnotes && lex.ItemCaret == reflabel[0] {
+		ctx.diagnose(SeverityError, RuleUndefinedFootnote, "footnote ["+string(reflabel)+"] is not defined", start, end)
+		return
+	}
+	if n := len(ctx.diagnostics); 0 < n && end == ctx.diagnostics[n-1].end {
+		// [text][foo] 匹配失败后 [foo] 会再作为简写引用匹配一次，不用重复诊断
+		return
+	}
+
+	severity := SeverityWarning
+	if fullRef {
+		severity = SeverityError
+	}
+	ctx.diagnose(severity, RuleUndefinedLinkRef, "link reference ["+string(reflabel)+"] is not defined", start, end)
+}
+
 func (t *Tree) parseOpenBracket(ctx *InlineContext) (ret *ast.Node) {
 	startPos := ctx.pos
 	ctx.pos++
@@ -139,6 +139,9 @@ func (context *Context) parseInlineLinkDest(tokens []byte) (passed, remains, des
 }
 
 func (context *Context) LinkPath(dest []byte) []byte {
+	if bytes.HasPrefix(dest, []byte("data:")) {
+		return dest // 内联的 data URI 不需要处理路径
+	}
 	dest = context.RelativePath(dest)
 	dest = context.PrefixPath(dest)
 	return dest
@@ -0,0 +1,83 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/util"
+)
+
+// InlineParseFunc 描述了用户自定义行级语法的解析函数签名。
+//
+// tokens 为当前块节点的行级内容，pos 为触发字节在 tokens 中的下标。匹配成功的话返回生成的节点以及语法结束位置（不包含）在 tokens 中的下标，
+// 匹配失败的话返回 nil，这时会继续尝试同一触发字节上注册的其他解析函数，最后交由内置解析处理。
+type InlineParseFunc func(tokens []byte, pos int) (ret *ast.Node, end int)
+
+// InlineSyntax 描述了用户自定义的行级语法，比如 @mention、!!spoiler!! 和 [[wikilink]]。
+type InlineSyntax struct {
+	Trigger  byte            // 触发字节，解析到该字节时调用 Parse
+	NodeType ast.NodeType    // 生成的节点类型，需要在各个渲染器中注册对应的渲染函数
+	Parse    InlineParseFunc // 解析函数
+	Nested   bool            // 是否将 Parse 返回节点的 Tokens 继续作为行级内容解析为子节点，比如 !!spoiler!! 中可以包含强调、链接等
+}
+
+// parseInlineSyntax 使用用户自定义的行级语法解析 ctx.pos 处的内容，没有匹配的话返回 nil。
+func (t *Tree) parseInlineSyntax(ctx *InlineContext) (ret *ast.Node) {
+	syntaxes := t.Context.Option.InlineSyntaxes[ctx.tokens[ctx.pos]]
+	for _, syntax := range syntaxes {
+		var end int
+		if ret, end = syntax.Parse(ctx.tokens, ctx.pos); nil == ret {
+			continue
+		}
+		if end <= ctx.pos || end > ctx.tokensLen {
+			// 解析函数至少需要消费一个字节，否则主循环无法推进
+			ret = nil
+			continue
+		}
+
+		ret.Type = syntax.NodeType
+		if syntax.Nested && 0 < len(ret.Tokens) {
+			t.parseNestedInline(ret, ctx)
+		}
+		ctx.pos = end
+		return
+	}
+	return nil
+}
+
+// parseNestedInline 将节点 node 的 Tokens 作为行级内容解析为 node 的子节点。
+func (t *Tree) parseNestedInline(node *ast.Node, ctx *InlineContext) {
+	tokens := node.Tokens
+	nested := &InlineContext{tokens: tokens, tokensLen: len(tokens)}
+	t.parseInline(node, nested)
+	t.processEmphasis(nil, nested)
+	t.mergeText(node)
+
+	// Tokens 是外层 Tokens 的一部分的话将子节点下标映射回外层，用于计算源码位置
+	if offset := util.BytesOffset(ctx.tokens, tokens); 0 <= offset {
+		for n, s := range nested.spans {
+			ctx.setSpan(n, offset+s[0], offset+s[1])
+		}
+		for _, d := range nested.diagnostics {
+			d.start, d.end = offset+d.start, offset+d.end
+			ctx.diagnostics = append(ctx.diagnostics, d)
+		}
+	}
+}
+
+// isInlineSyntaxTrigger 判断 token 是否是用户自定义行级语法的触发字节。
+func (t *Tree) isInlineSyntaxTrigger(token byte) bool {
+	if 1 > len(t.Context.Option.InlineSyntaxes) {
+		return false
+	}
+	_, ok := t.Context.Option.InlineSyntaxes[token]
+	return ok
+}
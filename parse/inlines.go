@@ -11,7 +11,10 @@
 package parse
 
 import (
+	"bytes"
+
 	"github.com/88250/lute/ast"
+	"github.com/88250/lute/lex"
 )
 
 // parseInlines 解析并生成行级节点。
@@ -21,12 +24,12 @@ func (t *Tree) parseInlines() {
 
 // walkParseInline 解析生成节点 node 的行级子节点。
 func (t *Tree) walkParseInline(node *ast.Node) {
-	if nil == node {
+	if nil == node || t.canceled() {
 		return
 	}
 
 	// 只有如下几种类型的块节点需要生成行级子节点
-	if typ := node.Type; ast.NodeParagraph == typ || ast.NodeHeading == typ || ast.NodeTableCell == typ {
+	if typ := node.Type; ast.NodeParagraph == typ || ast.NodeHeading == typ || ast.NodeTableCell == typ || ast.NodeDefinitionTerm == typ {
 		tokens := node.Tokens
 		if ast.NodeParagraph == typ && nil == tokens {
 			// 解析 GFM 表节点后段落内容 Tokens 可能会被置换为空，具体可参看函数 Paragraph.Finalize()
@@ -38,12 +41,22 @@ func (t *Tree) walkParseInline(node *ast.Node) {
 			return
 		}
 
+		if ast.NodeTableCell == typ && t.Context.Option.ExtendedTable && bytes.Contains(tokens, []byte{lex.ItemNewline}) {
+			// 扩展表格的多行单元格内容按块级元素解析
+			t.parseTableCellBlocks(node)
+			t.countNodes(node, true)
+			return
+		}
+
 		length := len(tokens)
 		if 1 > length {
+			t.countNodes(node, true)
 			return
 		}
 
+		src, index := t.Context.locate(node, tokens)
 		ctx := &InlineContext{tokens: tokens, tokensLen: length}
+		t.inlineContext = ctx
 
 		// 生成该块节点的行级子节点
 		t.parseInline(node, ctx)
@@ -66,6 +79,12 @@ func (t *Tree) walkParseInline(node *ast.Node) {
 		if t.Context.Option.Emoji {
 			t.emoji(node)
 		}
+
+		// 计算行级节点的源码位置
+		t.inlinePos(node, ctx, src, index)
+		t.inlineDiagnostics(node, ctx, src, index)
+		t.inlineContext = nil
+		t.countNodes(node, true)
 		return
 	} else if ast.NodeCodeBlock == typ {
 		if node.IsFencedCodeBlock {
@@ -81,6 +100,7 @@ func (t *Tree) walkParseInline(node *ast.Node) {
 			}
 			closeMarker := &ast.Node{Type: ast.NodeCodeBlockFenceCloseMarker, Tokens: node.CodeBlockCloseFence, CodeBlockFenceLen: node.CodeBlockFenceLen}
 			node.AppendChild(closeMarker)
+			t.codeBlockPos(node, openMarker, info, code, closeMarker)
 		} else {
 			// 细化缩进代码块子节点
 			code := &ast.Node{Type: ast.NodeCodeBlockCode, Tokens: node.Tokens}
@@ -89,7 +109,8 @@ func (t *Tree) walkParseInline(node *ast.Node) {
 		node.Tokens = nil
 	}
 
-	// 遍历处理子节点
+	// 子节点在遍历时计入节点数
+	t.countNodes(node, false)
 	for child := node.FirstChild; nil != child; child = child.Next {
 		t.walkParseInline(child)
 	}
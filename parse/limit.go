@@ -0,0 +1,141 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"unicode/utf8"
+
+	"github.com/88250/lute/ast"
+)
+
+// canceled 判断解析是否已经被取消，被取消的话将 ctx.Err() 记录到 t.Err。
+func (t *Tree) canceled() bool {
+	if nil != t.Err {
+		return true
+	}
+	if nil == t.Context.Ctx {
+		return false
+	}
+	if err := t.Context.Ctx.Err(); nil != err {
+		t.Err = err
+		return true
+	}
+	return false
+}
+
+// interruptInterval 解析循环中每迭代多少次检查一次是否被取消，ctx.Err() 需要加锁，不适合每次迭代都调用。
+const interruptInterval = 256
+
+// interrupted 用于在解析的内层循环中判断是否需要中止：已经出错的话立即返回 true，是否被取消则每 interruptInterval 次调用检查一次。
+func (t *Tree) interrupted() bool {
+	if nil != t.Err {
+		return true
+	}
+	if t.Context.ticks++; 0 != t.Context.ticks%interruptInterval {
+		return false
+	}
+	return t.canceled()
+}
+
+// exceed 记录超出资源限制 name（限制值为 limit）的错误，解析会尽快中止并将语法树降级。
+func (t *Tree) exceed(name string, limit int) {
+	if nil == t.Err {
+		t.Err = &LimitError{Name: name, Limit: limit}
+	}
+}
+
+// checkNestingDepth 检查新起始的块级容器 container 的嵌套深度是否超出最大嵌套深度。
+func (t *Tree) checkNestingDepth(container *ast.Node) {
+	if max := t.Context.Option.MaxNestingDepth; 0 < max && max < nestingDepth(container, max+1) {
+		t.exceed("MaxNestingDepth", max)
+	}
+}
+
+// checkInlineDepth 检查新生成的强调、链接等行级节点 node 的嵌套深度是否超出最大嵌套深度。
+// 只有匹配成功生成了节点的标记符才计入嵌套深度，深度为外层行级节点数加上 node 自身的嵌套层数。
+func (t *Tree) checkInlineDepth(node *ast.Node) {
+	max := t.Context.Option.MaxNestingDepth
+	if 1 > max {
+		return
+	}
+
+	depth := inlineHeight(node)
+	for p := node.Parent; nil != p && !p.IsBlock() && ast.NodeTableCell != p.Type; p = p.Parent {
+		depth++
+	}
+	if max < depth {
+		t.exceed("MaxNestingDepth", max)
+	}
+}
+
+// inlineHeight 返回行级节点 node 的嵌套层数，没有子节点的节点为 0。
+func inlineHeight(node *ast.Node) (ret int) {
+	for child := node.FirstChild; nil != child; child = child.Next {
+		if height := inlineHeight(child) + 1; ret < height {
+			ret = height
+		}
+	}
+	return
+}
+
+// nestingDepth 返回块节点 node 的嵌套深度，文档节点的深度为 0。最多向上查找 limit 层，避免深层嵌套时每次起始容器都遍历到根节点。
+func nestingDepth(node *ast.Node, limit int) (ret int) {
+	for p := node; nil != p && ast.NodeDocument != p.Type && ret < limit; p = p.Parent {
+		ret++
+	}
+	return
+}
+
+// countNodes 将节点 node（descendants 为 true 时包括其所有子节点）计入已生成的节点数，节点数和链接引用定义数之和超出最大节点数的话中止解析。
+func (t *Tree) countNodes(node *ast.Node, descendants bool) {
+	max := t.Context.Option.MaxNodes
+	if 1 > max {
+		return
+	}
+
+	if !descendants {
+		t.Context.nodes++
+	} else {
+		ast.Walk(node, func(n *ast.Node, entering bool) ast.WalkStatus {
+			if entering {
+				t.Context.nodes++
+			}
+			return ast.WalkContinue
+		})
+	}
+	t.checkNodes(0)
+}
+
+// checkNodes 检查已生成的节点数加上 pending 个还没有计入的节点以及链接引用定义数之和是否超出最大节点数。
+// 行级解析时 pending 为当前块中已经生成的非文本节点数，文本节点合并后数量会减少，所以不计入，块解析完成后再准确计数。
+func (t *Tree) checkNodes(pending int) {
+	if max := t.Context.Option.MaxNodes; 0 < max && max < t.Context.nodes+pending+len(t.Context.LinkRefDefs) {
+		t.exceed("MaxNodes", max)
+	}
+}
+
+// degrade 将语法树降级为只包含一个文本段落的文档，渲染时文本会被转义输出，用于超出资源限制的情况。
+func (t *Tree) degrade(err error) {
+	tokens := t.Source
+	if max := t.Context.Option.MaxBytes; 0 < max && max < len(tokens) {
+		for ; 0 < max && !utf8.RuneStart(tokens[max]); max-- { // 避免截断多字节字符
+		}
+		tokens = tokens[:max]
+	}
+
+	t.Root = &ast.Node{Type: ast.NodeDocument}
+	paragraph := &ast.Node{Type: ast.NodeParagraph}
+	paragraph.AppendChild(&ast.Node{Type: ast.NodeText, Tokens: tokens})
+	t.Root.AppendChild(paragraph)
+	t.Context.LinkRefDefs = map[string]*ast.Node{}
+	t.Context.FootnotesDefs = nil
+	t.Err = err
+}
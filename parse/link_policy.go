@@ -0,0 +1,121 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/util"
+)
+
+// Link 描述了渲染链接或者图片时使用的地址和属性，可以在 LinkCallback 中修改。
+type Link struct {
+	Node     *ast.Node  // 链接或者图片节点
+	Image    bool       // 是否是图片
+	Dest     string     // 地址，已经处理过 LinkBase、LinkPrefix、协议白名单和外链跳转，协议不被允许时为空
+	External bool       // 是否是外链
+	Attrs    [][]string // 附加的属性，比如外链的 target 和 rel
+}
+
+// LinkCallback 用于在渲染链接或者图片前检查、修改 link。
+type LinkCallback func(link *Link)
+
+// externalLinkRel 是外链使用的 rel 属性值。
+const externalLinkRel = "noopener noreferrer nofollow"
+
+// ResolveLink 根据链接选项解析链接或者图片节点 node 渲染时使用的地址和属性。
+//
+// 地址先按照 LinkBase 和 LinkPrefix 处理，协议不在 LinkSchemes 中的地址会被置空（渲染为空地址），渲染完整文档时内联本地图片生成的 data URI 除外。外链（http、https 或者 // 开头并且域名不在 InternalLinkHosts 中）
+// 在启用 ExternalLinkTarget 时添加 target="_blank" 和 rel="noopener noreferrer nofollow"，设置了 ExternalLinkRedirect 时通过跳转地址访问。
+// 最后调用 LinkCallback 对结果进行修改。
+func (context *Context) ResolveLink(node *ast.Node) (ret *Link) {
+	ret = &Link{Node: node, Image: ast.NodeImage == node.Type}
+	if dest := node.ChildByType(ast.NodeLinkDest); nil != dest {
+		ret.Dest = util.BytesToStr(context.LinkPath(dest.Tokens))
+	}
+
+	scheme := linkScheme(ret.Dest)
+	if "" != scheme && 0 < len(context.Option.LinkSchemes) && !containsFold(context.Option.LinkSchemes, scheme) && !node.LinkInlined {
+		ret.Dest = ""
+	}
+	if "" != ret.Dest {
+		ret.External = context.isExternalLink(ret.Dest, scheme)
+	}
+
+	if ret.External && !ret.Image {
+		if "" != context.Option.ExternalLinkRedirect {
+			ret.Dest = context.Option.ExternalLinkRedirect + url.QueryEscape(ret.Dest)
+		}
+		if context.Option.ExternalLinkTarget {
+			ret.Attrs = append(ret.Attrs, []string{"target", "_blank"}, []string{"rel", externalLinkRel})
+		}
+	}
+
+	if nil != context.Option.LinkCallback {
+		context.Option.LinkCallback(ret)
+	}
+	return
+}
+
+// isExternalLink 判断协议为 scheme 的地址 dest 是否是外链。
+func (context *Context) isExternalLink(dest, scheme string) bool {
+	if "" == scheme && !strings.HasPrefix(dest, "//") {
+		return false
+	}
+	if "" != scheme && "http" != scheme && "https" != scheme {
+		return false
+	}
+
+	u, err := url.Parse(dest)
+	if nil != err {
+		return true
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, internal := range context.Option.InternalLinkHosts {
+		internal = strings.ToLower(internal)
+		if host == internal || strings.HasSuffix(host, "."+internal) {
+			return false
+		}
+	}
+	return true
+}
+
+// linkScheme 返回地址 dest 的协议（小写），相对地址返回空字符串。和浏览器一样忽略地址中的空白和控制字符。
+func linkScheme(dest string) string {
+	dest = strings.Map(func(r rune) rune {
+		if ' ' >= r || 0x7f == r {
+			return -1
+		}
+		return r
+	}, dest)
+	colon := strings.IndexByte(dest, ':')
+	if 1 > colon {
+		return ""
+	}
+	for i, c := range dest[:colon] {
+		if ('a' <= c && 'z' >= c) || ('A' <= c && 'Z' >= c) || (0 < i && (('0' <= c && '9' >= c) || '+' == c || '-' == c || '.' == c)) {
+			continue
+		}
+		return "" // 冒号前出现 /、?、# 等字符时是相对地址
+	}
+	return strings.ToLower(dest[:colon])
+}
+
+func containsFold(strs []string, str string) bool {
+	for _, s := range strs {
+		if strings.EqualFold(s, str) {
+			return true
+		}
+	}
+	return false
+}
@@ -19,55 +19,90 @@ import (
 	"unicode/utf8"
 )
 
-func (context *Context) parseLinkRefDef(tokens []byte) []byte {
+// parseLinkRefDefs 尝试解析块节点 block 开头的链接引用定义，解析成功的定义会从 block.Tokens 中移除，返回值表示是否解析到了定义。
+// 开启 KeepLinkRefDefs 的话会在 block 前插入一个链接引用定义块节点，按原文顺序保留所有定义（包括重复 label 的定义）。
+func (context *Context) parseLinkRefDefs(block *ast.Node) (ok bool) {
+	var defs *ast.Node
+	for tokens := block.Tokens; 0 < len(tokens) && lex.ItemOpenBracket == tokens[0]; tokens = block.Tokens {
+		if t := context.Tree; nil != t {
+			if t.checkNodes(0); t.interrupted() {
+				break
+			}
+		}
+
+		remains, link := context.parseLinkRefDef(block, tokens)
+		if nil == remains {
+			break
+		}
+		block.Tokens = remains
+		ok = true
+
+		if !context.Option.KeepLinkRefDefs {
+			continue
+		}
+		if nil == defs {
+			defs = &ast.Node{Type: ast.NodeLinkRefDefBlock, Close: true, StartPos: link.StartPos}
+			block.InsertBefore(defs)
+		}
+		def := &ast.Node{Type: ast.NodeLinkRefDef, StartPos: link.StartPos, EndPos: link.EndPos}
+		def.AppendChild(link)
+		defs.AppendChild(def)
+		defs.EndPos = link.EndPos
+	}
+	return
+}
+
+// parseLinkRefDef 解析 tokens 开头的一个链接引用定义，返回剩余的 tokens 以及定义对应的链接节点，解析失败的话返回 nil。
+func (context *Context) parseLinkRefDef(block *ast.Node, tokens []byte) (remains []byte, link *ast.Node) {
 	_, tokens = lex.TrimLeft(tokens)
 	if 1 > len(tokens) {
-		return nil
+		return nil, nil
 	}
+	start := tokens
 
 	n, remains, label := context.parseLinkLabel(tokens)
 	if 2 > n || 1 > len(label) {
-		return nil
+		return nil, nil
 	}
 
 	length := len(remains)
 	if 1 > length {
-		return nil
+		return nil, nil
 	}
 
 	if ':' != remains[0] {
-		return nil
+		return nil, nil
 	}
 
 	remains = remains[1:]
 	whitespaces, remains := lex.TrimLeft(remains)
 	newlines, _, _ := lex.StatWhitespace(whitespaces)
 	if 1 < newlines {
-		return nil
+		return nil, nil
 	}
 
 	tokens = remains
 	linkDest, remains, destination := context.parseLinkDest(tokens)
 	if nil == linkDest {
-		return nil
+		return nil, nil
 	}
 
 	whitespaces, remains = lex.TrimLeft(remains)
 	if nil == whitespaces && 0 < len(remains) {
-		return nil
+		return nil, nil
 	}
 	newlines, spaces1, tabs1 := lex.StatWhitespace(whitespaces)
 	if 1 < newlines {
-		return nil
+		return nil, nil
 	}
 
 	_, tokens = lex.TrimLeft(remains)
 	validTitle, _, remains, title := context.parseLinkTitle(tokens)
 	if !validTitle && 1 > newlines {
-		return nil
+		return nil, nil
 	}
 	if 0 < spaces1+tabs1 && !lex.IsBlankLine(remains) && lex.ItemNewline != remains[0] {
-		return nil
+		return nil, nil
 	}
 
 	titleLine := tokens
@@ -79,12 +114,19 @@ func (context *Context) parseLinkRefDef(tokens []byte) []byte {
 		remains = tokens
 	}
 
-	link := context.Tree.newLink(ast.NodeLink, label, destination, title, 1)
+	link = context.Tree.newLink(ast.NodeLink, label, destination, title, 1)
+	_, def := lex.TrimRight(start[:len(start)-len(remains)])
+	context.setTokensPos(block, link, start, len(def))
 	lowerCaseLabel := bytes.ToLower(label)
 	if _, ok := context.LinkRefDefs[util.BytesToStr(lowerCaseLabel)]; !ok {
 		context.LinkRefDefs[util.BytesToStr(lowerCaseLabel)] = link
 	}
-	return remains
+	if context.Option.KeepLinkRefDefs {
+		// LinkRefDefs 中的链接节点仅用于解析链接引用，挂到语法树上的定义使用单独的节点
+		link = context.Tree.newLink(ast.NodeLink, label, destination, title, 1)
+		context.setTokensPos(block, link, start, len(def))
+	}
+	return
 }
 
 func (context *Context) parseLinkTitle(tokens []byte) (validTitle bool, passed, remains, title []byte) {
@@ -57,6 +57,8 @@ func (context *Context) mathBlockFinalize(mathBlock *ast.Node) {
 	}
 	if bytes.HasSuffix(tokens, MathBlockMarker) {
 		tokens = tokens[:len(tokens)-2] // 剔除结尾的 $$
+	} else {
+		context.diagnose(mathBlock, SeverityError, RuleUnclosedMathBlock, "math block is not closed with $$")
 	}
 	mathBlock.Tokens = nil
 	mathBlock.AppendChild(&ast.Node{Type: ast.NodeMathBlockOpenMarker})
@@ -29,18 +29,13 @@ func paragraphFinalize(p *ast.Node, context *Context) (insertTable bool) {
 	p.Tokens = lex.TrimWhitespace(p.Tokens)
 
 	// 尝试解析链接引用定义
-	hasReferenceDefs := false
-	for tokens := p.Tokens; 0 < len(tokens) && lex.ItemOpenBracket == tokens[0]; tokens = p.Tokens {
-		if tokens = context.parseLinkRefDef(tokens); nil != tokens {
-			p.Tokens = tokens
-			hasReferenceDefs = true
-			continue
-		}
-		break
-	}
-	if hasReferenceDefs && lex.IsBlankLine(p.Tokens) {
+	if context.parseLinkRefDefs(p) && lex.IsBlankLine(p.Tokens) {
 		p.Unlink()
 	}
+	if nil != context.Tree && nil != context.Tree.Err {
+		// 超出资源限制或者被取消的话剩余的内容不再解析，语法树随后会被降级
+		return
+	}
 
 	if context.Option.GFMTaskListItem {
 		// 尝试解析任务列表项
@@ -95,6 +90,7 @@ func paragraphFinalize(p *ast.Node, context *Context) (insertTable bool) {
 			if nil != paragraph {
 				p.Tokens = paragraph.Tokens
 				p.InsertAfter(table)
+				context.tablePos(p, table)
 				// 设置末梢及其状态
 				table.Close = true
 				context.Tip = table
@@ -103,6 +99,8 @@ func paragraphFinalize(p *ast.Node, context *Context) (insertTable bool) {
 				// 将该段落节点转成表节点
 				p.Type = ast.NodeTable
 				p.TableAligns = table.TableAligns
+				p.TableColumnWidths = table.TableColumnWidths
+				p.TableCaption = table.TableCaption
 				for tr := table.FirstChild; nil != tr; {
 					nextTr := tr.Next
 					p.AppendChild(tr)
@@ -11,6 +11,8 @@
 package parse
 
 import (
+	"context"
+
 	"github.com/88250/lute/ast"
 	"github.com/88250/lute/lex"
 )
@@ -19,13 +21,38 @@ import (
 const Zwsp = "\u200b"
 
 // Parse 会将 markdown 原始文本字节数组解析为一颗语法树。
+//
+// 超出 options 中设置的资源限制（MaxBytes、MaxNestingDepth、MaxNodes）的话解析会中止，语法树降级为只包含一个文本段落的文档，此时 tree.Err 为 *LimitError。
 func Parse(name string, markdown []byte, options *Options) (tree *Tree) {
-	tree = &Tree{Name: name, Context: &Context{Option: options}}
+	return parse(nil, name, markdown, options)
+}
+
+// parse 解析 markdown 生成语法树，ctx 不为 nil 的话解析过程中被取消会中止解析并将 ctx.Err() 记录到 tree.Err。
+func parse(ctx context.Context, name string, markdown []byte, options *Options) (tree *Tree) {
+	tree = &Tree{Name: name, Context: &Context{Option: options, Ctx: ctx}, Source: markdown}
 	tree.Context.Tree = tree
-	tree.lexer = lex.NewLexer(markdown)
 	tree.Root = &ast.Node{Type: ast.NodeDocument}
+	if max := options.MaxBytes; 0 < max && max < len(markdown) {
+		tree.degrade(&LimitError{Name: "MaxBytes", Limit: max})
+		return
+	}
+
+	tree.lexer = lex.NewLexer(markdown)
 	tree.parseBlocks()
 	tree.parseInlines()
+	if nil != tree.Err {
+		if limitErr, ok := tree.Err.(*LimitError); ok {
+			tree.degrade(limitErr)
+		}
+		tree.lexer = nil
+		return
+	}
+
+	tree.fillPos(tree.Root)
+	inheritPos(tree.Root)
+	tree.decodeFrontMatter()
+	tree.numberHeadings()
+	tree.finalizeDiagnostics()
 	tree.lexer = nil
 	return
 }
@@ -43,8 +70,9 @@ func Inline(name string, markdown []byte, options *Options) (tree *Tree) {
 
 // Context 用于维护块级元素解析过程中使用到的公共数据。
 type Context struct {
-	Tree   *Tree    // 关联的语法树
-	Option *Options // 解析渲染选项
+	Tree   *Tree           // 关联的语法树
+	Option *Options        // 解析渲染选项
+	Ctx    context.Context // 用于取消解析和渲染，为 nil 的话不会被取消
 
 	LinkRefDefs   map[string]*ast.Node // 链接引用定义集
 	FootnotesDefs []*ast.Node          // 脚注定义集
@@ -56,6 +84,12 @@ type Context struct {
 	lineNum, offset, column, nextNonspace, nextNonspaceColumn, indent int       // 解析时用到的行号、下标、缩进空格数等
 	indented, blank, partiallyConsumedTab, allClosed                  bool      // 是否是缩进行、空行等标识
 	lastMatchedContainer                                              *ast.Node // 最后一个匹配的块节点
+
+	sources map[*ast.Node]*source // 块节点内容来源，用于计算源码位置
+	nodes   int                   // 已生成的节点数，用于在解析过程中检查最大节点数
+	ticks   int                   // 解析循环的迭代次数，用于定期检查是否被取消
+
+	noBlockQueryEmbedClose bool // 剩余的输入中已经没有内容块查询嵌入的闭合标记符 }}
 }
 
 // InlineContext 描述了行级元素解析上下文。
@@ -67,6 +101,10 @@ type InlineContext struct {
 	columnNum  int        // 当前解析的起始列号
 	delimiters *delimiter // 分隔符栈，用于强调解析
 	brackets   *delimiter // 括号栈，用于图片和链接解析
+
+	spans       map[*ast.Node][2]int // 行级节点对应的 Tokens 下标范围，用于计算源码位置
+	diagnostics []*Diagnostic        // 行级诊断信息，位置在计算完源码位置后确定
+	nodes       int                  // 已生成的非文本行级节点数，用于在行级解析过程中检查最大节点数
 }
 
 // advanceOffset 用于移动 count 个字符位置，columns 指定了遇到 tab 时是否需要空格进行补偿偏移。
@@ -152,6 +190,7 @@ func (context *Context) closeUnmatchedBlocks() {
 func (context *Context) finalize(block *ast.Node, lineNum int) {
 	parent := block.Parent
 	block.Close = true
+	context.finalizePos(block, lineNum)
 
 	// 节点最终化处理。比如围栏代码块提取 info 部分；HTML 代码块剔除结尾空格；段落需要解析链接引用定义等。
 	switch block.Type {
@@ -166,10 +205,18 @@ func (context *Context) finalize(block *ast.Node, lineNum int) {
 		}
 	case ast.NodeMathBlock:
 		context.mathBlockFinalize(block)
+	case ast.NodeBlockQueryEmbed:
+		context.blockQueryEmbedFinalize(block)
 	case ast.NodeYamlFrontMatter:
 		context.yamlFrontMatterFinalize(block)
 	case ast.NodeList:
 		context.listFinalize(block)
+	case ast.NodeDefinitionList:
+		context.definitionListFinalize(block)
+	default:
+		if syntax := context.blockSyntax(block.Type); nil != syntax {
+			syntax.Finalize(block)
+		}
 	}
 
 	context.Tip = parent
@@ -189,7 +236,7 @@ func (context *Context) addChild(nodeType ast.NodeType, offset int) (ret *ast.No
 		context.finalize(context.Tip, context.lineNum-1) // 注意调用 finalize 会向父节点方向进行迭代
 	}
 
-	ret = &ast.Node{Type: nodeType}
+	ret = &ast.Node{Type: nodeType, StartPos: context.pos(offset)}
 	context.Tip.AppendChild(ret)
 	context.Tip = ret
 	return
@@ -204,10 +251,14 @@ func (context *Context) listsMatch(listData, itemData *ast.ListData) bool {
 
 // Tree 描述了 Markdown 抽象语法树结构。
 type Tree struct {
-	Root          *ast.Node      // 根节点
-	Context       *Context       // 块级解析上下文
-	lexer         *lex.Lexer     // 词法分析器
-	inlineContext *InlineContext // 行级解析上下文
+	Root          *ast.Node              // 根节点
+	Context       *Context               // 块级解析上下文
+	lexer         *lex.Lexer             // 词法分析器
+	inlineContext *InlineContext         // 行级解析上下文
+	Source        []byte                 // 解析时使用的 Markdown 原始文本，增量解析时需要用到
+	Diagnostics   []*Diagnostic          // 解析诊断信息，按源码位置排序
+	FrontMatter   map[string]interface{} // 解码后的 YAML Front Matter，没有 Front Matter 或者解码失败时为 nil
+	Err           error                  // 超出资源限制导致语法树降级或者被取消导致解析中止时的错误
 
 	Name    string   // 名称，可以为空
 	ID      string   // ID，可以为空
@@ -247,8 +298,16 @@ type Options struct {
 	Footnotes bool
 	// ToC 设置是否打开“目录”支持。
 	ToC bool
+	// ToCDepth 设置目录的最大层级，为 0 的话不限制。层级按照大纲结构计算，顶级标题为第 1 层。
+	ToCDepth int
+	// ToCList 设置目录渲染使用的列表标签，可以是 ul 或者 ol，为空的话使用 span 渲染。
+	ToCList string
+	// HeadingNumbering 设置是否自动为标题编号（1.、1.1、1.1.2），编号会用于 HTML 标题、目录和标题 ID。
+	HeadingNumbering bool
 	// HeadingID 设置是否打开“自定义标题 ID”支持。
 	HeadingID bool
+	// HeadingIDSlugger 设置根据标题文本生成标题 ID 的策略，为 nil 时保留所有语言的字母和数字，其他字符替换为 -。
+	HeadingIDSlugger Slugger
 	// AutoSpace 设置是否对普通文本中的中西文间自动插入空格。
 	// https://github.com/sparanoid/chinese-copywriting-guidelines
 	AutoSpace bool
@@ -285,6 +344,17 @@ type Options struct {
 	// 比如 LinkPrefix 设置为 http://domain.com，对于使用绝对路径的 ![foo](/local/path/bar.png) 则渲染为 <img src="http://domain.com/local/path/bar.png" alt="foo" />；
 	// 在 LinkBase 和 LinkPrefix 同时设置的情况下，会先处理 LinkBase 逻辑，最后再在 LinkBase 处理结果上加上 LinkPrefix。
 	LinkPrefix string
+	// LinkSchemes 设置链接、图片地址允许使用的协议，比如 http、https 和 mailto，为空时不限制。协议不在其中的地址会被渲染为空地址，相对地址总是允许。
+	// 渲染完整文档时内联本地图片生成的 data URI 不受限制，不需要将 data 加入其中（加入的话会同时允许 Markdown 中直接书写的 data URI）。
+	LinkSchemes []string
+	// ExternalLinkTarget 设置是否为外链添加 target="_blank" 和 rel="noopener noreferrer nofollow"。
+	ExternalLinkTarget bool
+	// InternalLinkHosts 设置站内链接的域名（包括子域名），其他域名下的 http、https 链接都是外链。
+	InternalLinkHosts []string
+	// ExternalLinkRedirect 设置外链的跳转地址，比如 /goto?url=，外链地址会经过 URL 编码后拼接在该值后面。
+	ExternalLinkRedirect string
+	// LinkCallback 设置渲染链接、图片前的回调，可以用于检查、修改地址和属性。
+	LinkCallback LinkCallback
 	// VditorCodeBlockPreview 设置 Vditor 代码块是否需要渲染预览部分
 	VditorCodeBlockPreview bool
 	// VditorMathBlockPreview 设置 Vditor 数学公式块是否需要渲染预览部分
@@ -295,6 +365,8 @@ type Options struct {
 	Setext bool
 	// Sanitize 设置是否启用 XSS 安全过滤 https://github.com/88250/lute/issues/51
 	Sanitize bool
+	// Sanitizer 设置启用安全过滤时使用的过滤策略，为 nil 时仅过滤脚本等不安全的元素和事件属性。
+	Sanitizer Sanitizer
 	// ImageLazyLoading 设置图片懒加载时使用的图片路径，配置该字段后将启用图片懒加载。
 	// 图片 src 的值会复制给新属性 data-src，然后使用该参数值作为 src 的值 https://github.com/88250/lute/issues/55
 	ImageLazyLoading string
@@ -302,14 +374,45 @@ type Options struct {
 	ChineseParagraphBeginningSpace bool
 	// YamlFrontMatter 设置是否开启 YAML Front Matter 支持。
 	YamlFrontMatter bool
+	// TomlFrontMatter 设置是否开启 TOML Front Matter（+++）支持，默认关闭。+++ 之间的内容是合法的 TOML 时才会作为 Front Matter。
+	TomlFrontMatter bool
+	// JSONFrontMatter 设置是否开启 JSON Front Matter（{ }）支持，默认关闭。{ } 包裹的内容是合法的 JSON 时才会作为 Front Matter。
+	JSONFrontMatter bool
 	// BlockRef 设置是否开启内容块引用支持。
 	BlockRef bool
+	// BlockQueryEmbedResolver 设置内容块查询嵌入的查询结果解析器，为 nil 时仅渲染查询占位。
+	BlockQueryEmbedResolver BlockQueryEmbedResolver
 	// Mark 设置是否打开 ==标记== 支持。
 	Mark bool
 	// KramdownIAL 设置是否打开 kramdown 内联属性列表支持。 https://kramdown.gettalong.org/syntax.html#inline-attribute-lists
 	KramdownIAL bool
 	// Tag 设置是否开启 #标签# 支持。
 	Tag bool
+	// Admonition 设置是否开启提示块 :::tip 和 > [!NOTE] 支持。
+	Admonition bool
+	// DefinitionList 设置是否开启 PHP Markdown Extra 风格的定义列表支持。
+	DefinitionList bool
+	// ExtendedTable 设置是否开启扩展表格支持，包括 || 跨列、^^ 跨行、行尾 \ 多行单元格以及表格下一行 [标题]。
+	ExtendedTable bool
+	// KeepLinkRefDefs 设置是否将链接引用定义作为节点保留在语法树中原来的位置上，格式化时原位输出。
+	KeepLinkRefDefs bool
+	// InlineSyntaxes 用户自定义的行级语法，按触发字节索引，同一触发字节上的语法按注册顺序尝试。
+	InlineSyntaxes map[byte][]*InlineSyntax
+	// BlockSyntaxes 用户自定义的块级语法，按注册顺序尝试。每个引擎持有各自的列表，生成的节点通过 ast.Node.BlockExt 判断结构特性。
+	BlockSyntaxes []BlockSyntax
+	// MaxBytes 设置允许解析的最大输入字节数，0 表示不限制。超出的话语法树降级为只包含一个文本段落（截断到该长度）的文档。
+	MaxBytes int
+	// MaxNestingDepth 设置块级容器（块引用、列表等）以及强调、链接的最大嵌套深度，0 表示不限制。只计算匹配成功的标记符，超出的话语法树降级为只包含一个文本段落的文档。
+	MaxNestingDepth int
+	// MaxNodes 设置语法树（包括链接引用定义）允许的最大节点数，0 表示不限制。超出的话语法树降级为只包含一个文本段落的文档。
+	MaxNodes int
+	// MaxOutputBytes 设置渲染输出的最大字节数，0 表示不限制。超出的话停止渲染并将输出截断到该长度。
+	MaxOutputBytes int
+}
+
+// Sanitizer 用于过滤 HTML 片段 tokens 中不安全的元素和属性，返回过滤后的 HTML。
+type Sanitizer interface {
+	Sanitize(tokens []byte) []byte
 }
 
 func (context *Context) ParentTip() {
@@ -0,0 +1,369 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"bytes"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/util"
+)
+
+// source 记录了块节点 Tokens 在原始输入中的来源，用于计算行级节点的源码位置。
+type source struct {
+	tokens []byte // 块节点累积的 Tokens
+	spans  []span // Tokens 下标到原始输入字节偏移的映射
+	cursor int    // 顺序查找内容时的起始下标，比如表格单元格
+}
+
+// span 描述了 Tokens 中从下标 index 开始的连续内容来自原始输入中字节偏移 offset 开始的内容。
+type span struct {
+	index, offset int
+}
+
+// offset 返回 Tokens 下标 i 处在原始输入中的字节偏移。
+func (src *source) offset(i int) int {
+	ret := src.spans[0]
+	for _, s := range src.spans[1:] {
+		if s.index > i {
+			break
+		}
+		ret = s
+	}
+	return ret.offset + i - ret.index
+}
+
+// tracking 判断是否需要记录源码位置，仅在对原始输入进行块级解析时才能记录。
+func (context *Context) tracking() bool {
+	return nil != context.Tree && nil != context.Tree.lexer && nil != context.sources
+}
+
+// pos 返回当前行下标 i 处在原始输入中的位置。
+func (context *Context) pos(i int) ast.Position {
+	if !context.tracking() {
+		return ast.Position{}
+	}
+	return context.position(context.Tree.lexer.OriginalOffset(i))
+}
+
+// position 返回原始输入中字节偏移 offset 处的位置。
+func (context *Context) position(offset int) ast.Position {
+	line, column := context.Tree.lexer.Position(offset)
+	return ast.Position{Line: line, Column: column, Offset: offset}
+}
+
+// lineEndPos 返回第 lineNum 行内容（不含换行符）结尾处的位置。
+func (context *Context) lineEndPos(lineNum int) ast.Position {
+	if !context.tracking() {
+		return ast.Position{}
+	}
+	lexer := context.Tree.lexer
+	if lines := lexer.Lines(); lineNum > lines {
+		lineNum = lines
+	}
+	if 1 > lineNum {
+		return ast.Position{}
+	}
+	end := lexer.LineEnd(lineNum)
+	return ast.Position{Line: lineNum, Column: end - lexer.LineStart(lineNum) + 1, Offset: end}
+}
+
+// finalizePos 设置块节点 block 的结束位置为第 lineNum 行结尾。
+func (context *Context) finalizePos(block *ast.Node, lineNum int) {
+	if !context.tracking() {
+		return
+	}
+	lexer := context.Tree.lexer
+	for lineNum > block.StartPos.Line && lineNum <= lexer.Lines() && lexer.IsBlankLine(lineNum) {
+		lineNum-- // 结尾空行不计入块的范围
+	}
+	if lineNum < block.StartPos.Line {
+		lineNum = block.StartPos.Line
+	}
+	if block.EndPos = context.lineEndPos(lineNum); 0 == block.EndPos.Line {
+		block.EndPos = block.StartPos
+	}
+}
+
+// recordSource 记录节点 node 的 Tokens 从下标 index 开始的内容来自当前行 offset 开始的内容。
+func (context *Context) recordSource(node *ast.Node, index, offset int) (ret *source) {
+	if !context.tracking() {
+		return
+	}
+	lexer := context.Tree.lexer
+	ret = context.sources[node]
+	if nil == ret {
+		ret = &source{}
+		context.sources[node] = ret
+	}
+	ret.spans = append(ret.spans, span{index, lexer.OriginalOffset(offset)})
+	for _, e := range lexer.Expands() {
+		if e >= offset { // 替换 \u0000 后的内容需要重新对齐
+			ret.spans = append(ret.spans, span{index + e + 3 - offset, lexer.OriginalOffset(e + 3)})
+		}
+	}
+	return
+}
+
+// shareSource 让节点 to 和节点 from 使用同一个来源，比如段落转换为表或者 Setext 标题的情况。
+func (context *Context) shareSource(from, to *ast.Node) {
+	if src := context.sources[from]; nil != src {
+		context.sources[to] = src
+	}
+}
+
+// locate 查找块节点 node 的 tokens 在其来源中的起始下标，找不到的话返回 -1。
+func (context *Context) locate(node *ast.Node, tokens []byte) (src *source, index int) {
+	for n := node; nil != n; n = n.Parent {
+		if src = context.sources[n]; nil != src {
+			break
+		}
+	}
+	if nil == src || 1 > len(src.spans) {
+		return nil, -1
+	}
+
+	if index = util.BytesOffset(src.tokens, tokens); 0 > index {
+		// 不是来源的子切片的话按内容顺序查找
+		if src.cursor > len(src.tokens) {
+			src.cursor = len(src.tokens)
+		}
+		if index = bytes.Index(src.tokens[src.cursor:], tokens); 0 > index {
+			return nil, -1
+		}
+		index += src.cursor
+		src.cursor = index + len(tokens)
+	}
+	return
+}
+
+// srcPos 返回来源 src 中下标 i 处在原始输入中的位置。
+func (context *Context) srcPos(src *source, i int) ast.Position {
+	return context.position(src.offset(i))
+}
+
+// srcEndPos 返回来源 src 中以下标 i 结尾（不包含）的内容在原始输入中的结束位置。
+func (context *Context) srcEndPos(src *source, start, i int) ast.Position {
+	if i <= start {
+		return context.srcPos(src, start)
+	}
+	return context.position(src.offset(i-1) + 1)
+}
+
+// setSpan 记录行级节点 n 对应的 Tokens 下标范围 [start, end)，已经记录过的话不覆盖。
+func (ctx *InlineContext) setSpan(n *ast.Node, start, end int) {
+	if nil == ctx.spans {
+		ctx.spans = map[*ast.Node][2]int{}
+	}
+	if _, ok := ctx.spans[n]; !ok {
+		ctx.spans[n] = [2]int{start, end}
+	}
+}
+
+// spanOf 返回行级节点 n 对应的 Tokens 下标范围 [start, end)。
+func (ctx *InlineContext) spanOf(n *ast.Node) (start, end int, ok bool) {
+	var s [2]int
+	if s, ok = ctx.spans[n]; ok {
+		return s[0], s[1], true
+	}
+	if start = util.BytesOffset(ctx.tokens, n.Tokens); 0 <= start {
+		return start, start + len(n.Tokens), true
+	}
+	return
+}
+
+// mergeSpan 在将文本节点 next 合并到 n 时合并两者对应的 Tokens 下标范围。
+func (ctx *InlineContext) mergeSpan(n, next *ast.Node) {
+	start, _, ok := ctx.spanOf(n)
+	if !ok {
+		return
+	}
+	if _, end, ok := ctx.spanOf(next); ok {
+		delete(ctx.spans, n)
+		ctx.setSpan(n, start, end)
+	}
+}
+
+// setTokensPos 设置节点 n 的位置为块节点 block 中 tokens 开头 length 个字节的范围。
+func (context *Context) setTokensPos(block, n *ast.Node, tokens []byte, length int) {
+	if !context.tracking() {
+		return
+	}
+	src := context.sources[block]
+	if nil == src {
+		return
+	}
+	if i := util.BytesOffset(src.tokens, tokens); 0 <= i {
+		n.StartPos = context.srcPos(src, i)
+		n.EndPos = context.srcEndPos(src, i, i+length)
+	}
+}
+
+// inlinePos 计算块节点 block 下所有行级节点的源码位置，index 为 ctx.tokens 在来源 src 中的起始下标。
+func (t *Tree) inlinePos(block *ast.Node, ctx *InlineContext, src *source, index int) {
+	if nil == src {
+		return
+	}
+	if 0 == block.StartPos.Line {
+		block.StartPos = t.Context.srcPos(src, index)
+		block.EndPos = t.Context.srcEndPos(src, index, index+ctx.tokensLen)
+	}
+
+	cursor := 0
+	for c := block.FirstChild; nil != c; c = c.Next {
+		cursor = t.inlineNodePos(c, ctx, src, index, cursor, ctx.tokensLen)
+	}
+}
+
+// inlineNodePos 计算行级节点 n 及其子节点的源码位置，无法直接确定的位置按兄弟节点顺序推断，返回 n 的结束下标。
+// 节点位置会被限制在 [cursor, limit) 范围内。
+func (t *Tree) inlineNodePos(n *ast.Node, ctx *InlineContext, src *source, index, cursor, limit int) int {
+	start, end, ok := ctx.spanOf(n)
+	if !ok {
+		if 0 != n.StartPos.Line { // 块级解析时已经确定位置，比如 ATX 标题标记符
+			return cursor
+		}
+		start = cursor
+		if nil == n.FirstChild && 0 < len(n.Tokens) {
+			// 复制出来的内容（比如链接标题）按内容查找
+			if i := bytes.Index(ctx.tokens[cursor:limit], n.Tokens); 0 <= i {
+				start += i
+			}
+		}
+	}
+	if start < cursor {
+		start = cursor
+	}
+	if end > limit {
+		end = limit
+	}
+
+	c := start
+	for child := n.FirstChild; nil != child; child = child.Next {
+		if ok {
+			c = t.inlineNodePos(child, ctx, src, index, c, end)
+		} else {
+			c = t.inlineNodePos(child, ctx, src, index, c, limit)
+		}
+	}
+	if !ok {
+		if nil == n.FirstChild {
+			end = start + len(n.Tokens)
+		} else {
+			end = c
+		}
+		if end > limit {
+			end = limit
+		}
+	}
+	if start > end {
+		start = end
+	}
+
+	n.StartPos = t.Context.srcPos(src, index+start)
+	n.EndPos = t.Context.srcEndPos(src, index+start, index+end)
+	return end
+}
+
+// fillPos 使用子节点的范围补全没有源码位置的节点，比如表格行等。
+func (t *Tree) fillPos(node *ast.Node) {
+	for c := node.FirstChild; nil != c; c = c.Next {
+		t.fillPos(c)
+	}
+	if 0 != node.StartPos.Line {
+		return
+	}
+
+	var first, last *ast.Node
+	for c := node.FirstChild; nil != c; c = c.Next {
+		if 0 != c.StartPos.Line {
+			if nil == first {
+				first = c
+			}
+			last = c
+		}
+	}
+	if nil != first {
+		node.StartPos, node.EndPos = first.StartPos, last.EndPos
+	}
+
+	if ast.NodeTableRow == node.Type && t.Context.tracking() {
+		// 表格行覆盖整行，包括两端的 |
+		table, lexer := node.Parent, t.lexer
+		if ast.NodeTableHead == table.Type {
+			table = table.Parent
+		}
+		if line := node.StartPos.Line; 0 < line {
+			offset := lexer.LineStart(line) + table.StartPos.Column - 1
+			node.StartPos = ast.Position{Line: line, Column: table.StartPos.Column, Offset: offset}
+			node.EndPos = t.Context.lineEndPos(line)
+		}
+	}
+}
+
+// inheritPos 使用兄弟节点或者父节点的位置补全 node 子节点中仍然没有源码位置的节点。
+func inheritPos(node *ast.Node) {
+	for c := node.FirstChild; nil != c; c = c.Next {
+		if 0 == c.StartPos.Line {
+			if nil != c.Previous && 0 != c.Previous.StartPos.Line {
+				c.StartPos = c.Previous.EndPos
+			} else {
+				c.StartPos = node.StartPos
+			}
+			c.EndPos = node.EndPos
+			for next := c.Next; nil != next; next = next.Next {
+				if 0 != next.StartPos.Line {
+					c.EndPos = next.StartPos
+					break
+				}
+			}
+		}
+		inheritPos(c)
+	}
+}
+
+// tablePos 设置从段落 p 尾部拆分出来的表 table 的源码位置，并修正 p 的结束位置。
+func (context *Context) tablePos(p, table *ast.Node) {
+	src := context.sources[p]
+	if nil == src {
+		return
+	}
+	i := util.BytesOffset(src.tokens, p.Tokens)
+	if 0 > i {
+		return
+	}
+	tableStart := i + len(p.Tokens) + 1 // 跳过段落和表之间的换行
+	context.sources[table] = &source{tokens: src.tokens, spans: src.spans, cursor: tableStart}
+	table.StartPos = context.srcPos(src, tableStart)
+	table.EndPos = p.EndPos
+	p.EndPos = context.srcEndPos(src, i, i+len(p.Tokens))
+}
+
+// codeBlockPos 设置围栏代码块 node 下开始标记符、代码和结束标记符的源码位置。
+func (t *Tree) codeBlockPos(node, openMarker, info, code, closeMarker *ast.Node) {
+	if !t.Context.tracking() || 0 == node.StartPos.Line {
+		return
+	}
+	openMarker.StartPos = node.StartPos
+	openMarker.EndPos = ast.Position{Line: node.StartPos.Line, Column: node.StartPos.Column + len(node.CodeBlockOpenFence), Offset: node.StartPos.Offset + len(node.CodeBlockOpenFence)}
+	info.StartPos = openMarker.EndPos
+	if info.EndPos = t.Context.lineEndPos(node.StartPos.Line); info.EndPos.Offset < info.StartPos.Offset {
+		info.EndPos = info.StartPos
+	}
+	if src, index := t.Context.locate(node, code.Tokens); nil != src {
+		code.StartPos = t.Context.srcPos(src, index)
+		code.EndPos = t.Context.srcEndPos(src, index, index+len(code.Tokens))
+	}
+	if 0 < len(node.CodeBlockCloseFence) && node.EndPos.Line > node.StartPos.Line {
+		closeMarker.EndPos = node.EndPos
+		closeMarker.StartPos = ast.Position{Line: node.EndPos.Line, Column: node.EndPos.Column - len(node.CodeBlockCloseFence), Offset: node.EndPos.Offset - len(node.CodeBlockCloseFence)}
+	}
+}
@@ -0,0 +1,332 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/lex"
+)
+
+// ErrNoSource 表示语法树没有保留原始文本（比如由 HTML 转换得到），无法进行增量解析。
+var ErrNoSource = errors.New("reparse: tree has no source")
+
+// ErrEditOutOfRange 表示编辑区间超出了原始文本范围。
+var ErrEditOutOfRange = errors.New("reparse: edit out of range")
+
+// Edit 描述了一次文本编辑：将原始文本中 [Start, End) 字节区间替换为 Text。
+type Edit struct {
+	Start int    // 起始字节偏移
+	End   int    // 结束字节偏移（不包含）
+	Text  []byte // 替换文本
+}
+
+// Reparse 将编辑 edit 应用到语法树的原始文本上，只重新解析受影响的顶层块并替换到树上。
+//
+// 重新解析的区间会在被编辑的顶层块前后各多包含一个顶层块，并且扩展到空行处，以处理段落延续、列表和引述合并等情况；如果解析后区间末尾
+// 的块和原来对不上（比如新打开了一个没有闭合的围栏代码块），则继续向后扩大区间。编辑涉及链接引用定义、脚注或者 Front Matter 时会影响全文，此时退化为全量解析。
+func (t *Tree) Reparse(edit *Edit) error {
+	if nil == t.Source && nil != t.Root.FirstChild {
+		return ErrNoSource
+	}
+	if 0 > edit.Start || edit.Start > edit.End || edit.End > len(t.Source) {
+		return ErrEditOutOfRange
+	}
+
+	source := make([]byte, 0, len(t.Source)-(edit.End-edit.Start)+len(edit.Text))
+	source = append(source, t.Source[:edit.Start]...)
+	source = append(source, edit.Text...)
+	source = append(source, t.Source[edit.End:]...)
+	delta := len(edit.Text) - (edit.End - edit.Start)
+
+	var blocks []*ast.Node
+	for n := t.Root.FirstChild; nil != n; n = n.Next {
+		blocks = append(blocks, n)
+	}
+	length := len(blocks)
+	if 1 > length {
+		t.reparseAll(source)
+		return nil
+	}
+
+	// 每个顶层块占据的原始文本区间为 [starts[i], starts[i+1])，即包含其后的空行
+	starts := make([]int, length+1)
+	for i, block := range blocks {
+		starts[i] = block.StartPos.Offset - (block.StartPos.Column - 1)
+	}
+	starts[0], starts[length] = 0, len(t.Source)
+
+	first, last := length-1, 0
+	for i := 0; i < length; i++ {
+		if edit.Start < starts[i+1] {
+			first = i
+			break
+		}
+	}
+	for i := length - 1; first < i; i-- {
+		if starts[i] <= edit.End {
+			last = i
+			break
+		}
+	}
+	if last < first {
+		last = first
+	}
+
+	lo, hi := first, last
+	if 0 < lo {
+		lo--
+	}
+	if hi < length-1 {
+		hi++
+	}
+	// 区间边界需要落在空行处，因为像表格这样的块是从前面的段落中拆分出来的，不能单独解析
+	for ; 0 < lo && !blankBetween(blocks[lo-1], blocks[lo]); lo-- {
+	}
+
+	for {
+		for ; hi < length-1 && !blankBetween(blocks[hi], blocks[hi+1]); hi++ {
+		}
+		regionStart, regionEnd := starts[lo], starts[hi+1]
+		if t.globalIn(blocks[lo:hi+1], regionStart, regionEnd) {
+			t.reparseAll(source)
+			return nil
+		}
+
+		newEnd := regionEnd + delta
+		region := source[regionStart:newEnd:newEnd]
+		if t.Context.Option.Footnotes && bytes.Contains(region, []byte("[^")) {
+			t.reparseAll(source)
+			return nil
+		}
+		if t.blockQueryEmbedIn(source[:regionStart], t.Source[regionStart:regionEnd], region) {
+			t.reparseAll(source)
+			return nil
+		}
+
+		tree := t.parseRegion(region, 0 == regionStart)
+		if nil == tree {
+			t.reparseAll(source)
+			return nil
+		}
+		if 0 == regionStart && (ast.NodeYamlFrontMatter == blocks[0].Type || (nil != tree.Root.FirstChild && ast.NodeYamlFrontMatter == tree.Root.FirstChild.Type)) {
+			// Front Matter 需要重新解码到树上，编辑涉及文档开头的 Front Matter 时退化为全量解析
+			t.reparseAll(source)
+			return nil
+		}
+
+		if hi < length-1 {
+			// 检查区间最后一个块是否和原来的块对齐，对不上的话说明影响到了区间之后的块，需要扩大区间
+			lastChild := tree.Root.LastChild
+			if nil == lastChild || lastChild.Type != blocks[hi].Type || regionStart+lastChild.StartPos.Offset-(lastChild.StartPos.Column-1) != starts[hi]+delta {
+				hi += hi - lo + 1
+				if hi > length-1 {
+					hi = length - 1
+				}
+				continue
+			}
+		}
+
+		line := 1
+		if 0 < lo {
+			line = blocks[lo].StartPos.Line
+		}
+		var next *ast.Node
+		if hi < length-1 {
+			next = blocks[hi+1]
+		}
+		for _, block := range blocks[lo : hi+1] {
+			block.Unlink()
+		}
+		for n := tree.Root.FirstChild; nil != n; {
+			child := n
+			n = n.Next
+			shiftPos(child, regionStart, line-1)
+			if nil != next {
+				next.InsertBefore(child)
+			} else {
+				t.Root.AppendChild(child)
+			}
+		}
+
+		lineDelta := countLines(region) - countLines(t.Source[regionStart:regionEnd])
+		for n := next; nil != n; n = n.Next {
+			shiftPos(n, delta, lineDelta)
+		}
+		for _, def := range t.Context.LinkRefDefs {
+			if def.StartPos.Offset >= regionEnd {
+				shiftPos(def, delta, lineDelta)
+			}
+		}
+		t.spliceDiagnostics(tree.Diagnostics, regionStart, regionEnd, line-1, delta, lineDelta)
+		t.numberHeadings()
+		t.Source = source
+		if nil == t.Root.LastChild {
+			t.reparseAll(source)
+			return nil
+		}
+		t.Root.EndPos = t.Root.LastChild.EndPos
+		return nil
+	}
+}
+
+// spliceDiagnostics 使用重新解析得到的区间诊断信息 diagnostics 替换原始文本区间 [start, end) 中的诊断信息，并移动区间之后的诊断位置。
+// 标题 ID 是否重复需要全文检查，所以会重新计算。
+func (t *Tree) spliceDiagnostics(diagnostics []*Diagnostic, start, end, lines, delta, lineDelta int) {
+	var kept []*Diagnostic
+	for _, d := range t.Diagnostics {
+		if RuleDuplicateHeadingID == d.Code || (start <= d.StartPos.Offset && d.StartPos.Offset < end) {
+			continue
+		}
+		if end <= d.StartPos.Offset {
+			shiftDiagnostic(d, delta, lineDelta)
+		}
+		kept = append(kept, d)
+	}
+	for _, d := range diagnostics {
+		shiftDiagnostic(d, start, lines)
+		kept = append(kept, d)
+	}
+	t.Diagnostics = kept
+	t.finalizeDiagnostics()
+}
+
+// shiftDiagnostic 将诊断信息 d 的位置后移 offset 个字节、lines 行。
+func shiftDiagnostic(d *Diagnostic, offset, lines int) {
+	d.StartPos.Offset += offset
+	d.StartPos.Line += lines
+	d.EndPos.Offset += offset
+	d.EndPos.Line += lines
+}
+
+// blankBetween 判断相邻的两个顶层块 prev 和 next 之间是否隔有空行。
+func blankBetween(prev, next *ast.Node) bool {
+	endLine := prev.EndPos.Line
+	if 1 == prev.EndPos.Column && prev.StartPos.Offset < prev.EndPos.Offset {
+		endLine--
+	}
+	return 1 < next.StartPos.Line-endLine
+}
+
+// globalIn 判断原始文本区间 [start, end) 中的块 blocks 是否包含会影响全文的链接引用定义或者脚注。
+func (t *Tree) globalIn(blocks []*ast.Node, start, end int) (ret bool) {
+	for _, def := range t.Context.LinkRefDefs {
+		if start <= def.StartPos.Offset && def.StartPos.Offset < end {
+			return true
+		}
+	}
+	if !t.Context.Option.Footnotes {
+		return false
+	}
+
+	for _, block := range blocks {
+		ast.Walk(block, func(n *ast.Node, entering bool) ast.WalkStatus {
+			if ast.NodeFootnotesDef == n.Type || ast.NodeFootnotesRef == n.Type {
+				ret = true
+				return ast.WalkStop
+			}
+			return ast.WalkContinue
+		})
+		if ret {
+			return
+		}
+	}
+	return
+}
+
+// blockQueryEmbedIn 判断编辑前的区间 oldRegion 或者编辑后的区间 newRegion 是否会影响区间之外的内容块查询嵌入。
+// 查询嵌入是否开始需要向后查找闭合标记符 }}，单独解析区间时看不到区间之后的内容，所以区间中包含开始标记符 !{{，
+// 或者包含 }} 并且区间之前的内容 before 中有开始标记符时都需要全量解析。
+func (t *Tree) blockQueryEmbedIn(before, oldRegion, newRegion []byte) bool {
+	if !t.Context.Option.BlockRef {
+		return false
+	}
+	if containsBlockQueryEmbedOpen(oldRegion) || containsBlockQueryEmbedOpen(newRegion) {
+		return true
+	}
+	closeMarker := []byte("}}")
+	return (bytes.Contains(oldRegion, closeMarker) || bytes.Contains(newRegion, closeMarker)) && containsBlockQueryEmbedOpen(before)
+}
+
+// parseRegion 将 region 作为一篇独立文档进行解析，解析时沿用当前树的链接引用定义。如果 region 中定义了新的链接引用、超出资源限制或者解析出错则返回 nil。
+//
+// Front Matter 只能出现在文档开头，所以 head 为 false（区间不在文档开头）时不识别 Front Matter。
+func (t *Tree) parseRegion(region []byte, head bool) (ret *Tree) {
+	option := t.Context.Option
+	if !head {
+		noFrontMatter := *option
+		noFrontMatter.YamlFrontMatter, noFrontMatter.TomlFrontMatter, noFrontMatter.JSONFrontMatter = false, false, false
+		option = &noFrontMatter
+	}
+	ret = &Tree{Name: t.Name, Context: &Context{Option: option}}
+	ret.Context.Tree = ret
+	ret.lexer = lex.NewLexer(region)
+	ret.Root = &ast.Node{Type: ast.NodeDocument}
+	defer func() {
+		if e := recover(); nil != e {
+			// 区间单独解析出错的话退回由全量解析处理
+			ret = nil
+		}
+	}()
+	ret.parseBlocks()
+	if 0 < len(ret.Context.LinkRefDefs) {
+		return nil
+	}
+	ret.Context.LinkRefDefs = t.Context.LinkRefDefs
+	ret.parseInlines()
+	if nil != ret.Err {
+		return nil
+	}
+	ret.fillPos(ret.Root)
+	inheritPos(ret.Root)
+	ret.resolveDiagnostics()
+	ret.lexer = nil
+	return
+}
+
+// reparseAll 全量解析 source 并替换当前树的内容，诊断信息、Front Matter 和错误等解析结果一并替换，只保留树的元数据。
+func (t *Tree) reparseAll(source []byte) {
+	tree := Parse(t.Name, source, t.Context.Option)
+	tree.ID, tree.URL, tree.Path, tree.Marks, tree.Created, tree.Updated, tree.Hash = t.ID, t.URL, t.Path, t.Marks, t.Created, t.Updated, t.Hash
+	*t = *tree
+	t.Context.Tree = t
+}
+
+// shiftPos 将节点 node 及其所有子节点的源码位置后移 offset 个字节、lines 行。
+func shiftPos(node *ast.Node, offset, lines int) {
+	if 0 == offset && 0 == lines {
+		return
+	}
+
+	ast.Walk(node, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if entering {
+			n.StartPos.Offset += offset
+			n.StartPos.Line += lines
+			n.EndPos.Offset += offset
+			n.EndPos.Line += lines
+		}
+		return ast.WalkContinue
+	})
+}
+
+// countLines 统计 text 中的换行数，\r\n 和单独的 \r 都算作一个换行。
+func countLines(text []byte) (ret int) {
+	for i, b := range text {
+		if lex.ItemNewline == b {
+			ret++
+		} else if lex.ItemCarriageReturn == b && (i == len(text)-1 || lex.ItemNewline != text[i+1]) {
+			ret++
+		}
+	}
+	return
+}
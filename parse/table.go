@@ -43,6 +43,10 @@ func (context *Context) parseTable(paragraph *ast.Node) (retParagraph, retTable
 
 func (context *Context) parseTable0(tokens []byte) (ret *ast.Node) {
 	lines := lex.Split(tokens, lex.ItemNewline)
+	var caption []byte
+	if context.Option.ExtendedTable {
+		lines, caption = context.parseTableCaption(lines)
+	}
 	length := len(lines)
 	if 2 > length {
 		return
@@ -71,41 +75,182 @@ func (context *Context) parseTable0(tokens []byte) (ret *ast.Node) {
 		cells[0] = append(cells[0], n)
 	}
 
-	ret = &ast.Node{Type: ast.NodeTable, TableAligns: aligns}
+	ret = &ast.Node{Type: ast.NodeTable, TableAligns: aligns, TableCaption: caption}
 	ret.TableAligns = aligns
 	ret.AppendChild(context.newTableHead(headRow))
 	for i := 2; i < length; i++ {
-		tableRow := context.parseTableRow(lex.TrimWhitespace(lines[i]), aligns, false)
+		rowLines := [][]byte{lex.TrimWhitespace(lines[i])}
+		for context.Option.ExtendedTable && i+1 < length && isTableRowContinued(rowLines[len(rowLines)-1]) {
+			// 行尾 \ 说明下一行是当前行的延续，用于书写多行单元格
+			last := rowLines[len(rowLines)-1]
+			rowLines[len(rowLines)-1] = last[:len(last)-1]
+			i++
+			rowLines = append(rowLines, lex.TrimWhitespace(lines[i]))
+		}
+
+		tableRow := context.parseTableRow(rowLines[0], aligns, false)
 		if nil == tableRow {
 			return
 		}
+		for _, rowLine := range rowLines[1:] {
+			context.appendTableRowLine(tableRow, rowLine, aligns)
+		}
 		ret.AppendChild(tableRow)
 
 		cells = append(cells, []*ast.Node{})
 		for n := tableRow.FirstChild; nil != n; n = n.Next {
-			cells[i-1] = append(cells[i-1], n)
+			cells[len(cells)-1] = append(cells[len(cells)-1], n)
 		}
 	}
 
-	var maxWidth int
-	var maxWidthContent []byte
-	for col := 0; col < len(cells[0]); col++ {
-		for row := 0; row < len(cells); row++ {
-			if maxWidth < cells[row][col].TableCellContentWidth {
-				maxWidth = cells[row][col].TableCellContentWidth
-				maxWidthContent = cells[row][col].Tokens
+	if context.Option.ExtendedTable {
+		context.tableRowspan(ret)
+	}
+
+	ret.TableColumnWidths = TableColumnWidths(cells)
+	return
+}
+
+// TableColumnWidths 按列计算表格单元格 cells（按行排列，需要已经设置 TableCellContentWidth）的内容最大宽度，设置各单元格的最大宽度并返回每列的宽度。
+// 跨列单元格的最大宽度为所跨各列的宽度加上列之间分隔（"| " 和 " "）的宽度，内容超出的话加宽所跨的最后一列。
+func TableColumnWidths(cells [][]*ast.Node) (ret []int) {
+	var contents [][]byte
+	eachCell := func(f func(cell *ast.Node, col, span int)) {
+		for _, row := range cells {
+			col := 0
+			for _, cell := range row {
+				span := tableCellSpan(cell.TableCellColspan)
+				for len(ret) < col+span {
+					ret = append(ret, 0)
+					contents = append(contents, nil)
+				}
+				f(cell, col, span)
+				col += span
 			}
 		}
-		for row := 0; row < len(cells); row++ {
-			cells[row][col].TableCellContentMaxWidth = maxWidth
-			cells[row][col].TableCellMaxWidthContent = maxWidthContent
+	}
+	spanWidth := func(col, span int) (width int) {
+		for _, w := range ret[col : col+span] {
+			width += w
 		}
-		maxWidth = 0
-		maxWidthContent = nil
+		return width + 2*(span-1)
 	}
+
+	eachCell(func(cell *ast.Node, col, span int) {
+		if 1 == span && ret[col] < cell.TableCellContentWidth {
+			ret[col] = cell.TableCellContentWidth
+			contents[col] = cell.Tokens
+		}
+	})
+	eachCell(func(cell *ast.Node, col, span int) {
+		if width := spanWidth(col, span); 1 < span && width < cell.TableCellContentWidth {
+			ret[col+span-1] += cell.TableCellContentWidth - width
+		}
+	})
+	eachCell(func(cell *ast.Node, col, span int) {
+		cell.TableCellContentMaxWidth = spanWidth(col, span)
+		cell.TableCellMaxWidthContent = contents[col+span-1]
+	})
 	return
 }
 
+// parseTableCaption 解析表格最后一行的标题 [标题]，返回去掉标题行后的文本行以及标题内容。
+func (context *Context) parseTableCaption(lines [][]byte) ([][]byte, []byte) {
+	if 3 > len(lines) {
+		return lines, nil
+	}
+
+	last := lex.TrimWhitespace(lines[len(lines)-1])
+	length := len(last)
+	if 3 > length || lex.ItemOpenBracket != last[0] || lex.ItemCloseBracket != last[length-1] || bytes.Contains(last, []byte{lex.ItemPipe}) {
+		return lines, nil
+	}
+	return lines[:len(lines)-1], lex.TrimWhitespace(last[1 : length-1])
+}
+
+// isTableRowContinued 判断扩展表格的行 line 是否以 |\ 结尾，即下一行是该行的延续。
+func isTableRowContinued(line []byte) bool {
+	return bytes.HasSuffix(line, []byte{lex.ItemPipe, lex.ItemBackslash})
+}
+
+// appendTableRowLine 将多行单元格的后续行 line 中各列的内容换行追加到表格行 row 对应的单元格上。
+func (context *Context) appendTableRowLine(row *ast.Node, line []byte, aligns []int) {
+	next := context.parseTableRow(line, aligns, false)
+	if nil == next {
+		return
+	}
+
+	for cell, c := row.FirstChild, next.FirstChild; nil != cell && nil != c; cell, c = cell.Next, c.Next {
+		tokens := make([]byte, 0, len(cell.Tokens)+1+len(c.Tokens))
+		tokens = append(tokens, cell.Tokens...)
+		tokens = append(tokens, lex.ItemNewline)
+		tokens = append(tokens, c.Tokens...)
+		cell.Tokens = tokens
+		cell.TableCellContent = tokens
+	}
+}
+
+// tableCellMergedMarker 扩展表格中跨行合并占位单元格的内容。
+var tableCellMergedMarker = []byte("^^")
+
+// tableRowspan 将表体中内容为 ^^ 的单元格标记为占位单元格，并增加上方同列单元格的跨行数。
+func (context *Context) tableRowspan(table *ast.Node) {
+	var above []*ast.Node // 上一行每一列对应的单元格，被合并的列对应发起跨行的单元格
+	for row := table.FirstChild.Next; nil != row; row = row.Next {
+		var current []*ast.Node
+		for cell := row.FirstChild; nil != cell; cell = cell.Next {
+			origin := cell
+			if col := len(current); col < len(above) && bytes.Equal(tableCellMergedMarker, lex.TrimWhitespace(cell.Tokens)) {
+				origin = above[col]
+				origin.TableCellRowspan = tableCellSpan(origin.TableCellRowspan) + 1
+				cell.TableCellMerged = true
+			}
+			for span := tableCellSpan(cell.TableCellColspan); 0 < span; span-- {
+				current = append(current, origin)
+			}
+		}
+		above = current
+	}
+}
+
+// tableCellSpan 返回单元格的跨行或者跨列数，未设置的话为 1。
+func tableCellSpan(span int) int {
+	if 1 > span {
+		return 1
+	}
+	return span
+}
+
+// parseTableCellBlocks 将扩展表格多行单元格 cell 的内容解析为块级节点挂到该单元格下，只有一个段落的话直接挂段落的行级节点。
+func (t *Tree) parseTableCellBlocks(cell *ast.Node) {
+	tree := &Tree{Context: &Context{Option: t.Context.Option}}
+	tree.Context.Tree = tree
+	tree.lexer = lex.NewLexer(cell.Tokens)
+	tree.Root = &ast.Node{Type: ast.NodeDocument}
+	tree.parseBlocks()
+	for label, def := range t.Context.LinkRefDefs {
+		if _, ok := tree.Context.LinkRefDefs[label]; !ok {
+			tree.Context.LinkRefDefs[label] = def
+		}
+	}
+	tree.parseInlines()
+
+	parent := tree.Root
+	if first := parent.FirstChild; nil != first && nil == first.Next && ast.NodeParagraph == first.Type {
+		parent = first
+	}
+	for c := parent.FirstChild; nil != c; {
+		next := c.Next
+		ast.Walk(c, func(n *ast.Node, entering bool) ast.WalkStatus {
+			// 位置是相对于单元格内容的，需要清空后由单元格推断
+			n.StartPos, n.EndPos = ast.Position{}, ast.Position{}
+			return ast.WalkContinue
+		})
+		cell.AppendChild(c)
+		c = next
+	}
+}
+
 func (context *Context) newTableHead(headRow *ast.Node) *ast.Node {
 	ret := &ast.Node{Type: ast.NodeTableHead}
 	tr := &ast.Node{Type: ast.NodeTableRow}
@@ -127,8 +272,10 @@ func (context *Context) parseTableRow(line []byte, aligns []int, isHead bool) (r
 	if lex.IsBlank(cols[0]) {
 		cols = cols[1:]
 	}
-	if len(cols) > 0 && lex.IsBlank(cols[len(cols)-1]) {
-		cols = cols[:len(cols)-1]
+	if last := len(cols) - 1; 0 <= last && lex.IsBlank(cols[last]) {
+		if !context.Option.ExtendedTable || 0 < len(cols[last]) { // 扩展表格中结尾的空列是跨列标记 | foo ||
+			cols = cols[:last]
+		}
 	}
 
 	colsLen := len(cols)
@@ -137,20 +284,28 @@ func (context *Context) parseTableRow(line []byte, aligns []int, isHead bool) (r
 		return nil
 	}
 
-	var i int
+	var i, align int
 	var col []byte
-	for ; i < colsLen && i < alignsLen; i++ {
+	for ; i < colsLen && align < alignsLen; i++ {
+		if context.Option.ExtendedTable && 1 > len(cols[i]) && nil != ret.LastChild {
+			// 空列说明前一个单元格跨列，比如 | foo || bar |
+			ret.LastChild.TableCellColspan = tableCellSpan(ret.LastChild.TableCellColspan) + 1
+			align++
+			continue
+		}
+
 		col = lex.TrimWhitespace(cols[i])
 		width := len(col)
-		cell := &ast.Node{Type: ast.NodeTableCell, TableCellAlign: aligns[i], TableCellContentWidth: width}
+		cell := &ast.Node{Type: ast.NodeTableCell, TableCellAlign: aligns[align], TableCellContentWidth: width}
 		cell.Tokens = col
 		cell.TableCellContent = col
 		ret.AppendChild(cell)
+		align++
 	}
 
 	// 可能需要补全剩余的列
-	for ; i < alignsLen; i++ {
-		cell := &ast.Node{Type: ast.NodeTableCell, TableCellAlign: aligns[i]}
+	for ; align < alignsLen; align++ {
+		cell := &ast.Node{Type: ast.NodeTableCell, TableCellAlign: aligns[align]}
 		ret.AppendChild(cell)
 	}
 	return
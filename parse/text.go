@@ -21,8 +21,9 @@ import (
 func (t *Tree) parseText(ctx *InlineContext) *ast.Node {
 	start := ctx.pos
 	for ; ctx.pos < ctx.tokensLen; ctx.pos++ {
-		if t.isMarker(ctx.tokens[ctx.pos]) {
+		if start < ctx.pos && t.isMarker(ctx.tokens[ctx.pos]) {
 			// 遇到潜在的标记符时需要跳出该文本节点，回到行级解析主循环
+			// 起始字节已经由主循环判断过，比如没有匹配的自定义语法触发字节，这里需要至少消费一个字节
 			break
 		}
 	}
@@ -36,7 +37,7 @@ func (t *Tree) isMarker(token byte) bool {
 		lex.ItemCloseBracket, lex.ItemAmpersand, lex.ItemTilde, lex.ItemDollar, lex.ItemOpenCurlyBrace, lex.ItemOpenParen, lex.ItemEqual, lex.ItemCrosshatch:
 		return true
 	default:
-		return false
+		return t.isInlineSyntaxTrigger(token)
 	}
 }
 
@@ -112,6 +113,9 @@ func (t *Tree) mergeText(node *ast.Node) {
 		if ast.NodeText == child.Type {
 			// 逐个合并后续兄弟节点
 			for nil != next && ast.NodeText == next.Type {
+				if nil != t.inlineContext {
+					t.inlineContext.mergeSpan(child, next)
+				}
 				child.AppendTokens(next.Tokens)
 				next.Unlink()
 				next = child.Next
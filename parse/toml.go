@@ -0,0 +1,485 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// TomlError 描述了 TOML 解码错误。
+type TomlError struct {
+	Line    int    // 出错的行号，从 1 开始
+	Message string // 错误消息
+}
+
+func (e *TomlError) Error() string {
+	return "toml: line " + strconv.Itoa(e.Line) + ": " + e.Message
+}
+
+// DecodeToml 解码 TOML 文本 data。
+//
+// 这里仅实现了 Front Matter 中常用的 TOML 子集：键值对和点分键、表 [a.b] 和表数组 [[a]]、基本和字面量字符串（包括多行）、
+// 整数、浮点数、布尔值、日期时间、数组、内联表以及注释。带时区的日期时间解码为 time.Time，本地日期时间、日期和时间保留原文。
+func DecodeToml(data []byte) (ret map[string]interface{}, err error) {
+	p := &tomlParser{text: string(data)}
+	ret = map[string]interface{}{}
+	current := ret
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return
+		}
+
+		if '[' == p.peek() {
+			if current, err = p.table(ret); nil != err {
+				return nil, err
+			}
+		} else if err = p.keyValue(current); nil != err {
+			return nil, err
+		}
+		if err = p.lineEnd(); nil != err {
+			return nil, err
+		}
+	}
+}
+
+// tomlParser 用于解码 TOML 文本。
+type tomlParser struct {
+	text string
+	pos  int
+}
+
+func (p *tomlParser) errorf(message string) error {
+	return &TomlError{Line: 1 + strings.Count(p.text[:p.pos], "\n"), Message: message}
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.text)
+}
+
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.text[p.pos]
+}
+
+// skipBlank 跳过空格、制表符和注释，newline 为 true 时还会跳过换行。
+func (p *tomlParser) skipBlank(newline bool) {
+	for !p.eof() {
+		switch c := p.text[p.pos]; {
+		case ' ' == c || '\t' == c || '\r' == c:
+			p.pos++
+		case '\n' == c && newline:
+			p.pos++
+		case '#' == c:
+			for !p.eof() && '\n' != p.text[p.pos] {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// lineEnd 读取行尾，值后面只能跟注释。
+func (p *tomlParser) lineEnd() error {
+	p.skipBlank(false)
+	if p.eof() || '\n' == p.peek() {
+		return nil
+	}
+	return p.errorf("unexpected [" + string(p.peek()) + "] at end of line")
+}
+
+func (p *tomlParser) expect(c byte) error {
+	if c != p.peek() {
+		return p.errorf("expected [" + string(c) + "]")
+	}
+	p.pos++
+	return nil
+}
+
+// table 解码表头 [a.b] 或者表数组头 [[a]]，返回后续键值对所属的表。
+func (p *tomlParser) table(root map[string]interface{}) (ret map[string]interface{}, err error) {
+	p.pos++ // [
+	array := '[' == p.peek()
+	if array {
+		p.pos++
+	}
+	p.skipBlank(false)
+	keys, err := p.key()
+	if nil != err {
+		return
+	}
+	p.skipBlank(false)
+	if err = p.expect(']'); nil != err {
+		return
+	}
+	if array {
+		if err = p.expect(']'); nil != err {
+			return
+		}
+	}
+
+	parent, err := p.subTable(root, keys[:len(keys)-1])
+	if nil != err {
+		return
+	}
+	last := keys[len(keys)-1]
+	if array {
+		tables, ok := parent[last].([]interface{})
+		if nil != parent[last] && !ok {
+			return nil, p.errorf("key [" + last + "] is not an array of tables")
+		}
+		ret = map[string]interface{}{}
+		parent[last] = append(tables, ret)
+		return
+	}
+	return p.subTable(parent, keys[len(keys)-1:])
+}
+
+// subTable 返回表 table 下按照 keys 逐级查找的子表，不存在的话创建，表数组取最后一个表。
+func (p *tomlParser) subTable(table map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	for _, key := range keys {
+		switch value := table[key].(type) {
+		case nil:
+			sub := map[string]interface{}{}
+			table[key] = sub
+			table = sub
+		case map[string]interface{}:
+			table = value
+		case []interface{}:
+			if 1 > len(value) {
+				return nil, p.errorf("key [" + key + "] is not a table")
+			}
+			sub, ok := value[len(value)-1].(map[string]interface{})
+			if !ok {
+				return nil, p.errorf("key [" + key + "] is not a table")
+			}
+			table = sub
+		default:
+			return nil, p.errorf("key [" + key + "] is not a table")
+		}
+	}
+	return table, nil
+}
+
+// keyValue 解码键值对 key = value 并保存到表 table 中。
+func (p *tomlParser) keyValue(table map[string]interface{}) error {
+	keys, err := p.key()
+	if nil != err {
+		return err
+	}
+	p.skipBlank(false)
+	if err = p.expect('='); nil != err {
+		return err
+	}
+	p.skipBlank(false)
+	value, err := p.value()
+	if nil != err {
+		return err
+	}
+
+	if table, err = p.subTable(table, keys[:len(keys)-1]); nil != err {
+		return err
+	}
+	last := keys[len(keys)-1]
+	if _, dup := table[last]; dup {
+		return p.errorf("duplicate key [" + last + "]")
+	}
+	table[last] = value
+	return nil
+}
+
+// key 解码裸键、引号键或者点分键。
+func (p *tomlParser) key() (ret []string, err error) {
+	for {
+		var key string
+		switch p.peek() {
+		case '"', '\'':
+			if key, err = p.str(); nil != err {
+				return
+			}
+		default:
+			start := p.pos
+			for !p.eof() && isTomlBareKeyChar(p.text[p.pos]) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, p.errorf("expected key")
+			}
+			key = p.text[start:p.pos]
+		}
+		ret = append(ret, key)
+
+		p.skipBlank(false)
+		if '.' != p.peek() {
+			return
+		}
+		p.pos++
+		p.skipBlank(false)
+	}
+}
+
+func isTomlBareKeyChar(c byte) bool {
+	return ('a' <= c && 'z' >= c) || ('A' <= c && 'Z' >= c) || ('0' <= c && '9' >= c) || '_' == c || '-' == c
+}
+
+func (p *tomlParser) value() (interface{}, error) {
+	switch c := p.peek(); c {
+	case '"', '\'':
+		return p.str()
+	case '[':
+		return p.array()
+	case '{':
+		return p.inlineTable()
+	case 0:
+		return nil, p.errorf("expected value")
+	}
+	return p.scalar()
+}
+
+// str 解码基本字符串、字面量字符串以及它们的多行形式。
+func (p *tomlParser) str() (string, error) {
+	quote := p.text[p.pos : p.pos+1]
+	if strings.HasPrefix(p.text[p.pos:], quote+quote+quote) {
+		return p.multilineStr(quote)
+	}
+
+	p.pos++
+	buf := &strings.Builder{}
+	for !p.eof() {
+		c := p.text[p.pos]
+		switch {
+		case '\n' == c:
+			return "", p.errorf("newline in string")
+		case quote[0] == c:
+			p.pos++
+			return buf.String(), nil
+		case '\\' == c && "\"" == quote:
+			if err := p.escape(buf); nil != err {
+				return "", err
+			}
+			continue
+		default:
+			buf.WriteByte(c)
+		}
+		p.pos++
+	}
+	return "", p.errorf("unclosed string")
+}
+
+// multilineStr 解码三个双引号或者三个单引号包裹的多行字符串，紧跟开始标记的换行会被去掉，基本字符串中行尾的 \ 会去掉换行和后续空白。
+func (p *tomlParser) multilineStr(quote string) (string, error) {
+	delimiter := quote + quote + quote
+	p.pos += 3
+	if strings.HasPrefix(p.text[p.pos:], "\r\n") {
+		p.pos += 2
+	} else if strings.HasPrefix(p.text[p.pos:], "\n") {
+		p.pos++
+	}
+
+	buf := &strings.Builder{}
+	for !p.eof() {
+		if strings.HasPrefix(p.text[p.pos:], delimiter) {
+			p.pos += 3
+			for i := 0; 2 > i && quote[0] == p.peek(); i++ { // 结束标记前最多可以再有两个引号
+				buf.WriteByte(quote[0])
+				p.pos++
+			}
+			return buf.String(), nil
+		}
+
+		c := p.text[p.pos]
+		if '\\' == c && "\"" == quote {
+			if rest := strings.TrimLeft(p.text[p.pos+1:], " \t\r"); strings.HasPrefix(rest, "\n") {
+				p.pos = len(p.text) - len(strings.TrimLeft(rest, " \t\r\n"))
+				continue
+			}
+			if err := p.escape(buf); nil != err {
+				return "", err
+			}
+			continue
+		}
+		buf.WriteByte(c)
+		p.pos++
+	}
+	return "", p.errorf("unclosed multi-line string")
+}
+
+// escape 解码基本字符串中的转义序列并写入 buf。
+func (p *tomlParser) escape(buf *strings.Builder) error {
+	if p.pos+1 >= len(p.text) {
+		return p.errorf("unclosed string")
+	}
+	c := p.text[p.pos+1]
+	p.pos += 2
+	switch c {
+	case 'b':
+		buf.WriteByte('\b')
+	case 't':
+		buf.WriteByte('\t')
+	case 'n':
+		buf.WriteByte('\n')
+	case 'f':
+		buf.WriteByte('\f')
+	case 'r':
+		buf.WriteByte('\r')
+	case '"', '\\':
+		buf.WriteByte(c)
+	case 'u', 'U':
+		size := 4
+		if 'U' == c {
+			size = 8
+		}
+		if p.pos+size > len(p.text) {
+			return p.errorf("invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(p.text[p.pos:p.pos+size], 16, 32)
+		if nil != err || !utf8.ValidRune(rune(code)) {
+			return p.errorf("invalid unicode escape")
+		}
+		buf.WriteRune(rune(code))
+		p.pos += size
+	default:
+		return p.errorf("invalid escape [\\" + string(c) + "]")
+	}
+	return nil
+}
+
+func (p *tomlParser) array() (ret []interface{}, err error) {
+	ret = []interface{}{}
+	p.pos++ // [
+	for {
+		p.skipBlank(true)
+		if ']' == p.peek() {
+			p.pos++
+			return
+		}
+		item, itemErr := p.value()
+		if nil != itemErr {
+			return nil, itemErr
+		}
+		ret = append(ret, item)
+
+		p.skipBlank(true)
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, p.errorf("expected , or ] in array")
+		}
+	}
+}
+
+func (p *tomlParser) inlineTable() (ret map[string]interface{}, err error) {
+	ret = map[string]interface{}{}
+	p.pos++ // {
+	p.skipBlank(false)
+	if '}' == p.peek() {
+		p.pos++
+		return
+	}
+	for {
+		p.skipBlank(false)
+		if err = p.keyValue(ret); nil != err {
+			return nil, err
+		}
+		p.skipBlank(false)
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return
+		default:
+			return nil, p.errorf("expected , or } in inline table")
+		}
+	}
+}
+
+// tomlTimeLayouts 是解码带时区的日期时间时尝试的格式。
+var tomlTimeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999Z07:00"}
+
+// tomlLocalTimeLayouts 是本地日期时间、日期和时间的格式，解码后保留原文。
+var tomlLocalTimeLayouts = []string{"2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999", "2006-01-02", "15:04:05.999999999"}
+
+// scalar 解码布尔值、整数、浮点数和日期时间。
+func (p *tomlParser) scalar() (interface{}, error) {
+	start := p.pos
+	for !p.eof() {
+		c := p.text[p.pos]
+		if isTomlBareKeyChar(c) || '+' == c || '.' == c || ':' == c {
+			p.pos++
+			continue
+		}
+		// 日期和时间之间可以使用空格分隔
+		if ' ' == c && 10 == p.pos-start && '-' == p.text[start+4] && p.pos+1 < len(p.text) && '0' <= p.text[p.pos+1] && '9' >= p.text[p.pos+1] {
+			p.pos++
+			continue
+		}
+		break
+	}
+	raw := p.text[start:p.pos]
+
+	switch raw {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "inf", "+inf", "-inf", "nan", "+nan", "-nan":
+		value, _ := strconv.ParseFloat(strings.TrimPrefix(raw, "+"), 64)
+		return value, nil
+	}
+
+	number := strings.ReplaceAll(raw, "_", "")
+	if value, err := strconv.ParseInt(number, 10, 64); nil == err {
+		return value, nil
+	}
+	for prefix, base := range map[string]int{"0x": 16, "0o": 8, "0b": 2} {
+		if strings.HasPrefix(number, prefix) {
+			if value, err := strconv.ParseInt(number[2:], base, 64); nil == err {
+				return value, nil
+			}
+		}
+	}
+	if isTomlFloat(number) {
+		if value, err := strconv.ParseFloat(number, 64); nil == err {
+			return value, nil
+		}
+	}
+
+	for _, layout := range tomlTimeLayouts {
+		if value, err := time.Parse(layout, raw); nil == err {
+			return value, nil
+		}
+	}
+	for _, layout := range tomlLocalTimeLayouts {
+		if _, err := time.Parse(layout, raw); nil == err {
+			return raw, nil
+		}
+	}
+	p.pos = start
+	return nil, p.errorf("invalid value [" + raw + "]")
+}
+
+// isTomlFloat 判断去掉下划线后的 number 是否是 TOML 浮点数的写法：可选的正负号后必须是数字，只能包含数字、小数点和指数。
+func isTomlFloat(number string) bool {
+	number = strings.TrimLeft(number, "+-")
+	if 1 > len(number) || '0' > number[0] || '9' < number[0] {
+		return false
+	}
+	return "" == strings.Trim(number, "0123456789.eE+-")
+}
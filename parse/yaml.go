@@ -0,0 +1,910 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// YamlError 描述了 YAML 解码错误。
+type YamlError struct {
+	Line    int    // 出错的行号，从 1 开始
+	Message string // 错误消息
+}
+
+func (e *YamlError) Error() string {
+	return "yaml: line " + strconv.Itoa(e.Line) + ": " + e.Message
+}
+
+// DecodeYaml 解码 YAML 文本 data，顶层必须是映射。
+//
+// 这里仅实现了 Front Matter 中常用的 YAML 子集：块映射和块序列、流式 [] 和 {}、单双引号字符串、| 和 > 块标量以及注释，
+// 纯量解码为 nil、bool、int、float64、time.Time（日期和 RFC 3339 时间）或者 string，不支持锚点、别名、标签和多文档。
+func DecodeYaml(data []byte) (ret map[string]interface{}, err error) {
+	entries, _, err := decodeYamlEntries(data)
+	if nil != err {
+		return
+	}
+	ret = map[string]interface{}{}
+	for _, entry := range entries {
+		ret[entry.key] = entry.value
+	}
+	return
+}
+
+// EncodeYaml 将 value 编码为 YAML 映射文本，映射的键按字典序输出。
+func EncodeYaml(value map[string]interface{}) []byte {
+	buf := &bytes.Buffer{}
+	writeYamlMapping(buf, value, 0, false)
+	return buf.Bytes()
+}
+
+// yamlEntry 描述了 YAML 顶层映射中的一个键值对及其原文。
+type yamlEntry struct {
+	key   string
+	value interface{}
+	lead  []string // 键前面的空行和注释行
+	body  []string // 键值对原文
+}
+
+// decodeYamlEntries 按原文顺序解码 YAML 顶层映射的键值对，trailing 为最后一个键值对之后的空行和注释行。
+func decodeYamlEntries(data []byte) (entries []*yamlEntry, trailing []string, err error) {
+	d, err := newYamlDecoder(data)
+	if nil != err {
+		return
+	}
+
+	start, keys := 0, map[string]bool{}
+	for line := d.next(); nil != line; line = d.next() {
+		if 0 != line.indent {
+			return nil, nil, d.errorf(line, "unexpected indentation")
+		}
+		key, value, ok, keyErr := d.splitKey(line)
+		if nil != keyErr {
+			return nil, nil, keyErr
+		}
+		if !ok {
+			return nil, nil, d.errorf(line, "expected a mapping key")
+		}
+		if keys[key] {
+			return nil, nil, d.errorf(line, "duplicate key ["+key+"]")
+		}
+		keys[key] = true
+
+		keyLine := d.pos
+		d.pos++
+		entry := &yamlEntry{key: key}
+		if entry.value, err = d.value(line, 0, value); nil != err {
+			return nil, nil, err
+		}
+
+		// 值后面的空行和注释行属于下一个键值对
+		end := d.pos
+		for keyLine+1 < end && d.lines[end-1].blank() {
+			end--
+		}
+		entry.lead, entry.body = d.raws(start, keyLine), d.raws(keyLine, end)
+		entries = append(entries, entry)
+		start = end
+	}
+	trailing = d.raws(start, len(d.lines))
+	return
+}
+
+// mergeYaml 将 YAML 文本 original 更新为 meta：值没有变化的键保留原文和注释，变化的键重新编码，删除的键连同前面的注释一起移除，新增的键按字典序追加到末尾。
+// original 解码失败的话直接编码 meta。
+func mergeYaml(original []byte, meta map[string]interface{}) []byte {
+	entries, trailing, err := decodeYamlEntries(original)
+	if nil != err {
+		return EncodeYaml(meta)
+	}
+
+	var lines []string
+	existing := map[string]bool{}
+	for _, entry := range entries {
+		existing[entry.key] = true
+		value, ok := meta[entry.key]
+		if !ok {
+			continue
+		}
+		lines = append(lines, entry.lead...)
+		if reflect.DeepEqual(normalizeYaml(value), entry.value) {
+			lines = append(lines, entry.body...)
+			continue
+		}
+		buf := &bytes.Buffer{}
+		writeYamlMapping(buf, map[string]interface{}{entry.key: value}, 0, false)
+		lines = append(lines, strings.TrimSuffix(buf.String(), "\n"))
+	}
+
+	added := map[string]interface{}{}
+	for key, value := range meta {
+		if !existing[key] {
+			added[key] = value
+		}
+	}
+	if 0 < len(added) {
+		lines = append(lines, strings.TrimSuffix(string(EncodeYaml(added)), "\n"))
+	}
+	lines = append(lines, trailing...)
+	return []byte(strings.TrimSpace(strings.Join(lines, "\n")))
+}
+
+// yamlLine 描述了 YAML 文本中的一行。
+type yamlLine struct {
+	num    int    // 行号，从 1 开始
+	indent int    // 缩进空格数
+	text   string // 去掉缩进和行尾空白后的内容
+	raw    string // 原文
+}
+
+// blank 判断该行是否是空行或者注释行。
+func (l *yamlLine) blank() bool {
+	return "" == l.text || '#' == l.text[0]
+}
+
+// yamlDecoder 用于按行解码 YAML 文本。
+type yamlDecoder struct {
+	lines []*yamlLine
+	pos   int // 当前解码到的行下标
+}
+
+func newYamlDecoder(data []byte) (ret *yamlDecoder, err error) {
+	ret = &yamlDecoder{}
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	for i, raw := range strings.Split(text, "\n") {
+		content := strings.TrimLeft(raw, " ")
+		line := &yamlLine{num: i + 1, indent: len(raw) - len(content), text: strings.TrimRight(content, " \t"), raw: raw}
+		if strings.HasPrefix(content, "\t") && "" != line.text {
+			return nil, ret.errorf(line, "tabs are not allowed for indentation")
+		}
+		ret.lines = append(ret.lines, line)
+	}
+	return
+}
+
+func (d *yamlDecoder) errorf(line *yamlLine, message string) error {
+	return &YamlError{Line: line.num, Message: message}
+}
+
+// raws 返回下标 [start, end) 范围内的行原文。
+func (d *yamlDecoder) raws(start, end int) (ret []string) {
+	for _, line := range d.lines[start:end] {
+		ret = append(ret, line.raw)
+	}
+	return
+}
+
+// next 跳过空行和注释行，返回下一个内容行，没有的话返回 nil。
+func (d *yamlDecoder) next() *yamlLine {
+	for ; d.pos < len(d.lines); d.pos++ {
+		if !d.lines[d.pos].blank() {
+			return d.lines[d.pos]
+		}
+	}
+	return nil
+}
+
+// block 解码从下一个内容行开始的块映射或者块序列，块的缩进即该行的缩进。
+func (d *yamlDecoder) block() (interface{}, error) {
+	line := d.next()
+	if isYamlSeqItem(line.text) {
+		return d.seq(line.indent)
+	}
+	return d.mapping(line.indent)
+}
+
+func (d *yamlDecoder) mapping(indent int) (ret map[string]interface{}, err error) {
+	ret = map[string]interface{}{}
+	for line := d.next(); nil != line && indent <= line.indent; line = d.next() {
+		if indent < line.indent {
+			return nil, d.errorf(line, "unexpected indentation")
+		}
+		key, value, ok, keyErr := d.splitKey(line)
+		if nil != keyErr {
+			return nil, keyErr
+		}
+		if !ok {
+			return nil, d.errorf(line, "expected a mapping key")
+		}
+		if _, dup := ret[key]; dup {
+			return nil, d.errorf(line, "duplicate key ["+key+"]")
+		}
+		d.pos++
+		if ret[key], err = d.value(line, indent, value); nil != err {
+			return nil, err
+		}
+	}
+	return
+}
+
+func (d *yamlDecoder) seq(indent int) (ret []interface{}, err error) {
+	ret = []interface{}{}
+	for line := d.next(); nil != line && indent == line.indent && isYamlSeqItem(line.text); line = d.next() {
+		rest := strings.TrimLeft(line.text[1:], " ")
+		var item interface{}
+		if "" == rest {
+			d.pos++
+			if next := d.next(); nil != next && indent < next.indent {
+				item, err = d.block()
+			}
+		} else if _, _, ok, _ := d.splitKey(&yamlLine{num: line.num, text: rest}); ok || isYamlSeqItem(rest) {
+			// - key: value 和 - - item 在同一行开始了一个缩进更深的块
+			line.indent += len(line.text) - len(rest)
+			line.text = rest
+			item, err = d.block()
+		} else {
+			d.pos++
+			item, err = d.flow(line, d.fold(indent, rest))
+		}
+		if nil != err {
+			return nil, err
+		}
+		ret = append(ret, item)
+	}
+	return
+}
+
+// value 解码键所在行 line 上冒号后面的值 value，indent 为该键所在映射的缩进。
+func (d *yamlDecoder) value(line *yamlLine, indent int, value string) (interface{}, error) {
+	if "" == value || '#' == value[0] {
+		next := d.next()
+		if nil == next || next.indent < indent || (next.indent == indent && !isYamlSeqItem(next.text)) {
+			return nil, nil
+		}
+		if next.indent == indent {
+			return d.seq(indent) // 序列项可以和键对齐
+		}
+		return d.block()
+	}
+	if '|' == value[0] || '>' == value[0] {
+		return d.blockScalar(line, indent, value)
+	}
+	if err := d.checkPlain(line, value); nil != err {
+		return nil, err
+	}
+	return d.flow(line, d.fold(indent, value))
+}
+
+// checkPlain 检查键所在行 line 上的纯量值 text，块上下文中纯量所在行不能再开始一个映射（包含 ": " 或者以 ":" 结尾）或者序列（以 "- " 开头）。
+func (d *yamlDecoder) checkPlain(line *yamlLine, text string) error {
+	switch text[0] {
+	case '"', '\'', '[', '{':
+		return nil
+	}
+	if isYamlSeqItem(text) {
+		return d.errorf(line, "block sequence entries are not allowed here")
+	}
+	for i := 0; i < len(text); i++ {
+		if ' ' == text[i] && i+1 < len(text) && '#' == text[i+1] {
+			break
+		}
+		if ':' == text[i] && (i+1 == len(text) || ' ' == text[i+1]) {
+			return d.errorf(line, "mapping values are not allowed here")
+		}
+	}
+	return nil
+}
+
+// fold 将跨行的纯量或者引号字符串 text 与后续行折叠为一行：行之间折叠为空格，空行折叠为换行。后续行需要比所在映射或者序列的缩进 indent 更深，
+// 纯量遇到注释或者键值对时结束，引号字符串没有闭合的话不折叠，错误在解码时报告。
+func (d *yamlDecoder) fold(indent int, text string) string {
+	quote := text[0]
+	switch quote {
+	case '[', '{':
+		return text
+	case '"', '\'':
+		if 0 < yamlQuoteEnd(text) {
+			return text
+		}
+	default:
+		if isYamlPlainEnd(text) {
+			return text
+		}
+		quote = 0
+	}
+
+	buf := &strings.Builder{}
+	buf.WriteString(text)
+	pos, blanks := d.pos, 0
+	for ; d.pos < len(d.lines); d.pos++ {
+		l := d.lines[d.pos]
+		if "" == l.text {
+			blanks++
+			continue
+		}
+		if l.indent <= indent {
+			break
+		}
+		if 0 == quote {
+			if _, _, ok, _ := d.splitKey(l); ok || '#' == l.text[0] {
+				break
+			}
+		}
+
+		prev := buf.String()
+		switch {
+		case 0 < blanks:
+			newline := "\n"
+			if '"' == quote {
+				newline = "\\n" // 双引号字符串按转义解码
+			}
+			buf.WriteString(strings.Repeat(newline, blanks))
+		case '"' == quote && 1 == (len(prev)-len(strings.TrimRight(prev, "\\")))%2:
+			// 双引号字符串中行尾的 \ 转义了换行，直接连接下一行
+			buf.Reset()
+			buf.WriteString(prev[:len(prev)-1])
+		default:
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(l.text)
+		blanks = 0
+
+		if 0 == quote {
+			if isYamlPlainEnd(l.text) {
+				d.pos++
+				return buf.String()
+			}
+		} else if 0 < yamlQuoteEnd(buf.String()) {
+			d.pos++
+			return buf.String()
+		}
+	}
+	if 0 != quote {
+		d.pos = pos
+		return text
+	}
+	d.pos -= blanks
+	return buf.String()
+}
+
+// isYamlPlainEnd 判断纯量行 text 是否以注释结束，以注释结束的纯量不能跨行。
+func isYamlPlainEnd(text string) bool {
+	return strings.Contains(text, " #")
+}
+
+// blockScalar 解码 | 或者 > 块标量，header 为块标量头，比如 |-。
+func (d *yamlDecoder) blockScalar(line *yamlLine, indent int, header string) (interface{}, error) {
+	literal, chomp, blockIndent := '|' == header[0], byte(0), 0
+	for _, c := range []byte(strings.TrimSpace(strings.SplitN(header[1:], "#", 2)[0])) {
+		switch {
+		case '-' == c || '+' == c:
+			chomp = c
+		case '1' <= c && '9' >= c:
+			blockIndent = indent + int(c-'0')
+		default:
+			return nil, d.errorf(line, "invalid block scalar header ["+header+"]")
+		}
+	}
+
+	end := len(d.lines)
+	if "" == d.lines[end-1].raw {
+		end-- // 文本以换行结尾时最后一行是拆分出的空串，不是空行
+	}
+	var lines []string
+	for ; d.pos < end; d.pos++ {
+		l := d.lines[d.pos]
+		if "" == strings.TrimSpace(l.raw) {
+			lines = append(lines, "")
+			continue
+		}
+		if l.indent <= indent {
+			break
+		}
+		if 0 == blockIndent {
+			blockIndent = l.indent
+		}
+		if l.indent < blockIndent {
+			return nil, d.errorf(l, "block scalar line is less indented than the first line")
+		}
+		lines = append(lines, l.raw[blockIndent:])
+	}
+
+	// 尾部空行不属于后面的内容，除非使用 + 保留
+	trailing := 0
+	for trailing < len(lines) && "" == lines[len(lines)-1-trailing] {
+		trailing++
+	}
+	d.pos -= trailing
+	lines = lines[:len(lines)-trailing]
+
+	var text string
+	if literal {
+		text = strings.Join(lines, "\n")
+	} else {
+		buf := &strings.Builder{}
+		for i, l := range lines {
+			// 空行折叠为换行，普通的行之间折叠为空格，缩进更深的行保留换行
+			if 0 < i {
+				prev := lines[i-1]
+				if "" == l || ' ' == l[0] || (0 < len(prev) && ' ' == prev[0]) {
+					buf.WriteByte('\n')
+				} else if "" != prev {
+					buf.WriteByte(' ')
+				}
+			}
+			buf.WriteString(l)
+		}
+		text = buf.String()
+	}
+
+	switch chomp {
+	case '-':
+		return text, nil
+	case '+':
+		return text + strings.Repeat("\n", trailing+1), nil
+	}
+	if "" == text {
+		return "", nil
+	}
+	return text + "\n", nil
+}
+
+// flow 解码 line 上的纯量或者流式 [] {} 值 text。
+func (d *yamlDecoder) flow(line *yamlLine, text string) (ret interface{}, err error) {
+	p := &yamlFlowParser{text: text, line: line, d: d}
+	if ret, err = p.value(false); nil != err {
+		return
+	}
+	p.skipSpaces()
+	if p.pos < len(p.text) && '#' != p.text[p.pos] {
+		return nil, d.errorf(line, "unexpected ["+p.text[p.pos:]+"] after value")
+	}
+	return
+}
+
+// splitKey 将 line 拆分为映射的键和值，不是键值对的话 ok 为 false。
+func (d *yamlDecoder) splitKey(line *yamlLine) (key, value string, ok bool, err error) {
+	text := line.text
+	if "" == text {
+		return
+	}
+
+	switch text[0] {
+	case '"', '\'':
+		p := &yamlFlowParser{text: text, line: line, d: d}
+		quoted, quoteErr := p.quoted()
+		if nil != quoteErr || p.pos >= len(text) || ':' != text[p.pos] || (p.pos+1 < len(text) && ' ' != text[p.pos+1]) {
+			return // 不是键的话作为纯量处理，错误在解码纯量时报告
+		}
+		return quoted, strings.TrimSpace(text[p.pos+1:]), true, nil
+	case '[', '{', '#', '|', '>':
+		return
+	}
+	if isYamlSeqItem(text) {
+		return
+	}
+	for i := 0; i < len(text); i++ {
+		if ' ' == text[i] && i+1 < len(text) && '#' == text[i+1] {
+			return
+		}
+		if ':' == text[i] && (i+1 == len(text) || ' ' == text[i+1]) {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true, nil
+		}
+	}
+	return
+}
+
+func isYamlSeqItem(text string) bool {
+	return "-" == text || strings.HasPrefix(text, "- ")
+}
+
+// yamlFlowParser 用于解码一行中的纯量和流式 [] {} 值。
+type yamlFlowParser struct {
+	text string
+	pos  int
+	line *yamlLine
+	d    *yamlDecoder
+}
+
+func (p *yamlFlowParser) skipSpaces() {
+	for p.pos < len(p.text) && ' ' == p.text[p.pos] {
+		p.pos++
+	}
+}
+
+// value 解码下一个值，inFlow 说明是否位于 [] 或者 {} 中。
+func (p *yamlFlowParser) value(inFlow bool) (interface{}, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.text) {
+		return nil, nil
+	}
+
+	switch p.text[p.pos] {
+	case '[':
+		return p.seq()
+	case '{':
+		return p.mapping()
+	case '"', '\'':
+		return p.quoted()
+	case '&', '*', '!':
+		return nil, p.d.errorf(p.line, "anchors, aliases and tags are not supported")
+	}
+	return resolveYamlPlain(p.plain(inFlow)), nil
+}
+
+// plain 读取一个纯量原文，纯量在注释 # 前结束，位于流式值中时还会在 , ] } 和 ": " 前结束。
+func (p *yamlFlowParser) plain(inFlow bool) string {
+	start := p.pos
+	for ; p.pos < len(p.text); p.pos++ {
+		c := p.text[p.pos]
+		if '#' == c && start < p.pos && ' ' == p.text[p.pos-1] {
+			break
+		}
+		if inFlow && (',' == c || ']' == c || '}' == c || (':' == c && (p.pos+1 == len(p.text) || strings.IndexByte(" ,]}", p.text[p.pos+1]) >= 0))) {
+			break
+		}
+	}
+	return strings.TrimSpace(p.text[start:p.pos])
+}
+
+func (p *yamlFlowParser) quoted() (string, error) {
+	quote, start := p.text[p.pos], p.pos
+	end := yamlQuoteEnd(p.text[start:])
+	if 0 > end {
+		p.pos = len(p.text)
+		return "", p.d.errorf(p.line, "unclosed quoted string")
+	}
+
+	p.pos += end
+	raw := p.text[start:p.pos]
+	if '\'' == quote {
+		return strings.ReplaceAll(raw[1:len(raw)-1], "''", "'"), nil
+	}
+	ret, err := strconv.Unquote(raw)
+	if nil != err {
+		return "", p.d.errorf(p.line, "invalid escape in double-quoted string "+raw)
+	}
+	return ret, nil
+}
+
+// yamlQuoteEnd 返回以引号开头的 text 中引号字符串结束后的下标，没有闭合的话返回 -1。
+func yamlQuoteEnd(text string) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		c := text[i]
+		if '\\' == c && '"' == quote {
+			i++
+			continue
+		}
+		if c != quote {
+			continue
+		}
+		if '\'' == quote && i+1 < len(text) && '\'' == text[i+1] {
+			i++ // '' 是单引号字符串中的转义
+			continue
+		}
+		return i + 1
+	}
+	return -1
+}
+
+func (p *yamlFlowParser) seq() (ret []interface{}, err error) {
+	ret = []interface{}{}
+	p.pos++ // [
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.text) {
+			return nil, p.d.errorf(p.line, "unclosed flow sequence")
+		}
+		if ']' == p.text[p.pos] {
+			p.pos++
+			return
+		}
+		item, itemErr := p.value(true)
+		if nil != itemErr {
+			return nil, itemErr
+		}
+		ret = append(ret, item)
+		if err = p.separator(']'); nil != err {
+			return
+		}
+	}
+}
+
+func (p *yamlFlowParser) mapping() (ret map[string]interface{}, err error) {
+	ret = map[string]interface{}{}
+	p.pos++ // {
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.text) {
+			return nil, p.d.errorf(p.line, "unclosed flow mapping")
+		}
+		if '}' == p.text[p.pos] {
+			p.pos++
+			return
+		}
+		k, keyErr := p.value(true)
+		if nil != keyErr {
+			return nil, keyErr
+		}
+		key := yamlKeyString(k)
+		if _, dup := ret[key]; dup {
+			return nil, p.d.errorf(p.line, "duplicate key ["+key+"]")
+		}
+		p.skipSpaces()
+		var value interface{}
+		if p.pos < len(p.text) && ':' == p.text[p.pos] {
+			p.pos++
+			if value, err = p.value(true); nil != err {
+				return
+			}
+		}
+		ret[key] = value
+		if err = p.separator('}'); nil != err {
+			return
+		}
+	}
+}
+
+// separator 读取流式值中的分隔符 , 或者结束符 end。
+func (p *yamlFlowParser) separator(end byte) error {
+	p.skipSpaces()
+	if p.pos < len(p.text) {
+		if ',' == p.text[p.pos] {
+			p.pos++
+			return nil
+		}
+		if end == p.text[p.pos] {
+			return nil
+		}
+	}
+	if '}' == end {
+		return p.d.errorf(p.line, "expected , or } in flow mapping")
+	}
+	return p.d.errorf(p.line, "expected , or ] in flow sequence")
+}
+
+// yamlTimeLayouts 是解码时间纯量时尝试的格式。
+var yamlTimeLayouts = []string{"2006-01-02", time.RFC3339Nano, "2006-01-02 15:04:05Z07:00", "2006-01-02T15:04:05", "2006-01-02 15:04:05"}
+
+// resolveYamlPlain 按照 YAML 1.2 核心模式确定纯量 s 的类型，日期和 RFC 3339 时间解析为 time.Time。
+func resolveYamlPlain(s string) interface{} {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return math.Inf(1)
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1)
+	case ".nan", ".NaN", ".NAN":
+		return math.NaN()
+	}
+
+	if i, err := parseYamlInt(s); nil == err {
+		return i
+	}
+	if isYamlFloat(s) {
+		if f, err := strconv.ParseFloat(s, 64); nil == err {
+			return f
+		}
+	}
+	if 10 <= len(s) && '-' == s[4] && '-' == s[7] {
+		for _, layout := range yamlTimeLayouts {
+			if t, err := time.Parse(layout, s); nil == err {
+				return t
+			}
+		}
+	}
+	return s
+}
+
+func parseYamlInt(s string) (int, error) {
+	digits := strings.TrimLeft(s, "+-")
+	base := 10
+	if strings.HasPrefix(digits, "0x") {
+		base, digits = 16, digits[2:]
+	} else if strings.HasPrefix(digits, "0o") {
+		base, digits = 8, digits[2:]
+	}
+	if "" == digits || len(s)-len(strings.TrimLeft(s, "+-")) > 1 {
+		return 0, strconv.ErrSyntax
+	}
+	for _, c := range digits {
+		if !('0' <= c && '9' >= c) && !(16 == base && strings.ContainsRune("abcdefABCDEF", c)) {
+			return 0, strconv.ErrSyntax
+		}
+	}
+	i, err := strconv.ParseInt(digits, base, 0)
+	if strings.HasPrefix(s, "-") {
+		i = -i
+	}
+	return int(i), err
+}
+
+// isYamlFloat 判断 s 是否符合 [-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?。
+func isYamlFloat(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	mantissa, exponent := s, ""
+	if i := strings.IndexAny(s, "eE"); 0 <= i {
+		mantissa, exponent = s[:i], strings.TrimLeft(s[i+1:], "+-")
+		if "" == exponent || "" != strings.Trim(exponent, "0123456789") {
+			return false
+		}
+	}
+	parts := strings.SplitN(mantissa, ".", 2)
+	if "" != strings.Trim(parts[0], "0123456789") || (2 == len(parts) && "" != strings.Trim(parts[1], "0123456789")) {
+		return false
+	}
+	return "" != parts[0] || (2 == len(parts) && "" != parts[1])
+}
+
+func yamlKeyString(key interface{}) string {
+	switch k := key.(type) {
+	case nil:
+		return "null"
+	case string:
+		return k
+	}
+	return yamlScalar(key)
+}
+
+// normalizeYaml 将 value 中的切片和映射统一为 []interface{} 和 map[string]interface{}，整数统一为 int，浮点数统一为 float64。
+func normalizeYaml(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, bool, int, float64, string, time.Time:
+		return v
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			ret[key] = normalizeYaml(item)
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for i, item := range v {
+			ret[i] = normalizeYaml(item)
+		}
+		return ret
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(rv.Uint())
+	case reflect.Float32:
+		return rv.Float()
+	case reflect.Slice, reflect.Array:
+		ret := make([]interface{}, rv.Len())
+		for i := range ret {
+			ret[i] = normalizeYaml(rv.Index(i).Interface())
+		}
+		return ret
+	case reflect.Map:
+		ret := make(map[string]interface{}, rv.Len())
+		for _, key := range rv.MapKeys() {
+			ret[yamlKeyString(normalizeYaml(key.Interface()))] = normalizeYaml(rv.MapIndex(key).Interface())
+		}
+		return ret
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return normalizeYaml(rv.Elem().Interface())
+	}
+	return value
+}
+
+// writeYamlMapping 写入映射 m 的键值对，indent 为键的缩进，inline 为 true 时第一个键紧跟在已经写入的 "- " 后面。
+func writeYamlMapping(buf *bytes.Buffer, m map[string]interface{}, indent int, inline bool) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		if !inline || 0 < i {
+			buf.WriteString(strings.Repeat(" ", indent))
+		}
+		buf.WriteString(yamlScalar(key))
+		buf.WriteByte(':')
+		writeYamlValue(buf, m[key], indent)
+	}
+}
+
+// writeYamlValue 写入键或者序列项标记后面的值 value，indent 为键或者序列项的缩进。
+func writeYamlValue(buf *bytes.Buffer, value interface{}, indent int) {
+	switch v := normalizeYaml(value).(type) {
+	case map[string]interface{}:
+		if 1 > len(v) {
+			buf.WriteString(" {}\n")
+			return
+		}
+		buf.WriteByte('\n')
+		writeYamlMapping(buf, v, indent+2, false)
+	case []interface{}:
+		if 1 > len(v) {
+			buf.WriteString(" []\n")
+			return
+		}
+		buf.WriteByte('\n')
+		writeYamlSeq(buf, v, indent+2)
+	default:
+		buf.WriteByte(' ')
+		buf.WriteString(yamlScalar(v))
+		buf.WriteByte('\n')
+	}
+}
+
+func writeYamlSeq(buf *bytes.Buffer, items []interface{}, indent int) {
+	for _, item := range items {
+		buf.WriteString(strings.Repeat(" ", indent))
+		buf.WriteByte('-')
+		if m, ok := item.(map[string]interface{}); ok && 0 < len(m) {
+			buf.WriteByte(' ')
+			writeYamlMapping(buf, m, indent+2, true)
+			continue
+		}
+		writeYamlValue(buf, item, indent)
+	}
+}
+
+// yamlScalar 返回纯量 value 的 YAML 表示，字符串按纯量解码会改变类型或者内容的话使用双引号。
+func yamlScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		switch {
+		case math.IsInf(v, 1):
+			return ".inf"
+		case math.IsInf(v, -1):
+			return "-.inf"
+		case math.IsNaN(v):
+			return ".nan"
+		}
+		ret := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(ret, ".e") {
+			ret += ".0" // 保持浮点数类型
+		}
+		return ret
+	case time.Time:
+		if 0 == v.Hour() && 0 == v.Minute() && 0 == v.Second() && 0 == v.Nanosecond() && time.UTC == v.Location() {
+			return v.Format("2006-01-02")
+		}
+		return v.Format(time.RFC3339Nano)
+	case string:
+		if isYamlPlainSafe(v) {
+			return v
+		}
+		return strconv.Quote(v)
+	}
+	return strconv.Quote(fmt.Sprint(value))
+}
+
+// isYamlPlainSafe 判断字符串 s 是否可以不加引号作为纯量输出。
+func isYamlPlainSafe(s string) bool {
+	if "" == s || s != strings.TrimSpace(s) || strings.ContainsAny(s, "\n\t\r") || strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return false
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return false
+	}
+	resolved, ok := resolveYamlPlain(s).(string)
+	return ok && resolved == s
+}
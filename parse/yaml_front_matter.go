@@ -12,13 +12,23 @@ package parse
 
 import (
 	"bytes"
+	"encoding/json"
+	"unicode"
+
 	"github.com/88250/lute/ast"
 	"github.com/88250/lute/lex"
 	"github.com/88250/lute/util"
 )
 
+// Front Matter 类型。
+const (
+	FrontMatterYaml = iota // YAML，使用 --- 包裹
+	FrontMatterToml        // TOML，使用 +++ 包裹，Hugo 等静态站点生成器使用
+	FrontMatterJSON        // JSON，使用单独成行的 { 和 } 包裹，{ } 是 JSON 内容的一部分
+)
+
 func YamlFrontMatterContinue(node *ast.Node, context *Context) int {
-	if isYamlFrontMatterClose(context) {
+	if isFrontMatterClose(node.FrontMatterType, context) {
 		context.finalize(node, context.lineNum)
 		return 2
 	}
@@ -30,19 +40,80 @@ var YamlFrontMatterMarkerNewline = util.StrToBytes("---\n")
 var YamlFrontMatterMarkerCaret = util.StrToBytes("---" + util.Caret)
 var YamlFrontMatterMarkerCaretNewline = util.StrToBytes("---" + util.Caret + "\n")
 
+var TomlFrontMatterMarker = util.StrToBytes("+++")
+var JSONFrontMatterOpenMarker = util.StrToBytes("{")
+var JSONFrontMatterCloseMarker = util.StrToBytes("}")
+
+// FrontMatterOpenMarker 返回 Front Matter 类型 typ 的开始标记符。
+func FrontMatterOpenMarker(typ int) []byte {
+	switch typ {
+	case FrontMatterToml:
+		return TomlFrontMatterMarker
+	case FrontMatterJSON:
+		return JSONFrontMatterOpenMarker
+	}
+	return YamlFrontMatterMarker
+}
+
+// FrontMatterCloseMarker 返回 Front Matter 类型 typ 的结束标记符。
+func FrontMatterCloseMarker(typ int) []byte {
+	switch typ {
+	case FrontMatterToml:
+		return TomlFrontMatterMarker
+	case FrontMatterJSON:
+		return JSONFrontMatterCloseMarker
+	}
+	return YamlFrontMatterMarker
+}
+
+// FrontMatterLanguage 返回 Front Matter 类型 typ 的内容语言，用于代码高亮。
+func FrontMatterLanguage(typ int) string {
+	switch typ {
+	case FrontMatterToml:
+		return "toml"
+	case FrontMatterJSON:
+		return "json"
+	}
+	return "yaml"
+}
+
+// FrontMatterTypeByMarker 根据标记符 marker 返回 Front Matter 类型，用于从 Vditor DOM 还原节点。
+func FrontMatterTypeByMarker(marker []byte) int {
+	marker = lex.TrimWhitespace(bytes.ReplaceAll(marker, util.CaretTokens, nil))
+	switch {
+	case bytes.Equal(marker, TomlFrontMatterMarker):
+		return FrontMatterToml
+	case bytes.Equal(marker, JSONFrontMatterOpenMarker), bytes.Equal(marker, JSONFrontMatterCloseMarker):
+		return FrontMatterJSON
+	}
+	return FrontMatterYaml
+}
+
 func (context *Context) yamlFrontMatterFinalize(node *ast.Node) {
-	tokens := node.Tokens[3:] // 剔除开头的 ---\n
-	tokens = lex.TrimWhitespace(tokens)
+	open, close := FrontMatterOpenMarker(node.FrontMatterType), FrontMatterCloseMarker(node.FrontMatterType)
+	tokens := node.Tokens[len(open):] // 剔除开头的 ---\n
+	if FrontMatterJSON == node.FrontMatterType {
+		// JSON 的首行通常是缩进的，仅剔除开始标记所在的行
+		if newline := bytes.IndexByte(tokens, lex.ItemNewline); 0 <= newline {
+			tokens = tokens[newline+1:]
+		}
+		tokens = bytes.TrimRightFunc(tokens, unicode.IsSpace)
+	} else {
+		tokens = lex.TrimWhitespace(tokens)
+	}
 	if context.Option.VditorWYSIWYG || context.Option.VditorIR || context.Option.VditorSV {
-		if bytes.HasSuffix(tokens, YamlFrontMatterMarkerCaret) {
+		if closeCaret := append(append([]byte{}, close...), util.CaretTokens...); bytes.HasSuffix(tokens, closeCaret) {
 			// 剔除结尾的 ---‸
-			tokens = bytes.TrimSuffix(tokens, YamlFrontMatterMarkerCaret)
+			tokens = bytes.TrimSuffix(tokens, closeCaret)
 			// 把 Vditor 插入符移动到内容末尾
 			tokens = append(tokens, util.CaretTokens...)
 		}
 	}
-	if bytes.HasSuffix(tokens, YamlFrontMatterMarker) {
-		tokens = tokens[:len(tokens)-3] // 剔除结尾的 ---
+	if bytes.HasSuffix(tokens, close) {
+		tokens = tokens[:len(tokens)-len(close)] // 剔除结尾的 ---
+	}
+	if FrontMatterJSON == node.FrontMatterType {
+		tokens = bytes.TrimRightFunc(tokens, unicode.IsSpace)
 	}
 	node.Tokens = tokens
 	node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterOpenMarker})
@@ -50,26 +121,153 @@ func (context *Context) yamlFrontMatterFinalize(node *ast.Node) {
 	node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterCloseMarker})
 }
 
-func (t *Tree) parseYamlFrontMatter() bool {
-	if lex.ItemHyphen != t.Context.currentLine[0] {
-		return false
+// parseFrontMatter 判断当前行是否开始 Front Matter，是的话返回 Front Matter 类型，否则返回 -1。
+func (t *Tree) parseFrontMatter() int {
+	line := t.Context.currentLine
+	switch line[0] {
+	case lex.ItemHyphen:
+		if t.Context.Option.YamlFrontMatter && 3 == markerLength(line, lex.ItemHyphen) {
+			return FrontMatterYaml
+		}
+	case lex.ItemPlus:
+		if t.Context.Option.TomlFrontMatter && 3 == markerLength(line, lex.ItemPlus) && t.frontMatterClosed(FrontMatterToml) {
+			return FrontMatterToml
+		}
+	case lex.ItemOpenCurlyBrace:
+		if t.Context.Option.JSONFrontMatter && isJSONFrontMatterMarker(line, lex.ItemOpenCurlyBrace) && t.frontMatterClosed(FrontMatterJSON) {
+			return FrontMatterJSON
+		}
+	}
+	return -1
+}
+
+// frontMatterClosed 判断后续是否存在类型为 typ 的 Front Matter 结束行，并且开始和结束行之间的内容是合法的 TOML 或者 JSON。
+// TOML 和 JSON 的开始标记会和普通段落冲突，不满足条件的话不能作为 Front Matter，否则会吞掉正文。遇到代码块围栏时停止查找。
+func (t *Tree) frontMatterClosed(typ int) bool {
+	var body [][]byte
+	for _, line := range bytes.Split(t.lexer.Remains(), []byte{lex.ItemNewline}) {
+		if isFrontMatterCloseLine(typ, line) {
+			return validFrontMatter(typ, bytes.ReplaceAll(bytes.Join(body, []byte{lex.ItemNewline}), util.CaretTokens, nil))
+		}
+		if isCodeFenceLine(line) {
+			return false
+		}
+		body = append(body, line)
 	}
+	return false
+}
+
+// validFrontMatter 判断类型为 typ 的 Front Matter 内容 body（不包括开始和结束行）是否合法。
+func validFrontMatter(typ int, body []byte) bool {
+	if FrontMatterJSON == typ {
+		return json.Valid(append(append([]byte{lex.ItemOpenCurlyBrace, lex.ItemNewline}, body...), lex.ItemNewline, lex.ItemCloseCurlyBrace))
+	}
+	_, err := DecodeToml(body)
+	return nil == err
+}
+
+// isCodeFenceLine 判断 line 是否是代码块围栏 ``` 或者 ~~~。
+func isCodeFenceLine(line []byte) bool {
+	indent := 0
+	for ; indent < len(line) && 3 > indent && lex.ItemSpace == line[indent]; indent++ {
+	}
+	line = line[indent:]
+	return bytes.HasPrefix(line, []byte("```")) || bytes.HasPrefix(line, []byte("~~~"))
+}
 
-	hyphenLength := 0
-	for i := 0; i < t.Context.currentLineLen && lex.ItemHyphen == t.Context.currentLine[i]; i++ {
-		hyphenLength++
+func isFrontMatterClose(typ int, context *Context) bool {
+	return isFrontMatterCloseLine(typ, context.currentLine)
+}
+
+func isFrontMatterCloseLine(typ int, line []byte) bool {
+	switch typ {
+	case FrontMatterToml:
+		return 3 == markerLength(line, lex.ItemPlus)
+	case FrontMatterJSON:
+		return isJSONFrontMatterMarker(line, lex.ItemCloseCurlyBrace)
 	}
-	return 3 == hyphenLength
+	return 3 == markerLength(line, lex.ItemHyphen)
 }
 
-func isYamlFrontMatterClose(context *Context) bool {
-	if lex.ItemHyphen != context.currentLine[0] {
+// markerLength 返回 line 开头连续的 marker 字符个数。
+func markerLength(line []byte, marker byte) (ret int) {
+	for ; ret < len(line) && marker == line[ret]; ret++ {
+	}
+	return
+}
+
+// isJSONFrontMatterMarker 判断 line 是否是单独成行的 JSON Front Matter 标记符 brace，标记符后可以跟 Vditor 插入符。
+func isJSONFrontMatterMarker(line []byte, brace byte) bool {
+	if 1 > len(line) || brace != line[0] {
 		return false
 	}
+	remains := bytes.TrimPrefix(line[1:], util.CaretTokens)
+	return 0 == len(bytes.Trim(remains, " \t\r\n"))
+}
+
+// decodeFrontMatter 解码 YAML Front Matter 并保存到 FrontMatter 上，解码失败的话记录诊断信息。
+func (t *Tree) decodeFrontMatter() {
+	frontMatter := t.Root.FirstChild
+	if nil == frontMatter || ast.NodeYamlFrontMatter != frontMatter.Type || FrontMatterYaml != frontMatter.FrontMatterType {
+		return
+	}
+	content := frontMatter.ChildByType(ast.NodeYamlFrontMatterContent)
+	if nil == content {
+		return
+	}
+
+	meta, err := DecodeYaml(content.Tokens)
+	if nil == err {
+		t.FrontMatter = meta
+		return
+	}
+
+	diagnostic := &Diagnostic{Severity: SeverityError, Code: RuleInvalidYamlFrontMatter, Message: "invalid YAML front matter: " + err.Error(), node: frontMatter}
+	// 内容在原文中的位置已知的话定位到出错的行
+	if yamlErr, ok := err.(*YamlError); ok && nil != t.lexer {
+		if start := bytes.Index(t.Source[frontMatter.StartPos.Offset:], content.Tokens); 0 <= start {
+			line, _ := t.lexer.Position(frontMatter.StartPos.Offset + start)
+			if line += yamlErr.Line - 1; line <= t.lexer.Lines() {
+				diagnostic.node = nil
+				diagnostic.Message = "invalid YAML front matter: " + yamlErr.Message
+				diagnostic.StartPos = ast.Position{Line: line, Column: 1, Offset: t.lexer.LineStart(line)}
+				diagnostic.EndPos = t.Context.lineEndPos(line)
+			}
+		}
+	}
+	t.Diagnostics = append(t.Diagnostics, diagnostic)
+}
 
-	hyphenLength := 0
-	for i := 0; i < context.currentLineLen && lex.ItemHyphen == context.currentLine[i]; i++ {
-		hyphenLength++
+// SetFrontMatter 将 YAML Front Matter 更新为 meta，meta 为空的话移除 Front Matter，格式化渲染时输出更新后的内容。
+//
+// 更新时会尽量保留原文：值没有变化的键保持原有的写法和注释，变化的键重新编码，新增的键按字典序追加到末尾。
+// TOML 和 JSON Front Matter 不会解码，更新时整体替换为 YAML Front Matter。
+func (t *Tree) SetFrontMatter(meta map[string]interface{}) {
+	frontMatter := t.Root.FirstChild
+	if nil != frontMatter && ast.NodeYamlFrontMatter != frontMatter.Type {
+		frontMatter = nil
+	}
+	if 1 > len(meta) {
+		if nil != frontMatter {
+			frontMatter.Unlink()
+		}
+		t.FrontMatter = nil
+		return
+	}
+
+	if nil == frontMatter {
+		frontMatter = &ast.Node{Type: ast.NodeYamlFrontMatter}
+		frontMatter.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterOpenMarker})
+		frontMatter.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterContent})
+		frontMatter.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterCloseMarker})
+		t.Root.PrependChild(frontMatter)
+	}
+	content := frontMatter.ChildByType(ast.NodeYamlFrontMatterContent)
+	if FrontMatterYaml != frontMatter.FrontMatterType {
+		frontMatter.FrontMatterType = FrontMatterYaml
+		content.Tokens = nil
 	}
-	return 3 == hyphenLength
+	content.Tokens = mergeYaml(content.Tokens, meta)
+	frontMatter.Tokens = content.Tokens
+	t.FrontMatter = meta
 }
@@ -11,6 +11,7 @@
 package render
 
 import (
+	"bytes"
 	"unicode"
 	"unicode/utf8"
 
@@ -25,29 +26,33 @@ func (r *BaseRenderer) ChinesePunct(textNode *ast.Node) {
 	textNode.Tokens = util.StrToBytes(text)
 }
 
-func chinesePunct0(text string) (ret string) {
+func chinesePunct0(text string) string {
 	runes := []rune(text)
 	length := len(runes)
+	buf := &bytes.Buffer{}
+	buf.Grow(len(text))
 	for i, r := range runes {
 		if ('.' == r || '!' == r || '?' == r) && i+1 < length {
 			if '.' == runes[i+1] || '!' == runes[i+1] || '?' == runes[i+1] {
 				// 连续英文标点符号出现在中文后不优化
-				ret += string(r)
+				buf.WriteRune(r)
 				continue
 			} else if isFileExt(i+1, length, &runes) {
 				// 中文.合法扩展名 的形式不进行转换
-				ret += string(r)
+				buf.WriteRune(r)
 				continue
 			}
 		}
-		ret = chinesePunct00(ret, r)
+		chinesePunct00(buf, r)
 	}
-	return
+	return buf.String()
 }
 
-func chinesePunct00(prefix string, nextChar rune) string {
-	if 0 == len(prefix) {
-		return string(nextChar)
+// chinesePunct00 将字符 nextChar 写入 buf，需要的话将 buf 末尾的英文逗号或者 nextChar 换成中文标点。
+func chinesePunct00(buf *bytes.Buffer, nextChar rune) {
+	if 0 == buf.Len() {
+		buf.WriteRune(nextChar)
+		return
 	}
 
 	nextCharIsEnglishComma := ',' == nextChar
@@ -56,30 +61,36 @@ func chinesePunct00(prefix string, nextChar rune) string {
 	nextCharIsEnglishBang := '!' == nextChar
 	nextCharIsEnglishQuestion := '?' == nextChar
 
-	currentChar, size := utf8.DecodeLastRuneInString(prefix)
+	currentChar, size := utf8.DecodeLastRune(buf.Bytes())
 	if 1 == size && (',' == currentChar) && unicode.Is(unicode.Han, nextChar) {
 		// test,测试 => test，测试
-		return prefix[:len(prefix)-1] + "，" + string(nextChar)
+		buf.Truncate(buf.Len() - 1)
+		buf.WriteString("，")
+		buf.WriteRune(nextChar)
+		return
 	}
 
 	if !nextCharIsEnglishComma && !nextCharIsEnglishPeriod && !nextCharIsEnglishColon && !nextCharIsEnglishBang && !nextCharIsEnglishQuestion {
-		return prefix + string(nextChar)
+		buf.WriteRune(nextChar)
+		return
 	}
 
 	if !unicode.Is(unicode.Han, currentChar) {
-		return prefix + string(nextChar)
+		buf.WriteRune(nextChar)
+		return
 	}
 
 	if nextCharIsEnglishComma {
-		return prefix + "，"
+		buf.WriteString("，")
 	} else if nextCharIsEnglishPeriod {
-		return prefix + "。"
+		buf.WriteString("。")
 	} else if nextCharIsEnglishColon {
-		return prefix + "："
+		buf.WriteString("：")
 	} else if nextCharIsEnglishBang {
-		return prefix + "！"
+		buf.WriteString("！")
 	} else if nextCharIsEnglishQuestion {
-		return prefix + "？"
+		buf.WriteString("？")
+	} else {
+		buf.WriteRune(nextChar)
 	}
-	return prefix + string(nextChar)
 }
@@ -37,6 +37,10 @@ func NewEChartsJSONRenderer(tree *parse.Tree) Renderer {
 	ret.RendererFuncs[ast.NodeEmphasis] = ret.renderEmphasis
 	ret.RendererFuncs[ast.NodeStrong] = ret.renderStrong
 	ret.RendererFuncs[ast.NodeBlockquote] = ret.renderBlockquote
+	ret.RendererFuncs[ast.NodeAdmonition] = ret.renderAdmonition
+	ret.RendererFuncs[ast.NodeDefinitionList] = ret.renderDefinitionList
+	ret.RendererFuncs[ast.NodeDefinitionTerm] = ret.renderDefinitionTerm
+	ret.RendererFuncs[ast.NodeDefinitionDescription] = ret.renderDefinitionDescription
 	ret.RendererFuncs[ast.NodeHeading] = ret.renderHeading
 	ret.RendererFuncs[ast.NodeList] = ret.renderList
 	ret.RendererFuncs[ast.NodeListItem] = ret.renderListItem
@@ -69,6 +73,7 @@ func NewEChartsJSONRenderer(tree *parse.Tree) Renderer {
 	ret.RendererFuncs[ast.NodeKramdownBlockIAL] = ret.renderKramdownBlockIAL
 	ret.RendererFuncs[ast.NodeBlockEmbed] = ret.renderBlockEmbed
 	ret.RendererFuncs[ast.NodeBlockQueryEmbed] = ret.renderBlockQueryEmbed
+	ret.RendererFuncs[ast.NodeLinkRefDefBlock] = ret.renderLinkRefDefBlock
 	ret.DefaultRendererFunc = ret.renderDefault
 	return ret
 }
@@ -86,6 +91,10 @@ func (r *EChartsJSONRenderer) renderMark(node *ast.Node, entering bool) ast.Walk
 	return ast.WalkStop
 }
 
+func (r *EChartsJSONRenderer) renderLinkRefDefBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkSkipChildren // 链接引用定义不进行渲染
+}
+
 func (r *EChartsJSONRenderer) renderBlockQueryEmbed(node *ast.Node, entering bool) ast.WalkStatus {
 	r.leaf("BlockQueryEmbed\n!{{script}}", node)
 	return ast.WalkStop
@@ -106,7 +115,7 @@ func (r *EChartsJSONRenderer) renderDefault(n *ast.Node, entering bool) ast.Walk
 }
 
 func (r *EChartsJSONRenderer) renderYamlFrontMatter(node *ast.Node, entering bool) ast.WalkStatus {
-	r.leaf("Front Matter\nYAML", node)
+	r.leaf("Front Matter\n"+strings.ToUpper(parse.FrontMatterLanguage(node.FrontMatterType)), node)
 	return ast.WalkStop
 }
 
@@ -308,6 +317,54 @@ func (r *EChartsJSONRenderer) renderStrong(node *ast.Node, entering bool) ast.Wa
 	return ast.WalkContinue
 }
 
+func (r *EChartsJSONRenderer) renderAdmonition(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.openObj()
+		r.val("Admonition\ndiv", node)
+		r.openChildren(node)
+	} else {
+		r.closeChildren(node)
+		r.closeObj(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *EChartsJSONRenderer) renderDefinitionList(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.openObj()
+		r.val("Definition List\ndl", node)
+		r.openChildren(node)
+	} else {
+		r.closeChildren(node)
+		r.closeObj(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *EChartsJSONRenderer) renderDefinitionTerm(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.openObj()
+		r.val("Definition Term\ndt", node)
+		r.openChildren(node)
+	} else {
+		r.closeChildren(node)
+		r.closeObj(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *EChartsJSONRenderer) renderDefinitionDescription(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.openObj()
+		r.val("Definition Description\ndd", node)
+		r.openChildren(node)
+	} else {
+		r.closeChildren(node)
+		r.closeObj(node)
+	}
+	return ast.WalkContinue
+}
+
 func (r *EChartsJSONRenderer) renderBlockquote(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.openObj()
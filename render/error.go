@@ -0,0 +1,38 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/util"
+)
+
+// RenderError 描述了渲染时发生 panic 导致的错误。
+type RenderError struct {
+	NodeType ast.NodeType // 发生 panic 时正在渲染的节点类型
+	Value    interface{}  // panic 值
+	Stack    string       // 发生 panic 时的调用栈
+}
+
+func (e *RenderError) Error() string {
+	return "render [" + e.NodeType.String() + "] failed: " + util.PanicMessage(e.Value)
+}
+
+// Recover 用于 defer 调用，恢复渲染时发生的 panic 并将其转换为 *RenderError 保存到 err 中。
+func (r *BaseRenderer) Recover(err *error) {
+	if e := recover(); nil != e {
+		renderErr := &RenderError{NodeType: r.Tree.Root.Type, Value: e, Stack: util.PanicStack()}
+		if nil != r.rendering {
+			renderErr.NodeType = r.rendering.Type
+		}
+		*err = renderErr
+	}
+}
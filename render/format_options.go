@@ -0,0 +1,358 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/lex"
+	"github.com/88250/lute/parse"
+)
+
+// FormatOptions 描述了格式化渲染器的输出风格配置，零值表示保留原文的写法。
+type FormatOptions struct {
+	// BulletChar 无序列表标记符，- * 或者 +，相邻的两个列表会交替使用另一个标记符以免被合并。
+	BulletChar byte
+	// EmphasisMarker 强调标记符，* 或者 _，单词内部的强调只能使用 *。
+	EmphasisMarker byte
+	// StrongMarker 加粗标记符，* 或者 _，单词内部的加粗只能使用 *。
+	StrongMarker byte
+	// HeadingStyle 标题风格，0：保留原文，1：ATX # 标题，2：Setext 标题（仅对一、二级标题生效）。
+	HeadingStyle int
+	// OrderedListNumbering 有序列表序号，0：递增，1：全部使用列表起始序号，比如 1. 1. 1.
+	OrderedListNumbering int
+	// FenceChar 代码块围栏标记符，` 或者 ~。
+	FenceChar byte
+	// FenceLen 代码块围栏长度，代码内容中包含更长的围栏时会自动加长。
+	FenceLen int
+	// HardWrapColumn 段落折行的列宽，超过该宽度的行会被折行，为 0 时不折行。
+	// 开启 SoftBreak2HardBreak（引擎默认开启）时软换行会渲染为硬换行，为了不改变渲染结果此时不会折行。
+	HardWrapColumn int
+	// Reflow 设置是否重排段落，合并段落中的软换行后再按 HardWrapColumn 折行，和 HardWrapColumn 一样在开启 SoftBreak2HardBreak 时不生效。
+	Reflow bool
+	// TableAlignPadding 设置是否使用空格填充表格单元格以对齐各列。
+	TableAlignPadding bool
+}
+
+// NewFormatOptions 创建默认的格式化风格配置，和原有的格式化输出保持一致。
+func NewFormatOptions() *FormatOptions {
+	return &FormatOptions{TableAlignPadding: true}
+}
+
+// NewPrettierFormatOptions 创建和 Prettier 的 Markdown 格式化风格一致的配置。
+func NewPrettierFormatOptions() *FormatOptions {
+	return &FormatOptions{
+		BulletChar:        lex.ItemHyphen,
+		EmphasisMarker:    lex.ItemUnderscore,
+		StrongMarker:      lex.ItemAsterisk,
+		HeadingStyle:      1,
+		FenceChar:         lex.ItemBacktick,
+		FenceLen:          3,
+		TableAlignPadding: true,
+	}
+}
+
+// bulletChar 返回无序列表 list 使用的标记符，original 为原文标记符。
+func (r *FormatRenderer) bulletChar(list *ast.Node, original byte) byte {
+	bullet := r.FormatOptions.BulletChar
+	if 0 == bullet {
+		return original
+	}
+
+	// 紧邻的无序列表如果使用相同的标记符会被解析为同一个列表，所以需要交替使用
+	alternate := false
+	for prev := list.Previous; nil != prev && ast.NodeList == prev.Type && nil != prev.ListData && 0 != prev.BulletChar; prev = prev.Previous {
+		alternate = !alternate
+	}
+	if alternate {
+		if lex.ItemHyphen == bullet {
+			return lex.ItemAsterisk
+		}
+		return lex.ItemHyphen
+	}
+	return bullet
+}
+
+// listItemMarker 返回列表项 item 格式化后的标记符，有序列表包含分隔符。
+func (r *FormatRenderer) listItemMarker(item *ast.Node) []byte {
+	if 1 == item.ListData.Typ || (3 == item.ListData.Typ && 0 == item.ListData.BulletChar) {
+		num := item.Num
+		if 1 == r.FormatOptions.OrderedListNumbering && nil != item.Parent && nil != item.Parent.ListData {
+			num = item.Parent.Start
+		}
+		return append([]byte(strconv.Itoa(num)), item.ListData.Delimiter)
+	}
+	if 1 != len(item.Marker) || nil == item.Parent {
+		return item.Marker
+	}
+	return []byte{r.bulletChar(item.Parent, item.Marker[0])}
+}
+
+// emphasisMarker 返回强调或者加粗节点 node 使用的标记符，preferred 为配置的标记符，original 为原文标记符。
+// 节点前后紧邻字母或者数字时 _ 无法生效，这时仍然使用 *。换用 preferred 后会和紧邻的强调、加粗标记符或者文本中的同一字符连成一串，
+// 或者节点内容中出现了 preferred（比如 *foo _bar* baz_）时，解析结果可能会发生变化，这时也使用原文标记符。
+func (r *FormatRenderer) emphasisMarker(node *ast.Node, preferred, original byte) byte {
+	if 0 == preferred || preferred == original {
+		return original
+	}
+	if lex.ItemUnderscore == preferred && (isWordText(node.Previous, false) || isWordText(node.Next, true)) {
+		return original
+	}
+	if preferred == textEdge(node.Previous, false) || preferred == textEdge(node.Next, true) ||
+		preferred == textEdge(emphasisContent(node, true), true) || preferred == textEdge(emphasisContent(node, false), false) {
+		return original
+	}
+	for _, n := range adjacentEmphasis(node) {
+		if preferred == emphasisOriginal(n) || preferred == r.emphasisPreferred(n) {
+			return original
+		}
+	}
+	if r.containsEmphasisMarker(node, preferred) {
+		return original
+	}
+	return preferred
+}
+
+// containsEmphasisMarker 判断强调或者加粗节点 node 的内容中是否出现了标记符 marker，包括文本中没有转义的 marker
+// 以及原文或者配置使用 marker 的内层强调、加粗节点。
+func (r *FormatRenderer) containsEmphasisMarker(node *ast.Node, marker byte) (ret bool) {
+	for c := emphasisContent(node, true); nil != c && c != node.LastChild; c = c.Next {
+		ast.Walk(c, func(n *ast.Node, entering bool) ast.WalkStatus {
+			if !entering {
+				return ast.WalkContinue
+			}
+			if (ast.NodeText == n.Type && 0 <= bytes.IndexByte(n.Tokens, marker)) ||
+				(isEmphasis(n) && (marker == emphasisOriginal(n) || marker == r.emphasisPreferred(n))) {
+				ret = true
+				return ast.WalkStop
+			}
+			return ast.WalkContinue
+		})
+		if ret {
+			return
+		}
+	}
+	return
+}
+
+// emphasisPreferred 返回强调或者加粗节点 n 配置的标记符。
+func (r *FormatRenderer) emphasisPreferred(n *ast.Node) byte {
+	if ast.NodeEmphasis == n.Type {
+		return r.FormatOptions.EmphasisMarker
+	}
+	return r.FormatOptions.StrongMarker
+}
+
+// emphasisOriginal 返回强调或者加粗节点 n 的原文标记符。
+func emphasisOriginal(n *ast.Node) byte {
+	if nil != n.FirstChild && (ast.NodeEmA6kOpenMarker == n.FirstChild.Type || ast.NodeStrongA6kOpenMarker == n.FirstChild.Type) {
+		return lex.ItemAsterisk
+	}
+	return lex.ItemUnderscore
+}
+
+func isEmphasis(n *ast.Node) bool {
+	return nil != n && (ast.NodeEmphasis == n.Type || ast.NodeStrong == n.Type)
+}
+
+// emphasisContent 返回强调或者加粗节点 n 中紧邻开始标记符（head 为 true 时）或者结束标记符的内容节点。
+func emphasisContent(n *ast.Node, head bool) (ret *ast.Node) {
+	if nil == n.FirstChild {
+		return nil
+	}
+	if head {
+		ret = n.FirstChild.Next
+	} else {
+		ret = n.LastChild.Previous
+	}
+	if ret == n.FirstChild || ret == n.LastChild {
+		return nil
+	}
+	return
+}
+
+// adjacentEmphasis 返回标记符和节点 node 的标记符紧邻的强调和加粗节点，包括首尾嵌套的内层节点、外层节点以及前后的兄弟节点。
+func adjacentEmphasis(node *ast.Node) (ret []*ast.Node) {
+	for _, head := range []bool{true, false} {
+		for n := emphasisContent(node, head); isEmphasis(n); n = emphasisContent(n, head) {
+			ret = append(ret, n)
+		}
+		for n := node; isEmphasis(n.Parent) && n == emphasisContent(n.Parent, head); n = n.Parent {
+			ret = append(ret, n.Parent)
+		}
+	}
+	if isEmphasis(node.Previous) {
+		ret = append(ret, node.Previous)
+	}
+	if isEmphasis(node.Next) {
+		ret = append(ret, node.Next)
+	}
+	return
+}
+
+// textEdge 返回文本节点 n 开头（head 为 true 时）或者结尾的字节，n 不是文本节点时返回 0。
+func textEdge(n *ast.Node, head bool) byte {
+	if nil == n || ast.NodeText != n.Type || 1 > len(n.Tokens) {
+		return 0
+	}
+	if head {
+		return n.Tokens[0]
+	}
+	return n.Tokens[len(n.Tokens)-1]
+}
+
+// isWordText 判断文本节点 n 紧邻强调节点的一端是否是字母或者数字，head 为 true 时判断开头，否则判断结尾。
+func isWordText(n *ast.Node, head bool) bool {
+	if nil == n || ast.NodeText != n.Type || 1 > len(n.Tokens) {
+		return false
+	}
+
+	var r rune
+	if head {
+		r, _ = utf8.DecodeRune(n.Tokens)
+	} else {
+		r, _ = utf8.DecodeLastRune(n.Tokens)
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// headingSetext 判断标题 heading 是否按 Setext 风格输出。
+func (r *FormatRenderer) headingSetext(heading *ast.Node) bool {
+	switch r.FormatOptions.HeadingStyle {
+	case 1:
+		// 多行的 Setext 标题无法转换为 ATX 标题
+		return heading.HeadingSetext && multilineHeading(heading)
+	case 2:
+		// 没有开启 Setext 标题解析或者标题位于块引用中时，转换后的标题会被解析为段落；空标题使用 Setext 风格的话会变为分隔线
+		if !r.Option.Setext || heading.ParentIs(ast.NodeBlockquote, ast.NodeAdmonition) {
+			return heading.HeadingSetext
+		}
+		return 2 >= heading.HeadingLevel && !emptyHeading(heading)
+	}
+	return heading.HeadingSetext
+}
+
+// emptyHeading 判断标题 heading 是否没有内容或者只有空白，标记符和标题 ID 不算内容。
+func emptyHeading(heading *ast.Node) bool {
+	for n := heading.FirstChild; nil != n; n = n.Next {
+		if ast.NodeHeadingC8hMarker == n.Type || ast.NodeHeadingID == n.Type {
+			continue
+		}
+		if ast.NodeText != n.Type || 0 < len(bytes.TrimSpace(n.Tokens)) {
+			return false
+		}
+	}
+	return true
+}
+
+// multilineHeading 判断标题 heading 中是否包含换行，换行可能位于强调等行级节点中。
+func multilineHeading(heading *ast.Node) (ret bool) {
+	ast.Walk(heading, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if entering && (ast.NodeSoftBreak == n.Type || ast.NodeHardBreak == n.Type) {
+			ret = true
+			return ast.WalkStop
+		}
+		return ast.WalkContinue
+	})
+	return
+}
+
+// codeBlockFence 返回代码块 codeBlock 格式化后的围栏，返回 nil 的话说明使用原文围栏。
+func (r *FormatRenderer) codeBlockFence(codeBlock *ast.Node) []byte {
+	char, length := r.FormatOptions.FenceChar, r.FormatOptions.FenceLen
+	if 0 == char && 0 == length {
+		if codeBlock.IsFencedCodeBlock {
+			return nil
+		}
+		char = lex.ItemBacktick
+	}
+	if 0 == char {
+		char = codeBlock.CodeBlockFenceChar
+	}
+	if 3 > length {
+		length = 3
+	}
+	if lex.ItemBacktick == char && 0 <= bytes.IndexByte(codeBlock.CodeBlockInfo, lex.ItemBacktick) {
+		char = lex.ItemTilde // 信息字符串中包含 ` 的话只能使用 ~ 围栏
+	}
+
+	// 代码内容中的围栏行不能提前结束代码块
+	if code := codeBlock.ChildByType(ast.NodeCodeBlockCode); nil != code {
+		for _, line := range bytes.Split(code.Tokens, []byte{lex.ItemNewline}) {
+			line = bytes.TrimLeft(line, " ")
+			run := 0
+			for run < len(line) && char == line[run] {
+				run++
+			}
+			if length <= run {
+				length = run + 1
+			}
+		}
+	}
+	return bytes.Repeat([]byte{char}, length)
+}
+
+// tableCellPadding 返回表格单元格 cell 需要填充的空格数，关闭对齐填充时返回 0。
+func (r *FormatRenderer) tableCellPadding(cell *ast.Node) int {
+	if !r.FormatOptions.TableAlignPadding {
+		return 0
+	}
+	if padding := cell.TableCellContentMaxWidth - cell.TableCellContentWidth; 0 < padding {
+		return padding
+	}
+	return 0
+}
+
+// measureTable 计算不是解析得到的扩展表格 table（比如由 HTML 转换得到，包括补全的 ^^ 占位单元格）中各单元格格式化后的内容宽度和每列的宽度，
+// 以便像解析得到的表格一样对齐填充。
+func (r *FormatRenderer) measureTable(table *ast.Node) {
+	writer, lastOut := r.Writer, r.LastOut
+	var cells [][]*ast.Node
+	measureRow := func(row *ast.Node) {
+		r.tableCells = nil
+		var rowCells []*ast.Node
+		for cell := row.FirstChild; nil != cell; cell = cell.Next {
+			ast.Walk(cell, r.renderNode)
+			cell.TableCellContentWidth = 0
+			for _, line := range bytes.Split(r.tableCells[len(rowCells)], []byte{lex.ItemNewline}) {
+				if cell.TableCellContentWidth < len(line) {
+					cell.TableCellContentWidth = len(line)
+				}
+			}
+			rowCells = append(rowCells, cell)
+		}
+		cells = append(cells, rowCells)
+	}
+	for c := table.FirstChild; nil != c; c = c.Next {
+		switch c.Type {
+		case ast.NodeTableHead:
+			for row := c.FirstChild; nil != row; row = row.Next {
+				measureRow(row)
+			}
+		case ast.NodeTableRow:
+			measureRow(c)
+		}
+	}
+	r.Writer, r.LastOut, r.tableCells = writer, lastOut, nil
+	table.TableColumnWidths = parse.TableColumnWidths(cells)
+}
+
+// tableColumnWidth 返回表格 table 第 col 列的宽度，关闭对齐填充时返回 0。
+func (r *FormatRenderer) tableColumnWidth(table *ast.Node, col int) int {
+	if !r.FormatOptions.TableAlignPadding || col >= len(table.TableColumnWidths) {
+		return 0
+	}
+	return table.TableColumnWidths[col]
+}
@@ -12,7 +12,8 @@ package render
 
 import (
 	"bytes"
-	"strconv"
+	"io"
+	"sort"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -27,11 +28,15 @@ import (
 type FormatRenderer struct {
 	*BaseRenderer
 	NodeWriterStack []*bytes.Buffer // 节点输出缓冲栈
+	stream          *trimWriter     // 流式输出，仅在 RenderTo 时使用
+	tableCells      [][]byte        // 扩展表格当前行中各单元格格式化后的内容
+	FormatOptions   *FormatOptions  // 输出风格配置
 }
 
 // NewFormatRenderer 创建一个格式化渲染器。
 func NewFormatRenderer(tree *parse.Tree) *FormatRenderer {
-	ret := &FormatRenderer{BaseRenderer: NewBaseRenderer(tree)}
+	ret := &FormatRenderer{BaseRenderer: NewBaseRenderer(tree), FormatOptions: NewFormatOptions()}
+	ret.DefaultRendererFunc = ret.renderSource
 	ret.RendererFuncs[ast.NodeDocument] = ret.renderDocument
 	ret.RendererFuncs[ast.NodeParagraph] = ret.renderParagraph
 	ret.RendererFuncs[ast.NodeText] = ret.renderText
@@ -64,6 +69,10 @@ func NewFormatRenderer(tree *parse.Tree) *FormatRenderer {
 	ret.RendererFuncs[ast.NodeStrongU8eCloseMarker] = ret.renderStrongU8eCloseMarker
 	ret.RendererFuncs[ast.NodeBlockquote] = ret.renderBlockquote
 	ret.RendererFuncs[ast.NodeBlockquoteMarker] = ret.renderBlockquoteMarker
+	ret.RendererFuncs[ast.NodeAdmonition] = ret.renderAdmonition
+	ret.RendererFuncs[ast.NodeDefinitionList] = ret.renderDefinitionList
+	ret.RendererFuncs[ast.NodeDefinitionTerm] = ret.renderDefinitionTerm
+	ret.RendererFuncs[ast.NodeDefinitionDescription] = ret.renderDefinitionDescription
 	ret.RendererFuncs[ast.NodeHeading] = ret.renderHeading
 	ret.RendererFuncs[ast.NodeHeadingC8hMarker] = ret.renderHeadingC8hMarker
 	ret.RendererFuncs[ast.NodeHeadingID] = ret.renderHeadingID
@@ -78,6 +87,8 @@ func NewFormatRenderer(tree *parse.Tree) *FormatRenderer {
 	ret.RendererFuncs[ast.NodeImage] = ret.renderImage
 	ret.RendererFuncs[ast.NodeBang] = ret.renderBang
 	ret.RendererFuncs[ast.NodeOpenBracket] = ret.renderOpenBracket
+	ret.RendererFuncs[ast.NodeOpenBrace] = ret.renderOpenBrace
+	ret.RendererFuncs[ast.NodeCloseBrace] = ret.renderCloseBrace
 	ret.RendererFuncs[ast.NodeCloseBracket] = ret.renderCloseBracket
 	ret.RendererFuncs[ast.NodeOpenParen] = ret.renderOpenParen
 	ret.RendererFuncs[ast.NodeCloseParen] = ret.renderCloseParen
@@ -121,6 +132,8 @@ func NewFormatRenderer(tree *parse.Tree) *FormatRenderer {
 	ret.RendererFuncs[ast.NodeKramdownBlockIAL] = ret.renderKramdownBlockIAL
 	ret.RendererFuncs[ast.NodeBlockQueryEmbed] = ret.renderBlockQueryEmbed
 	ret.RendererFuncs[ast.NodeBlockQueryEmbedScript] = ret.renderBlockQueryEmbedScript
+	ret.RendererFuncs[ast.NodeLinkRefDefBlock] = ret.renderLinkRefDefBlock
+	ret.RendererFuncs[ast.NodeLinkRefDef] = ret.renderLinkRefDef
 	ret.RendererFuncs[ast.NodeBlockEmbed] = ret.renderBlockEmbed
 	ret.RendererFuncs[ast.NodeBlockEmbedID] = ret.renderBlockEmbedID
 	ret.RendererFuncs[ast.NodeBlockEmbedSpace] = ret.renderBlockEmbedSpace
@@ -173,22 +186,114 @@ func (r *FormatRenderer) renderKramdownBlockIAL(node *ast.Node, entering bool) a
 
 func (r *FormatRenderer) Render() (output []byte) {
 	output = r.BaseRenderer.Render()
-	if 1 > len(r.Tree.Context.LinkRefDefs) {
+	if 1 > len(r.Tree.Context.LinkRefDefs) || r.Option.KeepLinkRefDefs || nil != r.err {
 		return
 	}
 
+	output = append(output, r.renderLinkRefDefs()...)
+	return
+}
+
+// RenderTo 渲染输出到 w，文档首尾的空白会像 Render 一样被去掉。
+func (r *FormatRenderer) RenderTo(w io.Writer) (err error) {
+	r.stream = &trimWriter{w: w}
+	defer func() { r.stream = nil }()
+	if err = r.BaseRenderer.RenderTo(r.stream); nil != err {
+		return
+	}
+	if _, err = w.Write([]byte{lex.ItemNewline}); nil != err {
+		return
+	}
+	if 1 > len(r.Tree.Context.LinkRefDefs) || r.Option.KeepLinkRefDefs {
+		return
+	}
+
+	_, err = w.Write(r.renderLinkRefDefs())
+	return
+}
+
+func (r *FormatRenderer) renderLinkRefDefs() []byte {
 	buf := &bytes.Buffer{}
 	buf.WriteByte(lex.ItemNewline)
-	// 将链接引用定义添加到末尾
+	// 将链接引用定义按原文顺序添加到末尾，没有源码位置的话按 label 排序，保证输出稳定
+	var defs []*ast.Node
 	for _, node := range r.Tree.Context.LinkRefDefs {
-		label := node.LinkRefLabel
-		dest := node.ChildByType(ast.NodeLinkDest).Tokens
-		buf.WriteString("[" + util.BytesToStr(label) + "]: " + util.BytesToStr(dest) + "\n")
+		defs = append(defs, node)
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		if defs[i].StartPos.Offset != defs[j].StartPos.Offset {
+			return defs[i].StartPos.Offset < defs[j].StartPos.Offset
+		}
+		return bytes.Compare(defs[i].LinkRefLabel, defs[j].LinkRefLabel) < 0
+	})
+	for _, node := range defs {
+		buf.Write(linkRefDefMarkdown(node))
+		buf.WriteByte(lex.ItemNewline)
+	}
+	return buf.Bytes()
+}
+
+// linkRefDefMarkdown 返回链接引用定义 link 的 Markdown 文本 [label]: dest "title"，label 保持原文大小写。
+func linkRefDefMarkdown(link *ast.Node) (ret []byte) {
+	ret = append(ret, lex.ItemOpenBracket)
+	ret = append(ret, link.LinkRefLabel...)
+	ret = append(ret, "]: "...)
+	if dest := link.ChildByType(ast.NodeLinkDest).Tokens; 1 > len(dest) {
+		ret = append(ret, "<>"...)
+	} else {
+		ret = append(ret, dest...)
+	}
+	if title := link.ChildByType(ast.NodeLinkTitle); nil != title {
+		opener, closer := linkTitleQuotes(title.Tokens)
+		ret = append(ret, lex.ItemSpace, opener)
+		ret = append(ret, title.Tokens...)
+		ret = append(ret, closer)
 	}
-	output = append(output, buf.Bytes()...)
 	return
 }
 
+// linkTitleQuotes 返回包裹链接标题 title 使用的定界符，优先使用 "，标题中包含未转义的 " 时依次尝试 ' 和 ()。
+func linkTitleQuotes(title []byte) (opener, closer byte) {
+	for _, quote := range []byte{lex.ItemDoublequote, lex.ItemSinglequote} {
+		unescaped := false
+		for i, token := range title {
+			if quote == token && !lex.IsBackslashEscapePunct(title, i) {
+				unescaped = true
+				break
+			}
+		}
+		if !unescaped {
+			return quote, quote
+		}
+	}
+	return lex.ItemOpenParen, lex.ItemCloseParen
+}
+
+func (r *FormatRenderer) renderLinkRefDefBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	r.Newline()
+	if entering || r.isLastNode(r.Tree.Root, node) {
+		return ast.WalkContinue
+	}
+
+	if next := node.Next; nil != next && 0 < next.StartPos.Line && next.StartPos.Line <= node.EndPos.Line+1 {
+		// 原文中定义后紧跟着内容（比如定义和段落写在一起）时不插入空行，避免改变列表的紧凑性
+		return ast.WalkContinue
+	}
+	if parent := node.Parent; ast.NodeListItem == parent.Type && nil != parent.Parent.ListData && parent.Parent.Tight {
+		return ast.WalkContinue
+	}
+	r.WriteByte(lex.ItemNewline)
+	return ast.WalkContinue
+}
+
+func (r *FormatRenderer) renderLinkRefDef(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Write(linkRefDefMarkdown(node.FirstChild))
+		r.WriteByte(lex.ItemNewline)
+	}
+	return ast.WalkSkipChildren
+}
+
 func (r *FormatRenderer) renderMark(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.TextAutoSpacePrevious(node)
@@ -226,10 +331,11 @@ func (r *FormatRenderer) renderBlockQueryEmbedScript(node *ast.Node, entering bo
 }
 
 func (r *FormatRenderer) renderBlockQueryEmbed(node *ast.Node, entering bool) ast.WalkStatus {
-	if entering {
-		r.Newline()
-	} else {
-		r.Newline()
+	r.Newline()
+	if !entering && !r.isLastNode(r.Tree.Root, node) {
+		if r.withoutKramdownIAL(node) {
+			r.WriteByte(lex.ItemNewline)
+		}
 	}
 	return ast.WalkContinue
 }
@@ -300,7 +406,7 @@ func (r *FormatRenderer) renderBlockRefText(node *ast.Node, entering bool) ast.W
 }
 
 func (r *FormatRenderer) renderYamlFrontMatterCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
-	r.Write(parse.YamlFrontMatterMarker)
+	r.Write(parse.FrontMatterCloseMarker(node.Parent.FrontMatterType))
 	r.WriteByte(lex.ItemNewline)
 	return ast.WalkStop
 }
@@ -312,7 +418,7 @@ func (r *FormatRenderer) renderYamlFrontMatterContent(node *ast.Node, entering b
 }
 
 func (r *FormatRenderer) renderYamlFrontMatterOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
-	r.Write(parse.YamlFrontMatterMarker)
+	r.Write(parse.FrontMatterOpenMarker(node.Parent.FrontMatterType))
 	r.WriteByte(lex.ItemNewline)
 	return ast.WalkStop
 }
@@ -377,7 +483,11 @@ func (r *FormatRenderer) renderEmoji(node *ast.Node, entering bool) ast.WalkStat
 }
 
 func (r *FormatRenderer) renderTableCell(node *ast.Node, entering bool) ast.WalkStatus {
-	padding := node.TableCellContentMaxWidth - node.TableCellContentWidth
+	if r.Option.ExtendedTable {
+		return r.renderExtendedTableCell(node, entering)
+	}
+
+	padding := r.tableCellPadding(node)
 	if entering {
 		r.WriteByte(lex.ItemPipe)
 		r.WriteByte(lex.ItemSpace)
@@ -400,43 +510,134 @@ func (r *FormatRenderer) renderTableCell(node *ast.Node, entering bool) ast.Walk
 	return ast.WalkContinue
 }
 
+// renderExtendedTableCell 缓存扩展表格单元格格式化后的内容，在行结束时统一输出以支持多行单元格。
+func (r *FormatRenderer) renderExtendedTableCell(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Writer = &bytes.Buffer{}
+		r.NodeWriterStack = append(r.NodeWriterStack, r.Writer)
+		if node.TableCellMerged {
+			return ast.WalkSkipChildren
+		}
+	} else {
+		writer := r.NodeWriterStack[len(r.NodeWriterStack)-1]
+		r.NodeWriterStack = r.NodeWriterStack[:len(r.NodeWriterStack)-1]
+		r.Writer = r.NodeWriterStack[len(r.NodeWriterStack)-1]
+		content := bytes.TrimSpace(writer.Bytes())
+		if node.TableCellMerged {
+			content = []byte("^^")
+		}
+		r.tableCells = append(r.tableCells, content)
+	}
+	return ast.WalkContinue
+}
+
 func (r *FormatRenderer) renderTableRow(node *ast.Node, entering bool) ast.WalkStatus {
+	if r.Option.ExtendedTable {
+		if entering {
+			r.tableCells = nil
+		} else {
+			r.writeExtendedTableRow(node)
+		}
+		return ast.WalkContinue
+	}
+
 	if !entering {
 		r.WriteString("|\n")
 	}
 	return ast.WalkContinue
 }
 
+// writeExtendedTableRow 输出扩展表格的行 row，多行单元格按行拆分后逐行输出，除最后一行外行尾使用 \ 标记延续。
+func (r *FormatRenderer) writeExtendedTableRow(row *ast.Node) {
+	var cellsLines [][][]byte
+	lineCnt := 1
+	for _, content := range r.tableCells {
+		lines := bytes.Split(content, []byte{lex.ItemNewline})
+		cellsLines = append(cellsLines, lines)
+		if lineCnt < len(lines) {
+			lineCnt = len(lines)
+		}
+	}
+
+	for i := 0; i < lineCnt; i++ {
+		cell := row.FirstChild
+		for _, lines := range cellsLines {
+			var line []byte
+			if i < len(lines) {
+				line = lines[i]
+			}
+			padding := 0
+			if 1 == lineCnt { // 多行单元格不进行对齐
+				padding = r.tableCellPadding(cell)
+			}
+			r.WriteByte(lex.ItemPipe)
+			r.WriteByte(lex.ItemSpace)
+			switch cell.TableCellAlign {
+			case 2:
+				r.Write(bytes.Repeat([]byte{lex.ItemSpace}, padding/2))
+			case 3:
+				r.Write(bytes.Repeat([]byte{lex.ItemSpace}, padding))
+			}
+			r.Write(line)
+			switch cell.TableCellAlign {
+			case 2:
+				r.Write(bytes.Repeat([]byte{lex.ItemSpace}, padding/2))
+			case 3:
+			default:
+				r.Write(bytes.Repeat([]byte{lex.ItemSpace}, padding))
+			}
+			r.WriteByte(lex.ItemSpace)
+			for span := cell.TableCellColspan; 1 < span; span-- {
+				r.WriteByte(lex.ItemPipe) // 跨列 | foo ||
+			}
+			cell = cell.Next
+		}
+		r.WriteByte(lex.ItemPipe)
+		if i < lineCnt-1 {
+			r.WriteByte(lex.ItemBackslash)
+		}
+		r.WriteByte(lex.ItemNewline)
+	}
+	r.tableCells = nil
+}
+
 func (r *FormatRenderer) renderTableHead(node *ast.Node, entering bool) ast.WalkStatus {
 	if !entering {
 		headRow := node.FirstChild
+		aligns, col := node.Parent.TableAligns, 0
 		for th := headRow.FirstChild; nil != th; th = th.Next {
-			align := th.TableCellAlign
-			switch align {
-			case 0:
-				r.WriteString("| -")
-				if padding := th.TableCellContentMaxWidth - 1; 0 < padding {
-					r.Write(bytes.Repeat([]byte{lex.ItemHyphen}, padding))
-				}
-				r.WriteByte(lex.ItemSpace)
-			case 1:
-				r.WriteString("| :-")
-				if padding := th.TableCellContentMaxWidth - 2; 0 < padding {
-					r.Write(bytes.Repeat([]byte{lex.ItemHyphen}, padding))
+			for span := 0; 0 == span || span < th.TableCellColspan; span, col = span+1, col+1 {
+				align := th.TableCellAlign
+				if 0 < span && col < len(aligns) { // 跨列单元格后续列的对齐方式
+					align = aligns[col]
 				}
-				r.WriteByte(lex.ItemSpace)
-			case 2:
-				r.WriteString("| :-")
-				if padding := th.TableCellContentMaxWidth - 3; 0 < padding {
-					r.Write(bytes.Repeat([]byte{lex.ItemHyphen}, padding))
-				}
-				r.WriteString(": ")
-			case 3:
-				r.WriteString("| -")
-				if padding := th.TableCellContentMaxWidth - 2; 0 < padding {
-					r.Write(bytes.Repeat([]byte{lex.ItemHyphen}, padding))
+				width := r.tableColumnWidth(node.Parent, col)
+				switch align {
+				case 0:
+					r.WriteString("| -")
+					if padding := width - 1; 0 < padding {
+						r.Write(bytes.Repeat([]byte{lex.ItemHyphen}, padding))
+					}
+					r.WriteByte(lex.ItemSpace)
+				case 1:
+					r.WriteString("| :-")
+					if padding := width - 2; 0 < padding {
+						r.Write(bytes.Repeat([]byte{lex.ItemHyphen}, padding))
+					}
+					r.WriteByte(lex.ItemSpace)
+				case 2:
+					r.WriteString("| :-")
+					if padding := width - 3; 0 < padding {
+						r.Write(bytes.Repeat([]byte{lex.ItemHyphen}, padding))
+					}
+					r.WriteString(": ")
+				case 3:
+					r.WriteString("| -")
+					if padding := width - 2; 0 < padding {
+						r.Write(bytes.Repeat([]byte{lex.ItemHyphen}, padding))
+					}
+					r.WriteString(": ")
 				}
-				r.WriteString(": ")
 			}
 		}
 		r.WriteString("|\n")
@@ -445,7 +646,16 @@ func (r *FormatRenderer) renderTableHead(node *ast.Node, entering bool) ast.Walk
 }
 
 func (r *FormatRenderer) renderTable(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering && r.Option.ExtendedTable && r.FormatOptions.TableAlignPadding && nil == node.TableColumnWidths {
+		r.measureTable(node)
+	}
 	if !entering {
+		if 0 < len(node.TableCaption) {
+			r.WriteByte(lex.ItemOpenBracket)
+			r.Write(node.TableCaption)
+			r.WriteByte(lex.ItemCloseBracket)
+			r.WriteByte(lex.ItemNewline)
+		}
 		r.Newline()
 		if !r.isLastNode(r.Tree.Root, node) {
 			if r.withoutKramdownIAL(node) {
@@ -530,6 +740,16 @@ func (r *FormatRenderer) renderOpenBracket(node *ast.Node, entering bool) ast.Wa
 	return ast.WalkStop
 }
 
+func (r *FormatRenderer) renderCloseBrace(node *ast.Node, entering bool) ast.WalkStatus {
+	r.WriteByte(lex.ItemCloseCurlyBrace)
+	return ast.WalkStop
+}
+
+func (r *FormatRenderer) renderOpenBrace(node *ast.Node, entering bool) ast.WalkStatus {
+	r.WriteByte(lex.ItemOpenCurlyBrace)
+	return ast.WalkStop
+}
+
 func (r *FormatRenderer) renderBang(node *ast.Node, entering bool) ast.WalkStatus {
 	r.WriteByte(lex.ItemBang)
 	return ast.WalkStop
@@ -572,12 +792,49 @@ func (r *FormatRenderer) renderInlineHTML(node *ast.Node, entering bool) ast.Wal
 	return ast.WalkStop
 }
 
+// renderSource 原样输出节点对应的 Markdown 原文，用于没有注册渲染函数的自定义语法节点。
+func (r *FormatRenderer) renderSource(node *ast.Node, entering bool) ast.WalkStatus {
+	start, end := node.StartPos.Offset, node.EndPos.Offset
+	if 0 == node.StartPos.Line || start > end || end > len(r.Tree.Source) {
+		return r.BaseRenderer.renderDefault(node, entering)
+	}
+	if !node.IsBlock() {
+		if entering {
+			r.Write(r.Tree.Source[start:end])
+		}
+		return ast.WalkSkipChildren
+	}
+
+	r.Newline()
+	if entering {
+		// 后续行去掉父级容器的前缀（比如列表项缩进、块引用 >），这部分由父级容器的渲染函数输出
+		lines := bytes.Split(bytes.TrimRight(r.Tree.Source[start:end], "\r\n"), []byte("\n"))
+		for i, line := range lines {
+			if 0 < i {
+				r.WriteByte(lex.ItemNewline)
+				if prefix := node.StartPos.Column - 1; prefix <= len(line) {
+					line = line[prefix:]
+				} else {
+					line = nil
+				}
+			}
+			r.Write(line)
+		}
+	} else if !r.isLastNode(r.Tree.Root, node) {
+		r.WriteByte(lex.ItemNewline)
+	}
+	return ast.WalkSkipChildren
+}
+
 func (r *FormatRenderer) renderDocument(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.Writer = &bytes.Buffer{}
 		r.NodeWriterStack = append(r.NodeWriterStack, r.Writer)
 	} else {
 		r.NodeWriterStack = r.NodeWriterStack[:len(r.NodeWriterStack)-1]
+		if nil != r.stream { // 流式输出时首尾空白由 trimWriter 去掉
+			return ast.WalkContinue
+		}
 		buf := bytes.Trim(r.Writer.Bytes(), " \t\n")
 		r.Writer.Reset()
 		r.Write(buf)
@@ -598,8 +855,24 @@ func (r *FormatRenderer) renderParagraph(node *ast.Node, entering bool) ast.Walk
 				}
 			}
 		}
+		if r.hardWrapping(node) {
+			r.Writer = &bytes.Buffer{}
+			r.NodeWriterStack = append(r.NodeWriterStack, r.Writer)
+			r.renderWrapInlines(node)
+			return ast.WalkSkipChildren
+		}
 	} else {
-		if !node.ParentIs(ast.NodeTableCell) {
+		if r.hardWrapping(node) {
+			writer := r.NodeWriterStack[len(r.NodeWriterStack)-1]
+			r.NodeWriterStack = r.NodeWriterStack[:len(r.NodeWriterStack)-1]
+			r.Writer = r.NodeWriterStack[len(r.NodeWriterStack)-1]
+			indent := 0
+			if r.Option.ChineseParagraphBeginningSpace && ast.NodeDocument == node.Parent.Type {
+				indent = 4 // 渲染时段落开头会空两个全角字符
+			}
+			r.Write(r.wrapParagraph(writer.Bytes(), r.FormatOptions.HardWrapColumn, indent))
+		}
+		if !r.inTableCell(node) {
 			if r.withoutKramdownIAL(node) {
 				r.Newline()
 			}
@@ -621,10 +894,13 @@ func (r *FormatRenderer) renderParagraph(node *ast.Node, entering bool) ast.Walk
 				} else {
 					inTightList = true
 				}
+			} else if ast.NodeDefinitionDescription == parent.Type { // DefinitionDescription.Paragraph
+				inTightList = parent.Parent.Tight
+				lastListItemLastPara = nil == parent.Next && nil == node.Next
 			}
 		}
 
-		if (!inTightList || (lastListItemLastPara)) && !node.ParentIs(ast.NodeTableCell) {
+		if (!inTightList || (lastListItemLastPara)) && !r.inTableCell(node) {
 			if r.withoutKramdownIAL(node) {
 				r.WriteByte(lex.ItemNewline)
 			}
@@ -771,7 +1047,11 @@ func (r *FormatRenderer) renderMathBlock(node *ast.Node, entering bool) ast.Walk
 
 func (r *FormatRenderer) renderCodeBlockCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	r.Newline()
-	r.Write(node.Tokens)
+	if fence := r.codeBlockFence(node.Parent); nil != fence {
+		r.Write(fence)
+	} else {
+		r.Write(node.Tokens)
+	}
 	r.Newline()
 	if !r.isLastNode(r.Tree.Root, node) {
 		if r.withoutKramdownIAL(node.Parent) {
@@ -793,6 +1073,10 @@ func (r *FormatRenderer) renderCodeBlockInfoMarker(node *ast.Node, entering bool
 }
 
 func (r *FormatRenderer) renderCodeBlockOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
+	if fence := r.codeBlockFence(node.Parent); nil != fence {
+		r.Write(fence)
+		return ast.WalkStop
+	}
 	r.Write(node.Tokens)
 	return ast.WalkStop
 }
@@ -802,10 +1086,11 @@ func (r *FormatRenderer) renderCodeBlock(node *ast.Node, entering bool) ast.Walk
 		r.Newline()
 	}
 	if !node.IsFencedCodeBlock {
-		r.Write(bytes.Repeat([]byte{lex.ItemBacktick}, 3))
+		fence := r.codeBlockFence(node)
+		r.Write(fence)
 		r.WriteByte(lex.ItemNewline)
 		r.Write(node.FirstChild.Tokens)
-		r.Write(bytes.Repeat([]byte{lex.ItemBacktick}, 3))
+		r.Write(fence)
 		r.Newline()
 		if !r.isLastNode(r.Tree.Root, node) {
 			if r.withoutKramdownIAL(node) {
@@ -827,22 +1112,22 @@ func (r *FormatRenderer) renderEmphasis(node *ast.Node, entering bool) ast.WalkS
 }
 
 func (r *FormatRenderer) renderEmAsteriskOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
-	r.WriteByte(lex.ItemAsterisk)
+	r.WriteByte(r.emphasisMarker(node.Parent, r.FormatOptions.EmphasisMarker, lex.ItemAsterisk))
 	return ast.WalkStop
 }
 
 func (r *FormatRenderer) renderEmAsteriskCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
-	r.WriteByte(lex.ItemAsterisk)
+	r.WriteByte(r.emphasisMarker(node.Parent, r.FormatOptions.EmphasisMarker, lex.ItemAsterisk))
 	return ast.WalkStop
 }
 
 func (r *FormatRenderer) renderEmUnderscoreOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
-	r.WriteByte(lex.ItemUnderscore)
+	r.WriteByte(r.emphasisMarker(node.Parent, r.FormatOptions.EmphasisMarker, lex.ItemUnderscore))
 	return ast.WalkStop
 }
 
 func (r *FormatRenderer) renderEmUnderscoreCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
-	r.WriteByte(lex.ItemUnderscore)
+	r.WriteByte(r.emphasisMarker(node.Parent, r.FormatOptions.EmphasisMarker, lex.ItemUnderscore))
 	return ast.WalkStop
 }
 
@@ -856,22 +1141,30 @@ func (r *FormatRenderer) renderStrong(node *ast.Node, entering bool) ast.WalkSta
 }
 
 func (r *FormatRenderer) renderStrongA6kOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
-	r.WriteString("**")
+	marker := r.emphasisMarker(node.Parent, r.FormatOptions.StrongMarker, lex.ItemAsterisk)
+	r.WriteByte(marker)
+	r.WriteByte(marker)
 	return ast.WalkStop
 }
 
 func (r *FormatRenderer) renderStrongA6kCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
-	r.WriteString("**")
+	marker := r.emphasisMarker(node.Parent, r.FormatOptions.StrongMarker, lex.ItemAsterisk)
+	r.WriteByte(marker)
+	r.WriteByte(marker)
 	return ast.WalkStop
 }
 
 func (r *FormatRenderer) renderStrongU8eOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
-	r.WriteString("__")
+	marker := r.emphasisMarker(node.Parent, r.FormatOptions.StrongMarker, lex.ItemUnderscore)
+	r.WriteByte(marker)
+	r.WriteByte(marker)
 	return ast.WalkStop
 }
 
 func (r *FormatRenderer) renderStrongU8eCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
-	r.WriteString("__")
+	marker := r.emphasisMarker(node.Parent, r.FormatOptions.StrongMarker, lex.ItemUnderscore)
+	r.WriteByte(marker)
+	r.WriteByte(marker)
 	return ast.WalkStop
 }
 
@@ -920,7 +1213,7 @@ func (r *FormatRenderer) renderBlockquote(node *ast.Node, entering bool) ast.Wal
 		buf = bytes.TrimSpace(r.Writer.Bytes())
 		r.Writer.Reset()
 		r.Write(buf)
-		if !node.ParentIs(ast.NodeTableCell) { // 在表格中不能换行，否则会破坏表格的排版 https://github.com/Vanessa219/vditor/issues/368
+		if !r.inTableCell(node) { // 在表格中不能换行，否则会破坏表格的排版 https://github.com/Vanessa219/vditor/issues/368
 			if r.withoutKramdownIAL(node) {
 				r.WriteString("\n\n")
 			}
@@ -933,14 +1226,85 @@ func (r *FormatRenderer) renderBlockquoteMarker(node *ast.Node, entering bool) a
 	return ast.WalkStop
 }
 
+func (r *FormatRenderer) renderAdmonition(node *ast.Node, entering bool) ast.WalkStatus {
+	if 0 == node.AdmonitionFenceLen {
+		// GitHub 风格的提示块按块引用格式化，第一行是类型标记 [!NOTE]
+		if entering {
+			r.renderBlockquote(node, entering)
+			r.WriteString(admonitionOpenMarker(node) + "\n")
+			return ast.WalkContinue
+		}
+		return r.renderBlockquote(node, entering)
+	}
+
+	if entering {
+		r.Writer = &bytes.Buffer{}
+		r.NodeWriterStack = append(r.NodeWriterStack, r.Writer)
+	} else {
+		writer := r.NodeWriterStack[len(r.NodeWriterStack)-1]
+		r.NodeWriterStack = r.NodeWriterStack[:len(r.NodeWriterStack)-1]
+		content := bytes.TrimRight(bytes.TrimLeft(writer.Bytes(), "\n"), " \n")
+		r.Writer = r.NodeWriterStack[len(r.NodeWriterStack)-1]
+		r.WriteString(admonitionOpenMarker(node) + "\n")
+		if 0 < len(content) {
+			r.Write(content)
+			r.WriteByte(lex.ItemNewline)
+		}
+		r.WriteString(admonitionMarker(node))
+		if !r.inTableCell(node) {
+			if r.withoutKramdownIAL(node) {
+				r.WriteString("\n\n")
+			}
+		}
+	}
+	return ast.WalkContinue
+}
+
+func (r *FormatRenderer) renderDefinitionList(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.renderList(node, entering)
+}
+
+func (r *FormatRenderer) renderDefinitionTerm(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.WriteByte(lex.ItemNewline)
+	}
+	return ast.WalkContinue
+}
+
+func (r *FormatRenderer) renderDefinitionDescription(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Writer = &bytes.Buffer{}
+		r.NodeWriterStack = append(r.NodeWriterStack, r.Writer)
+	} else {
+		writer := r.NodeWriterStack[len(r.NodeWriterStack)-1]
+		r.NodeWriterStack = r.NodeWriterStack[:len(r.NodeWriterStack)-1]
+		r.Writer = r.NodeWriterStack[len(r.NodeWriterStack)-1]
+		r.WriteString(": ")
+		lines := bytes.Split(bytes.TrimRight(writer.Bytes(), " \n"), []byte{lex.ItemNewline})
+		for i, line := range lines {
+			if 0 < i && 0 < len(line) {
+				r.WriteString("  ")
+			}
+			r.Write(line)
+			r.WriteByte(lex.ItemNewline)
+		}
+		if next := node.Next; nil != next && (ast.NodeDefinitionTerm == next.Type || !node.Parent.Tight) {
+			// 描述后面的术语需要空行分隔，否则会被解析为描述的延续文本
+			r.WriteByte(lex.ItemNewline)
+		}
+	}
+	return ast.WalkContinue
+}
+
 func (r *FormatRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStatus {
+	setext := r.headingSetext(node)
 	if entering {
-		if !node.HeadingSetext {
+		if !setext {
 			r.Write(bytes.Repeat([]byte{lex.ItemCrosshatch}, node.HeadingLevel))
 			r.WriteByte(lex.ItemSpace)
 		}
 	} else {
-		if node.HeadingSetext {
+		if setext {
 			r.WriteByte(lex.ItemNewline)
 			contentLen := r.setextHeadingLen(node)
 			if 1 == node.HeadingLevel {
@@ -950,7 +1314,7 @@ func (r *FormatRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkSt
 			}
 		}
 
-		if !node.ParentIs(ast.NodeTableCell) {
+		if !r.inTableCell(node) {
 			if r.withoutKramdownIAL(node) {
 				r.Newline()
 				r.WriteByte(lex.ItemNewline)
@@ -981,7 +1345,7 @@ func (r *FormatRenderer) renderList(node *ast.Node, entering bool) ast.WalkStatu
 		buf := bytes.TrimSpace(r.Writer.Bytes())
 		r.Writer.Reset()
 		r.Write(buf)
-		if !node.ParentIs(ast.NodeTableCell) {
+		if !r.inTableCell(node) {
 			if r.withoutKramdownIAL(node) {
 				r.WriteString("\n\n")
 			}
@@ -997,10 +1361,8 @@ func (r *FormatRenderer) renderListItem(node *ast.Node, entering bool) ast.WalkS
 	} else {
 		writer := r.NodeWriterStack[len(r.NodeWriterStack)-1]
 		r.NodeWriterStack = r.NodeWriterStack[:len(r.NodeWriterStack)-1]
-		indent := len(node.Marker) + 1
-		if 1 == node.ListData.Typ || (3 == node.ListData.Typ && 0 == node.ListData.BulletChar) {
-			indent++
-		}
+		marker := r.listItemMarker(node)
+		indent := len(marker) + 1
 		indentSpaces := bytes.Repeat([]byte{lex.ItemSpace}, indent)
 		indentedLines := bytes.Buffer{}
 		buf := writer.Bytes()
@@ -1020,20 +1382,16 @@ func (r *FormatRenderer) renderListItem(node *ast.Node, entering bool) ast.WalkS
 		}
 
 		listItemBuf := bytes.Buffer{}
-		if 1 == node.ListData.Typ || (3 == node.ListData.Typ && 0 == node.ListData.BulletChar) {
-			listItemBuf.WriteString(strconv.Itoa(node.Num) + string(node.ListData.Delimiter))
-		} else {
-			listItemBuf.Write(node.Marker)
-		}
+		listItemBuf.Write(marker)
 		listItemBuf.WriteByte(lex.ItemSpace)
 		buf = append(listItemBuf.Bytes(), buf...)
-		if node.ParentIs(ast.NodeTableCell) {
+		if r.inTableCell(node) {
 			buf = bytes.ReplaceAll(buf, []byte("\n"), nil)
 		}
 		writer.Reset()
 		writer.Write(buf)
 		buf = writer.Bytes()
-		if node.ParentIs(ast.NodeTableCell) {
+		if r.inTableCell(node) {
 			buf = bytes.ReplaceAll(buf, []byte("\n"), nil)
 		}
 		r.NodeWriterStack[len(r.NodeWriterStack)-1].Write(buf)
@@ -1041,7 +1399,7 @@ func (r *FormatRenderer) renderListItem(node *ast.Node, entering bool) ast.WalkS
 		buf = bytes.TrimSpace(r.Writer.Bytes())
 		r.Writer.Reset()
 		r.Write(buf)
-		if !node.ParentIs(ast.NodeTableCell) {
+		if !r.inTableCell(node) {
 			r.WriteString("\n")
 		}
 	}
@@ -1095,6 +1453,11 @@ func (r *FormatRenderer) renderSoftBreak(node *ast.Node, entering bool) ast.Walk
 	return ast.WalkStop
 }
 
+// inTableCell 判断节点 node 是否在表格单元格中，此时块级节点需要压缩为一行。扩展表格的单元格支持多行内容，不需要压缩。
+func (r *FormatRenderer) inTableCell(node *ast.Node) bool {
+	return node.ParentIs(ast.NodeTableCell) && !r.Option.ExtendedTable
+}
+
 func (r *FormatRenderer) withoutKramdownIAL(node *ast.Node) bool {
 	return !r.Option.KramdownIAL || 0 == len(node.KramdownIAL)
 }
@@ -0,0 +1,362 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/lex"
+)
+
+// 段落折行时写入输出缓冲的标记字节，折行完成后会被去掉。
+const (
+	wrapAtomOpen  = '\x02' // 不可拆分片段开始，比如代码、链接和内容块引用
+	wrapAtomClose = '\x03' // 不可拆分片段结束
+	wrapSoftBreak = '\x1f' // 可以合并的软换行
+)
+
+// 折行片段和前一个片段之间的间隔类型。
+const (
+	wrapGapNone  = iota // 紧邻，不能断开
+	wrapGapZero         // 零宽断点，比如两个汉字之间
+	wrapGapSpace        // 空格，断开时空格被换行替代
+	wrapGapSoft         // 软换行，根据两侧字符确定为空格或者零宽断点
+	wrapGapHard         // 硬换行，必须保留
+)
+
+// 不能出现在行首的标点，即避头尾规则中的避头字符。
+const wrapNoBreakBefore = "，。、；：！？）」』】》〉〕〗〙〛｝］”’…—～·%,.;:!?)]}"
+
+// 不能出现在行尾的标点，即避头尾规则中的避尾字符。
+const wrapNoBreakAfter = "（「『【《〈〔〖〘〚｛［“‘([{"
+
+// wrapUnit 描述了折行时不可拆分的片段。
+type wrapUnit struct {
+	text        []byte
+	width       int  // 显示宽度，东亚宽字符占两列
+	gap         int  // 和前一个片段之间的间隔类型
+	first, last rune // 首尾字符，不可拆分片段按拉丁字符处理
+}
+
+// hardWrapping 判断段落 paragraph 是否需要折行，表格单元格中的段落不折行。
+// 软换行渲染为硬换行时折行会改变渲染结果，这时也不折行。
+func (r *FormatRenderer) hardWrapping(paragraph *ast.Node) bool {
+	return 0 < r.FormatOptions.HardWrapColumn && !r.Option.SoftBreak2HardBreak && !paragraph.ParentIs(ast.NodeTableCell)
+}
+
+// renderWrapInlines 渲染段落 paragraph 的行级子节点，并在输出中写入不可拆分片段和软换行的标记。
+func (r *FormatRenderer) renderWrapInlines(paragraph *ast.Node) {
+	reflow := r.FormatOptions.Reflow
+	for c := paragraph.FirstChild; nil != c; c = c.Next {
+		ast.Walk(c, func(n *ast.Node, entering bool) ast.WalkStatus {
+			if ast.NodeSoftBreak == n.Type && reflow {
+				r.Writer.WriteByte(wrapSoftBreak)
+				return ast.WalkStop
+			}
+
+			atom := isWrapAtom(n)
+			if atom && entering {
+				r.Writer.WriteByte(wrapAtomOpen)
+			}
+			status := r.renderNode(n, entering)
+			if atom && (!entering || ast.WalkStop == status) {
+				r.Writer.WriteByte(wrapAtomClose)
+			}
+			return status
+		})
+	}
+}
+
+// isWrapAtom 判断行级节点 n 是否是折行时不可拆分的片段。
+func isWrapAtom(n *ast.Node) bool {
+	switch n.Type {
+	case ast.NodeCodeSpan, ast.NodeInlineMath, ast.NodeLink, ast.NodeImage, ast.NodeBlockRef, ast.NodeInlineHTML,
+		ast.NodeFootnotesRef, ast.NodeTag, ast.NodeHTMLEntity, ast.NodeBackslash:
+		return true
+	}
+	return false
+}
+
+// wrapParagraph 将段落内容 text 按列宽 column 折行，indent 为段落首行缩进的宽度。
+func (r *FormatRenderer) wrapParagraph(text []byte, column, indent int) []byte {
+	units := splitWrapUnits(text)
+	for i, unit := range units {
+		if wrapGapSoft == unit.gap {
+			unit.gap = r.softBreakGap(units[i-1].last, unit.first)
+		}
+	}
+
+	// 段落以 [label]: 开头的话首行不能折行，否则 [foo]: /url "title" ok 这样的段落折行后会变为链接引用定义
+	keepFirstLine := 0 < len(units) && isLinkRefDefStart(wrapLineFrom(units))
+	buf := &bytes.Buffer{}
+	width, limit := 0, column-indent
+	for i, unit := range units {
+		if 0 < i {
+			switch unit.gap {
+			case wrapGapHard:
+				buf.WriteByte(lex.ItemNewline)
+				width, limit = 0, column
+				keepFirstLine = false
+			case wrapGapZero, wrapGapSpace:
+				gapWidth := 0
+				if wrapGapSpace == unit.gap {
+					gapWidth = 1
+				}
+				runWidth := unit.width
+				for j := i + 1; j < len(units) && wrapGapNone == units[j].gap; j++ {
+					runWidth += units[j].width
+				}
+				if 0 < width && limit < width+gapWidth+runWidth && !keepFirstLine && !isBlockStartLine(wrapLineFrom(units[i:])) {
+					buf.WriteByte(lex.ItemNewline)
+					width, limit = 0, column
+				} else if 0 < gapWidth {
+					buf.WriteByte(lex.ItemSpace)
+					width += gapWidth
+				}
+			}
+		}
+		buf.Write(unit.text)
+		width += unit.width
+	}
+	return buf.Bytes()
+}
+
+// softBreakGap 返回字符 prev 和 next 之间的软换行合并后的间隔类型。
+// 两个东亚宽字符之间直接相连；宽字符和拉丁字符之间开启 AutoSpace 时使用空格，否则直接相连；拉丁字符之间使用空格。
+func (r *FormatRenderer) softBreakGap(prev, next rune) int {
+	prevWide, nextWide := isWideRune(prev), isWideRune(next)
+	if !prevWide && !nextWide {
+		return wrapGapSpace
+	}
+	if prevWide != nextWide && r.Option.AutoSpace && allowSpace(prev, next) {
+		return wrapGapSpace
+	}
+	if wrapBreakable(prev, next) {
+		return wrapGapZero
+	}
+	return wrapGapNone
+}
+
+// splitWrapUnits 将 text 拆分为不可拆分的片段，拉丁单词和标记包裹的片段不会被拆开，东亚宽字符之间可以断开。
+func splitWrapUnits(text []byte) (ret []*wrapUnit) {
+	cur := &wrapUnit{}
+	pending := wrapGapNone
+	appendContent := func(content []byte, width int, first, last rune) {
+		if wrapGapNone != pending || (0 < len(cur.text) && wrapBreakable(cur.last, first)) {
+			if 0 < len(cur.text) {
+				ret = append(ret, cur)
+			}
+			gap := pending
+			if wrapGapNone == gap {
+				gap = wrapGapZero
+			}
+			cur = &wrapUnit{gap: gap}
+			pending = wrapGapNone
+		}
+		if 0 == len(cur.text) {
+			cur.first = first
+		}
+		cur.text = append(cur.text, content...)
+		cur.width += width
+		cur.last = last
+	}
+
+	for i := 0; i < len(text); {
+		switch text[i] {
+		case lex.ItemSpace:
+			if wrapGapSpace > pending {
+				pending = wrapGapSpace
+			}
+			i++
+			continue
+		case wrapSoftBreak:
+			if wrapGapSoft > pending {
+				pending = wrapGapSoft
+			}
+			i++
+			continue
+		case lex.ItemNewline:
+			pending = wrapGapHard
+			i++
+			continue
+		case wrapAtomOpen:
+			end, depth := i+1, 1
+			for ; end < len(text); end++ {
+				if wrapAtomOpen == text[end] {
+					depth++
+				} else if wrapAtomClose == text[end] {
+					if depth--; 0 == depth {
+						break
+					}
+				}
+			}
+			atom := bytes.Map(func(r rune) rune {
+				switch r {
+				case wrapAtomOpen, wrapAtomClose:
+					return -1
+				case wrapSoftBreak:
+					return ' '
+				}
+				return r
+			}, text[i+1:end])
+			// 渲染片段时自动插入的首尾空格（比如 AutoSpace）作为间隔处理
+			if trimmed := bytes.TrimLeft(atom, " "); len(trimmed) < len(atom) && wrapGapSpace > pending {
+				pending = wrapGapSpace
+				atom = trimmed
+			}
+			trimmed := bytes.TrimRight(atom, " ")
+			if 0 < len(trimmed) {
+				appendContent(trimmed, displayWidth(trimmed), 'a', 'a')
+			}
+			if len(trimmed) < len(atom) && wrapGapSpace > pending {
+				pending = wrapGapSpace
+			}
+			i = end + 1
+			continue
+		}
+
+		if end := wrapAngleSpanEnd(text[i:]); 0 < end {
+			// 可能构成自动链接或者 HTML 标签的 <…> 不能拆开，否则重新解析时结果会发生变化
+			appendContent(text[i:i+end], displayWidth(text[i:i+end]), 'a', 'a')
+			i += end
+			continue
+		}
+
+		r, size := utf8.DecodeRune(text[i:])
+		appendContent(text[i:i+size], runeWidth(r), r, r)
+		i += size
+	}
+	if 0 < len(cur.text) {
+		ret = append(ret, cur)
+	}
+	return
+}
+
+// wrapAngleSpanEnd 返回 text 开头可能构成自动链接或者 HTML 标签的 <…> 的长度，比如 <http://foo.bar/baz bim> 和 <a href="x y">，
+// 不是的话返回 0。< 后面需要紧跟字母、/、! 或者 ?，并且在同一行内闭合。
+func wrapAngleSpanEnd(text []byte) int {
+	if 2 > len(text) || lex.ItemLess != text[0] {
+		return 0
+	}
+	if c := text[1]; !lex.IsASCIILetter(c) && lex.ItemSlash != c && lex.ItemBang != c && lex.ItemQuestion != c {
+		return 0
+	}
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case lex.ItemGreater:
+			return i + 1
+		case lex.ItemNewline, wrapSoftBreak, wrapAtomOpen, wrapAtomClose, lex.ItemLess:
+			return 0
+		}
+	}
+	return 0
+}
+
+// wrapBreakable 判断紧邻的字符 prev 和 next 之间是否可以断开。拉丁单词内部不能断开，另外还需要遵守标点的避头尾规则。
+func wrapBreakable(prev, next rune) bool {
+	if !isWideRune(prev) && !isWideRune(next) {
+		return false
+	}
+	return !strings.ContainsRune(wrapNoBreakBefore, next) && !strings.ContainsRune(wrapNoBreakAfter, prev)
+}
+
+// isWideRune 判断 r 是否是东亚宽字符（包括全角字符）。
+func isWideRune(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r) ||
+		(0x2E80 <= r && 0x303F >= r) || (0x3100 <= r && 0x32FF >= r) || (0xFE30 <= r && 0xFE4F >= r) ||
+		(0xFF01 <= r && 0xFF60 >= r) || (0xFFE0 <= r && 0xFFE6 >= r) || (0x1F300 <= r && 0x1FAFF >= r)
+}
+
+// runeWidth 返回 r 的显示宽度，东亚宽字符占两列。
+func runeWidth(r rune) int {
+	if isWideRune(r) {
+		return 2
+	}
+	return 1
+}
+
+// displayWidth 返回 text 的显示宽度。
+func displayWidth(text []byte) (ret int) {
+	for _, r := range string(text) {
+		ret += runeWidth(r)
+	}
+	return
+}
+
+// wrapLineMaxLen 判断折行后行首是否开始块级元素时最多检查的字节数，链接引用定义的标签最长为 999 个字符。
+const wrapLineMaxLen = 1024
+
+// wrapLineFrom 返回从片段 units[0] 开始到下一个硬换行为止（最多 wrapLineMaxLen 字节）的内容，即在此处折行后新行的前缀。
+func wrapLineFrom(units []*wrapUnit) []byte {
+	buf := &bytes.Buffer{}
+	for i, unit := range units {
+		if 0 < i {
+			if wrapGapHard == unit.gap {
+				break
+			}
+			if wrapGapSpace == unit.gap {
+				buf.WriteByte(lex.ItemSpace)
+			}
+		}
+		buf.Write(unit.text)
+		if wrapLineMaxLen <= buf.Len() {
+			break
+		}
+	}
+	return buf.Bytes()
+}
+
+// isBlockStartLine 判断位于行首的内容 line 是否可能开始一个块级元素，比如标题、列表、块引用、代码块、链接引用定义或者 Setext 标题下划线。
+func isBlockStartLine(line []byte) bool {
+	if lex.ItemOpenBracket == line[0] {
+		return isLinkRefDefStart(line)
+	}
+
+	word := line
+	if end := bytes.IndexByte(line, lex.ItemSpace); 0 < end {
+		word = line[:end]
+	}
+	return isBlockStartWord(word)
+}
+
+// isLinkRefDefStart 判断 line 是否以链接引用定义 [label]: 或者脚注定义 [^label]: 开头，标签中可能包含空格。
+func isLinkRefDefStart(line []byte) bool {
+	if 1 > len(line) || lex.ItemOpenBracket != line[0] {
+		return false
+	}
+	end := bytes.IndexByte(line, lex.ItemCloseBracket)
+	return 0 < end && end+1 < len(line) && lex.ItemColon == line[end+1]
+}
+
+// isBlockStartWord 判断位于行首的单词 word 是否可能开始一个块级元素，比如标题、列表、块引用、代码块或者 Setext 标题下划线。
+func isBlockStartWord(word []byte) bool {
+	switch word[0] {
+	case '>', '|', '<':
+		return true
+	case '`', '~', '$':
+		return bytes.HasPrefix(word, bytes.Repeat(word[:1], 3)) || (lex.ItemDollar == word[0] && bytes.HasPrefix(word, []byte("$$")))
+	case '!', '{':
+		return bytes.HasPrefix(word, []byte("!{{")) || bytes.HasPrefix(word, []byte("{{{"))
+	case '#', '-', '+', '*', '=', '_', ':':
+		// 标题、列表项、分隔线、Setext 标题下划线、定义列表和提示块标记符都由同一个字符组成
+		return 0 == len(bytes.Trim(word, string(word[:1])))
+	}
+
+	i := 0
+	for i < len(word) && lex.IsDigit(word[i]) {
+		i++
+	}
+	return 0 < i && i == len(word)-1 && (lex.ItemDot == word[i] || lex.ItemCloseParen == word[i])
+}
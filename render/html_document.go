@@ -0,0 +1,228 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+//go:build !javascript
+// +build !javascript
+
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	chromahtml "github.com/alecthomas/chroma/formatters/html"
+	"github.com/alecthomas/chroma/styles"
+)
+
+// DocumentOptions 描述了渲染完整 HTML 文档的配置。
+type DocumentOptions struct {
+	// Template 用户自定义的文档模板，模板数据为 *Document，为 nil 时使用内置模板。
+	Template *template.Template
+	// InlineImages 设置是否将本地图片内联为 data URI，以便导出单文件。
+	InlineImages bool
+	// ImageDir 设置本地图片相对路径的基础目录，为空时使用当前工作目录。只内联位于该目录下的图片，通过 ../ 或者符号链接指向目录外的图片保持原样。
+	ImageDir string
+	// AllowAbsoluteImagePaths 设置是否内联使用绝对路径引用的本地图片，默认不内联，避免文档读取到任意位置的文件。
+	AllowAbsoluteImagePaths bool
+	// Title 设置默认标题，YAML Front Matter 中没有 title 时使用，为空的话使用第一个标题。
+	Title string
+	// Lang 设置默认语言，YAML Front Matter 中没有 lang 时使用。
+	Lang string
+}
+
+// NewDocumentOptions 创建默认的完整 HTML 文档配置。
+func NewDocumentOptions() *DocumentOptions {
+	return &DocumentOptions{}
+}
+
+// Document 描述了完整 HTML 文档模板的数据。
+type Document struct {
+	Title       string                 // 标题
+	Description string                 // 描述
+	Lang        string                 // 语言
+	Meta        map[string]interface{} // 解码后的 YAML Front Matter
+	CSS         template.CSS           // 代码块语法高亮样式
+	Body        template.HTML          // 渲染得到的 HTML 片段
+}
+
+// DefaultDocumentTemplate 是内置的完整 HTML 文档模板。
+var DefaultDocumentTemplate = template.Must(template.New("document").Parse(`<!DOCTYPE html>
+<html{{if .Lang}} lang="{{.Lang}}"{{end}}>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+{{- if .Description}}
+<meta name="description" content="{{.Description}}">
+{{- end}}
+{{- if .CSS}}
+<style>
+{{.CSS}}</style>
+{{- end}}
+</head>
+<body>
+{{.Body}}</body>
+</html>
+`))
+
+// NewDocument 根据语法树 tree 和配置 options 创建文档数据，并为渲染完整文档调整语法树：
+// 移除 YAML Front Matter 节点（其内容作为文档元数据），按配置将本地图片内联为 data URI。
+func NewDocument(tree *parse.Tree, options *DocumentOptions) (ret *Document) {
+	ret = &Document{Title: options.Title, Lang: options.Lang, Meta: tree.FrontMatter}
+	if nil == ret.Meta {
+		ret.Meta = map[string]interface{}{}
+	}
+	if frontMatter := tree.Root.ChildByType(ast.NodeYamlFrontMatter); nil != frontMatter {
+		frontMatter.Unlink()
+	}
+	if title := metaString(ret.Meta["title"]); "" != title {
+		ret.Title = title
+	}
+	if "" == ret.Title {
+		if heading := tree.Root.ChildByType(ast.NodeHeading); nil != heading {
+			ret.Title = heading.Text()
+		} else {
+			ret.Title = tree.Name
+		}
+	}
+	ret.Description = metaString(ret.Meta["description"])
+	if lang := metaString(ret.Meta["lang"]); "" != lang {
+		ret.Lang = lang
+	}
+
+	if tree.Context.Option.CodeSyntaxHighlight && !tree.Context.Option.CodeSyntaxHighlightInlineStyle {
+		ret.CSS = template.CSS(chromaCSS(tree.Context.Option.CodeSyntaxHighlightStyleName))
+	}
+
+	if options.InlineImages {
+		inlineImages(tree, options.ImageDir, options.AllowAbsoluteImagePaths)
+	}
+	return
+}
+
+// Render 使用模板 tpl 渲染完整 HTML 文档，tpl 为 nil 时使用内置模板。
+func (doc *Document) Render(tpl *template.Template) ([]byte, error) {
+	if nil == tpl {
+		tpl = DefaultDocumentTemplate
+	}
+	buf := &bytes.Buffer{}
+	if err := tpl.Execute(buf, doc); nil != err {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// metaString 返回元数据值 value 的字符串形式，value 不是纯量的话返回空字符串。
+func metaString(value interface{}) string {
+	switch v := value.(type) {
+	case nil, map[string]interface{}, []interface{}:
+		return ""
+	case string:
+		return v
+	}
+	return fmt.Sprint(value)
+}
+
+// chromaCSS 返回语法高亮样式 styleName 对应的 CSS。
+func chromaCSS(styleName string) string {
+	formatter := chromahtml.New(chromahtml.WithClasses(true), chromahtml.ClassPrefix("highlight-"))
+	buf := &bytes.Buffer{}
+	if err := formatter.WriteCSS(buf, styles.Get(styleName)); nil != err {
+		return ""
+	}
+	return buf.String()
+}
+
+// inlineImages 将语法树 tree 中引用本地图片的地址替换为 data URI，dir 为相对路径的基础目录，allowAbs 表示是否内联绝对路径，
+// 读取失败或者不在 dir 下的图片保持原样。
+func inlineImages(tree *parse.Tree, dir string, allowAbs bool) {
+	dir, err := filepath.Abs(dir)
+	if nil != err {
+		return
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); nil == err {
+		dir = resolved
+	}
+
+	ast.Walk(tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering || ast.NodeImage != n.Type {
+			return ast.WalkContinue
+		}
+		dest := n.ChildByType(ast.NodeLinkDest)
+		if nil == dest {
+			return ast.WalkContinue
+		}
+		if dataURI := imageDataURI(string(dest.Tokens), dir, allowAbs); "" != dataURI {
+			dest.Tokens = []byte(dataURI)
+			n.LinkInlined = true
+		}
+		return ast.WalkContinue
+	})
+}
+
+// imageDataURI 读取本地图片 dest 并返回对应的 data URI，dest 不是本地图片、读取失败或者解析后不在 dir 下（allowAbs 时绝对路径除外）时返回空字符串。
+func imageDataURI(dest, dir string, allowAbs bool) string {
+	if "" == dest || strings.HasPrefix(dest, "//") || (strings.Contains(dest, ":") && !filepath.IsAbs(dest)) {
+		return "" // 远程地址或者已经是 data URI
+	}
+	if i := strings.IndexAny(dest, "?#"); 0 <= i {
+		dest = dest[:i]
+	}
+	path, err := url.PathUnescape(dest)
+	if nil != err {
+		return ""
+	}
+	path = filepath.FromSlash(path)
+	abs := filepath.IsAbs(path)
+	if abs && !allowAbs {
+		return ""
+	}
+	if !abs {
+		path = filepath.Join(dir, path)
+	}
+	if path, err = filepath.EvalSymlinks(path); nil != err {
+		return ""
+	}
+	if !abs && !inDir(path, dir) {
+		return ""
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if nil != err {
+		return ""
+	}
+	mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(path)))
+	if "" == mimeType {
+		mimeType = http.DetectContentType(data)
+	}
+	if i := strings.Index(mimeType, ";"); 0 <= i {
+		mimeType = mimeType[:i]
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		return ""
+	}
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+// inDir 判断解析后的路径 path 是否位于目录 dir 下。
+func inDir(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return nil == err && ".." != rel && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -65,6 +65,10 @@ func NewHtmlRenderer(tree *parse.Tree) *HtmlRenderer {
 	ret.RendererFuncs[ast.NodeStrongU8eCloseMarker] = ret.renderStrongU8eCloseMarker
 	ret.RendererFuncs[ast.NodeBlockquote] = ret.renderBlockquote
 	ret.RendererFuncs[ast.NodeBlockquoteMarker] = ret.renderBlockquoteMarker
+	ret.RendererFuncs[ast.NodeAdmonition] = ret.renderAdmonition
+	ret.RendererFuncs[ast.NodeDefinitionList] = ret.renderDefinitionList
+	ret.RendererFuncs[ast.NodeDefinitionTerm] = ret.renderDefinitionTerm
+	ret.RendererFuncs[ast.NodeDefinitionDescription] = ret.renderDefinitionDescription
 	ret.RendererFuncs[ast.NodeHeading] = ret.renderHeading
 	ret.RendererFuncs[ast.NodeHeadingC8hMarker] = ret.renderHeadingC8hMarker
 	ret.RendererFuncs[ast.NodeHeadingID] = ret.renderHeadingID
@@ -121,7 +125,7 @@ func NewHtmlRenderer(tree *parse.Tree) *HtmlRenderer {
 	ret.RendererFuncs[ast.NodeMark2CloseMarker] = ret.renderMark2CloseMarker
 	ret.RendererFuncs[ast.NodeKramdownBlockIAL] = ret.renderKramdownBlockIAL
 	ret.RendererFuncs[ast.NodeBlockQueryEmbed] = ret.renderBlockQueryEmbed
-	ret.RendererFuncs[ast.NodeBlockQueryEmbedScript] = ret.renderBlockQueryEmbedScript
+	ret.RendererFuncs[ast.NodeLinkRefDefBlock] = ret.renderLinkRefDefBlock
 	ret.RendererFuncs[ast.NodeBlockEmbed] = ret.renderBlockEmbed
 	ret.RendererFuncs[ast.NodeBlockEmbedID] = ret.renderBlockEmbedID
 	ret.RendererFuncs[ast.NodeBlockEmbedSpace] = ret.renderBlockEmbedSpace
@@ -186,24 +190,23 @@ func (r *HtmlRenderer) renderMark2CloseMarker(node *ast.Node, entering bool) ast
 	return ast.WalkStop
 }
 
+func (r *HtmlRenderer) renderLinkRefDefBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkSkipChildren // 链接引用定义不进行渲染
+}
+
 func (r *HtmlRenderer) renderBlockQueryEmbed(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.Newline()
-		r.tag("div", nil, false)
-	} else {
+		script := parse.BlockQueryEmbedScript(node.ChildByType(ast.NodeBlockQueryEmbedScript).Tokens)
+		r.tag("div", [][]string{{"class", "block-query-embed"}, {"data-script", html.EscapeString(script)}}, false)
+		if result := r.blockQueryEmbedResult(node); nil != result {
+			r.Newline()
+			r.Write(result)
+		}
 		r.tag("/div", nil, false)
 		r.Newline()
 	}
-	return ast.WalkContinue
-}
-
-func (r *HtmlRenderer) renderBlockQueryEmbedScript(node *ast.Node, entering bool) ast.WalkStatus {
-	if entering {
-		r.WriteByte(lex.ItemDoublequote)
-		r.Write(node.Tokens)
-		r.WriteByte(lex.ItemDoublequote)
-	}
-	return ast.WalkContinue
+	return ast.WalkSkipChildren
 }
 
 func (r *HtmlRenderer) renderBlockEmbed(node *ast.Node, entering bool) ast.WalkStatus {
@@ -272,6 +275,10 @@ func (r *HtmlRenderer) renderYamlFrontMatterOpenMarker(node *ast.Node, entering
 }
 
 func (r *HtmlRenderer) renderYamlFrontMatter(node *ast.Node, entering bool) ast.WalkStatus {
+	if parse.FrontMatterYaml != node.FrontMatterType {
+		// TOML 和 JSON Front Matter 仅作为元数据，不渲染
+		return ast.WalkStop
+	}
 	r.Newline()
 	return ast.WalkContinue
 }
@@ -297,17 +304,46 @@ func (r *HtmlRenderer) renderToC(node *ast.Node, entering bool) ast.WalkStatus {
 		return ast.WalkStop
 	}
 	r.WriteString("<div class=\"vditor-toc\">")
+	if "ul" == r.Option.ToCList || "ol" == r.Option.ToCList {
+		r.renderToCList(Outline(r.Tree), 1)
+		r.WriteString("</div>")
+		return ast.WalkStop
+	}
 	for _, heading := range headings {
 		level := strconv.Itoa(heading.HeadingLevel)
 		spaces := (heading.HeadingLevel - 1) * 2
 		r.WriteString(strings.Repeat("&emsp;", spaces))
 		r.WriteString("<span class=\"toc-h" + level + "\">")
-		r.WriteString("<a class=\"toc-a\" href=\"#" + HeadingID(heading) + "\">" + heading.Text() + "</a></span><br>")
+		r.WriteString("<a class=\"toc-a\" href=\"#" + HeadingID(heading) + "\">" + tocText(heading) + "</a></span><br>")
 	}
 	r.WriteString("</div>")
 	return ast.WalkStop
 }
 
+// renderToCList 使用嵌套列表渲染大纲 outline，depth 为 outline 所在的层级。
+func (r *HtmlRenderer) renderToCList(outline []*OutlineHeading, depth int) {
+	if 1 > len(outline) || (0 < r.Option.ToCDepth && depth > r.Option.ToCDepth) {
+		return
+	}
+
+	r.WriteString("<" + r.Option.ToCList + ">")
+	for _, heading := range outline {
+		r.WriteString("<li class=\"toc-h" + strconv.Itoa(heading.Level) + "\">")
+		r.WriteString("<a class=\"toc-a\" href=\"#" + heading.ID + "\">" + tocText(heading.Node) + "</a>")
+		r.renderToCList(heading.Children, depth+1)
+		r.WriteString("</li>")
+	}
+	r.WriteString("</" + r.Option.ToCList + ">")
+}
+
+// tocText 返回标题 heading 在 ToC 中的文本，开启标题编号的话带上编号。
+func tocText(heading *ast.Node) string {
+	if "" != heading.HeadingNumber {
+		return heading.HeadingNumber + " " + heading.Text()
+	}
+	return heading.Text()
+}
+
 func (r *HtmlRenderer) RenderFootnotesDefs(context *parse.Context) []byte {
 	r.WriteString("<div class=\"footnotes-defs-div\">")
 	r.WriteString("<hr class=\"footnotes-defs-hr\" />\n")
@@ -426,6 +462,10 @@ func (r *HtmlRenderer) renderMathBlock(node *ast.Node, entering bool) ast.WalkSt
 }
 
 func (r *HtmlRenderer) renderTableCell(node *ast.Node, entering bool) ast.WalkStatus {
+	if node.TableCellMerged { // 被上方单元格跨行合并的占位单元格
+		return ast.WalkSkipChildren
+	}
+
 	tag := "td"
 	if ast.NodeTableHead == node.Parent.Parent.Type {
 		tag = "th"
@@ -440,6 +480,12 @@ func (r *HtmlRenderer) renderTableCell(node *ast.Node, entering bool) ast.WalkSt
 		case 3:
 			attrs = append(attrs, []string{"align", "right"})
 		}
+		if 1 < node.TableCellColspan {
+			attrs = append(attrs, []string{"colspan", strconv.Itoa(node.TableCellColspan)})
+		}
+		if 1 < node.TableCellRowspan {
+			attrs = append(attrs, []string{"rowspan", strconv.Itoa(node.TableCellRowspan)})
+		}
 		r.tag(tag, attrs, false)
 	} else {
 		r.tag("/"+tag, nil, false)
@@ -478,6 +524,12 @@ func (r *HtmlRenderer) renderTable(node *ast.Node, entering bool) ast.WalkStatus
 	if entering {
 		r.tag("table", nil, false)
 		r.Newline()
+		if 0 < len(node.TableCaption) {
+			r.tag("caption", nil, false)
+			r.Write(html.EscapeHTML(node.TableCaption))
+			r.tag("/caption", nil, false)
+			r.Newline()
+		}
 	} else {
 		if nil != node.FirstChild.Next {
 			r.tag("/tbody", nil, false)
@@ -562,14 +614,19 @@ func (r *HtmlRenderer) renderImage(node *ast.Node, entering bool) ast.WalkStatus
 	if entering {
 		if 0 == r.DisableTags {
 			r.WriteString("<img src=\"")
-			destTokens := node.ChildByType(ast.NodeLinkDest).Tokens
-			destTokens = r.Tree.Context.LinkPath(destTokens)
+			link := r.Tree.Context.ResolveLink(node)
 			if "" != r.Option.ImageLazyLoading {
 				r.Write(html.EscapeHTML(util.StrToBytes(r.Option.ImageLazyLoading)))
 				r.WriteString("\" data-src=\"")
 			}
-			r.Write(html.EscapeHTML(destTokens))
-			r.WriteString("\" alt=\"")
+			r.Write(html.EscapeHTML(util.StrToBytes(link.Dest)))
+			r.WriteByte(lex.ItemDoublequote)
+			for _, attr := range link.Attrs {
+				r.WriteString(" " + attr[0] + "=\"")
+				r.Write(html.EscapeHTML(util.StrToBytes(attr[1])))
+				r.WriteByte(lex.ItemDoublequote)
+			}
+			r.WriteString(" alt=\"")
 		}
 		r.DisableTags++
 		return ast.WalkContinue
@@ -585,16 +642,7 @@ func (r *HtmlRenderer) renderImage(node *ast.Node, entering bool) ast.WalkStatus
 		}
 		r.WriteString(" />")
 
-		if r.Option.Sanitize {
-			buf := r.Writer.Bytes()
-			idx := bytes.LastIndex(buf, []byte("<img src="))
-			imgBuf := buf[idx:]
-			if r.Option.Sanitize {
-				imgBuf = sanitize(imgBuf)
-			}
-			r.Writer.Truncate(idx)
-			r.Writer.Write(imgBuf)
-		}
+		r.sanitizeFrom(bytes.LastIndex(r.Writer.Bytes(), []byte("<img src=")))
 	}
 	return ast.WalkContinue
 }
@@ -603,16 +651,23 @@ func (r *HtmlRenderer) renderLink(node *ast.Node, entering bool) ast.WalkStatus
 	if entering {
 		r.LinkTextAutoSpacePrevious(node)
 
-		dest := node.ChildByType(ast.NodeLinkDest)
-		destTokens := dest.Tokens
-		destTokens = r.Tree.Context.LinkPath(destTokens)
-		attrs := [][]string{{"href", util.BytesToStr(html.EscapeHTML(destTokens))}}
-		if title := node.ChildByType(ast.NodeLinkTitle); nil != title && nil != title.Tokens {
-			attrs = append(attrs, []string{"title", util.BytesToStr(html.EscapeHTML(title.Tokens))})
+		if 0 == r.DisableTags {
+			link := r.Tree.Context.ResolveLink(node)
+			attrs := [][]string{{"href", util.BytesToStr(html.EscapeHTML(util.StrToBytes(link.Dest)))}}
+			if title := node.ChildByType(ast.NodeLinkTitle); nil != title && nil != title.Tokens {
+				attrs = append(attrs, []string{"title", util.BytesToStr(html.EscapeHTML(title.Tokens))})
+			}
+			for _, attr := range link.Attrs {
+				attrs = append(attrs, []string{attr[0], util.BytesToStr(html.EscapeHTML(util.StrToBytes(attr[1])))})
+			}
+			offset := r.Writer.Len()
+			r.tag("a", attrs, false)
+			r.sanitizeFrom(offset)
 		}
-		r.tag("a", attrs, false)
 	} else {
+		offset := r.Writer.Len()
 		r.tag("/a", nil, false)
+		r.sanitizeFrom(offset)
 
 		r.LinkTextAutoSpaceNext(node)
 	}
@@ -623,7 +678,7 @@ func (r *HtmlRenderer) renderHTML(node *ast.Node, entering bool) ast.WalkStatus
 	r.Newline()
 	tokens := node.Tokens
 	if r.Option.Sanitize {
-		tokens = sanitize(tokens)
+		tokens = r.sanitize(tokens)
 	}
 	r.Write(tokens)
 	r.Newline()
@@ -633,7 +688,7 @@ func (r *HtmlRenderer) renderHTML(node *ast.Node, entering bool) ast.WalkStatus
 func (r *HtmlRenderer) renderInlineHTML(node *ast.Node, entering bool) ast.WalkStatus {
 	tokens := node.Tokens
 	if r.Option.Sanitize {
-		tokens = sanitize(tokens)
+		tokens = r.sanitize(tokens)
 	}
 	r.Write(tokens)
 	return ast.WalkStop
@@ -647,6 +702,9 @@ func (r *HtmlRenderer) renderParagraph(node *ast.Node, entering bool) ast.WalkSt
 	if grandparent := node.Parent.Parent; nil != grandparent && ast.NodeList == grandparent.Type && grandparent.Tight { // List.ListItem.Paragraph
 		return ast.WalkContinue
 	}
+	if grandparent := node.Parent.Parent; nil != grandparent && ast.NodeDefinitionList == grandparent.Type && grandparent.Tight { // DefinitionList.DefinitionDescription.Paragraph
+		return ast.WalkContinue
+	}
 
 	if entering {
 		r.Newline()
@@ -788,6 +846,60 @@ func (r *HtmlRenderer) renderBlockquoteMarker(node *ast.Node, entering bool) ast
 	return ast.WalkStop
 }
 
+func (r *HtmlRenderer) renderAdmonition(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Newline()
+		attrs := [][]string{{"class", "admonition " + node.AdmonitionType}}
+		attrs = append(attrs, node.KramdownIAL...)
+		r.tag("div", attrs, false)
+		r.Newline()
+		r.tag("p", [][]string{{"class", "admonition-title"}}, false)
+		r.Write(html.EscapeHTML(admonitionTitle(node)))
+		r.tag("/p", nil, false)
+		r.Newline()
+	} else {
+		r.Newline()
+		r.WriteString("</div>")
+		r.Newline()
+	}
+	return ast.WalkContinue
+}
+
+func (r *HtmlRenderer) renderDefinitionList(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Newline()
+		r.tag("dl", node.KramdownIAL, false)
+		r.Newline()
+	} else {
+		r.Newline()
+		r.tag("/dl", nil, false)
+		r.Newline()
+	}
+	return ast.WalkContinue
+}
+
+func (r *HtmlRenderer) renderDefinitionTerm(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Newline()
+		r.tag("dt", nil, false)
+	} else {
+		r.tag("/dt", nil, false)
+		r.Newline()
+	}
+	return ast.WalkContinue
+}
+
+func (r *HtmlRenderer) renderDefinitionDescription(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Newline()
+		r.tag("dd", nil, false)
+	} else {
+		r.tag("/dd", nil, false)
+		r.Newline()
+	}
+	return ast.WalkContinue
+}
+
 var headingLevel = " 123456"
 
 func (r *HtmlRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStatus {
@@ -800,6 +912,9 @@ func (r *HtmlRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStat
 			r.WriteString(" id=\"" + id + "\"")
 		}
 		r.WriteString(">")
+		if "" != node.HeadingNumber {
+			r.WriteString(node.HeadingNumber + " ")
+		}
 	} else {
 		if r.Option.HeadingAnchor {
 			id := HeadingID(node)
@@ -0,0 +1,64 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// OutlineHeading 描述了文档大纲中的一个标题。
+type OutlineHeading struct {
+	Level    int               // 标题级别，1~6
+	Text     string            // 标题文本
+	ID       string            // 标题 ID
+	Number   string            `json:",omitempty"` // 标题编号，开启标题编号时有效
+	Position ast.Position      // 标题在原始输入中的起始位置
+	Children []*OutlineHeading `json:",omitempty"` // 下级标题
+
+	Node *ast.Node `json:"-"` // 标题节点
+}
+
+// Outline 返回语法树 tree 的文档大纲。
+//
+// 仅有顶级标题（直接挂在根上的）才纳入大纲，标题挂在前面最近的级别更高的标题下，所以跳级的标题（比如 h1 后直接是 h3）是 h1 的直接下级。
+func Outline(tree *parse.Tree) (ret []*OutlineHeading) {
+	GenerateHeadingIDs(tree.Root, tree.Context.Option.HeadingIDSlugger)
+	var stack []*OutlineHeading
+	for n := tree.Root.FirstChild; nil != n; n = n.Next {
+		if ast.NodeHeading != n.Type {
+			continue
+		}
+
+		heading := &OutlineHeading{
+			Level:    n.HeadingLevel,
+			Text:     strings.ReplaceAll(n.Text(), util.Caret, ""),
+			ID:       HeadingID(n),
+			Number:   n.HeadingNumber,
+			Position: n.StartPos,
+			Node:     n,
+		}
+		for 0 < len(stack) && stack[len(stack)-1].Level >= heading.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if 0 < len(stack) {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, heading)
+		} else {
+			ret = append(ret, heading)
+		}
+		stack = append(stack, heading)
+	}
+	return
+}
@@ -12,12 +12,14 @@ package render
 
 import (
 	"bytes"
+	"io"
 	"strconv"
 	"strings"
 	"unicode"
 	"unicode/utf8"
 
 	"github.com/88250/lute/ast"
+	"github.com/88250/lute/html"
 	"github.com/88250/lute/lex"
 	"github.com/88250/lute/parse"
 	"github.com/88250/lute/util"
@@ -35,6 +37,14 @@ type Renderer interface {
 	Render() (output []byte)
 }
 
+// StreamRenderer 描述了流式渲染器接口。
+type StreamRenderer interface {
+	Renderer
+
+	// RenderTo 渲染输出到 w，每渲染完一个顶层块节点后就写入 w 一次。
+	RenderTo(w io.Writer) (err error)
+}
+
 // BaseRenderer 描述了渲染器结构。
 type BaseRenderer struct {
 	Option              *parse.Options                   // 解析渲染选项
@@ -45,6 +55,9 @@ type BaseRenderer struct {
 	LastOut             byte                             // 最新输出的一个字节
 	Tree                *parse.Tree                      // 待渲染的树
 	DisableTags         int                              // 标签嵌套计数器，用于判断不可能出现标签嵌套的情况，比如语法树允许图片节点包含链接节点，但是 HTML <img> 不能包含 <a>
+	rendering           *ast.Node                        // 正在渲染的节点，用于定位渲染时发生的 panic
+	written             int                              // 流式输出时已经写出的字节数，用于限制输出大小
+	err                 error                            // 超出输出大小限制或者被取消导致渲染中止时的错误
 }
 
 // NewBaseRenderer 构造一个 BaseRenderer。
@@ -57,33 +70,126 @@ func NewBaseRenderer(tree *parse.Tree) *BaseRenderer {
 
 // Render 从根节点开始遍历并渲染。
 func (r *BaseRenderer) Render() (output []byte) {
+	r.err = r.render(nil)
+	output = r.Writer.Bytes()
+	return
+}
+
+// Err 返回最近一次 Render 因为超出 MaxOutputBytes 输出大小限制（*parse.LimitError）或者语法树关联的 ctx 被取消而中止时的错误，此时输出是截断后的结果。
+func (r *BaseRenderer) Err() error {
+	return r.err
+}
+
+// RenderTo 从根节点开始遍历并渲染，每渲染完一个顶层块节点后就将输出缓冲写入 w 并清空，这样渲染大文档时内存占用不会随输出增长。
+func (r *BaseRenderer) RenderTo(w io.Writer) (err error) {
+	if err = r.render(w); nil != err {
+		return
+	}
+	return r.Flush(w)
+}
+
+// Flush 将输出缓冲中的内容写入 w 并清空输出缓冲。
+func (r *BaseRenderer) Flush(w io.Writer) (err error) {
+	if 0 < r.Writer.Len() {
+		r.written += r.Writer.Len()
+		_, err = w.Write(r.Writer.Bytes())
+		r.Writer.Reset()
+	}
+	return
+}
+
+// render 从根节点开始遍历并渲染，如果 w 不为 nil 则在离开顶层块节点时将输出缓冲写入 w。
+func (r *BaseRenderer) render(w io.Writer) (err error) {
 	r.LastOut = lex.ItemNewline
 	r.Writer = &bytes.Buffer{}
 	r.Writer.Grow(4096)
+	r.written = 0
+	GenerateHeadingIDs(r.Tree.Root, r.Option.HeadingIDSlugger)
 
+	ctx, max := r.Tree.Context.Ctx, r.Option.MaxOutputBytes
 	ast.Walk(r.Tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
-		extRender := r.ExtRendererFuncs[n.Type]
-		if nil != extRender {
-			output, status := extRender(n, entering)
-			r.WriteString(output)
-			return status
+		if nil != err {
+			return ast.WalkStop
 		}
-
-		render := r.RendererFuncs[n.Type]
-		if nil == render {
-			if nil != r.DefaultRendererFunc {
-				return r.DefaultRendererFunc(n, entering)
-			} else {
-				return r.renderDefault(n, entering)
+		if nil != ctx && entering && r.Tree.Root == n.Parent {
+			if err = ctx.Err(); nil != err {
+				return ast.WalkStop
 			}
 		}
-		return render(n, entering)
+
+		status := r.renderNode(n, entering)
+		if 0 < max && max < r.written+r.Writer.Len() {
+			r.Writer.Truncate(max - r.written)
+			err = &parse.LimitError{Name: "MaxOutputBytes", Limit: max}
+			return ast.WalkStop
+		}
+		if nil != w && !entering && r.Tree.Root == n.Parent {
+			err = r.Flush(w)
+		}
+		return status
 	})
+	return
+}
 
-	output = r.Writer.Bytes()
+// trimWriter 用于流式输出时去掉首尾的空白（空格、制表符和换行），效果和 bytes.Trim(output, " \t\n") 一致。
+type trimWriter struct {
+	w       io.Writer
+	started bool   // 是否已经输出过非空白字符
+	pending []byte // 暂存的空白，等到后续出现非空白字符时才输出
+}
+
+func (tw *trimWriter) Write(p []byte) (n int, err error) {
+	n = len(p)
+	for 0 < len(p) {
+		i := bytes.IndexFunc(p, func(r rune) bool { return ' ' != r && '\t' != r && '\n' != r })
+		if 0 > i {
+			if tw.started {
+				tw.pending = append(tw.pending, p...)
+			}
+			return
+		}
+
+		if tw.started {
+			tw.pending = append(tw.pending, p[:i]...)
+			if 0 < len(tw.pending) {
+				if _, err = tw.w.Write(tw.pending); nil != err {
+					return
+				}
+				tw.pending = tw.pending[:0]
+			}
+		}
+		tw.started = true
+
+		p = p[i:]
+		j := bytes.LastIndexFunc(p, func(r rune) bool { return ' ' != r && '\t' != r && '\n' != r }) + 1
+		if _, err = tw.w.Write(p[:j]); nil != err {
+			return
+		}
+		p = p[j:]
+	}
 	return
 }
 
+func (r *BaseRenderer) renderNode(n *ast.Node, entering bool) ast.WalkStatus {
+	r.rendering = n
+	extRender := r.ExtRendererFuncs[n.Type]
+	if nil != extRender {
+		output, status := extRender(n, entering)
+		r.WriteString(output)
+		return status
+	}
+
+	render := r.RendererFuncs[n.Type]
+	if nil == render {
+		if nil != r.DefaultRendererFunc {
+			return r.DefaultRendererFunc(n, entering)
+		} else {
+			return r.renderDefault(n, entering)
+		}
+	}
+	return render(n, entering)
+}
+
 func (r *BaseRenderer) renderDefault(n *ast.Node, entering bool) ast.WalkStatus {
 	r.WriteString("not found render function for node [type=" + n.Type.String() + ", Tokens=" + util.BytesToStr(n.Tokens) + "]")
 	return ast.WalkContinue
@@ -189,62 +295,30 @@ func SubStr(str string, length int) (ret string) {
 	return
 }
 
+// HeadingID 返回标题 heading 的 ID，还没有生成的话使用 UnicodeSlugger 为整个文档生成标题 ID。
 func HeadingID(heading *ast.Node) (ret string) {
 	if 0 == len(util.StrToBytes(heading.HeadingNormalizedID)) {
-		headingID0(heading)
-	}
-	return heading.HeadingNormalizedID
-}
-
-func headingID0(heading *ast.Node) {
-	var root *ast.Node
-	for root = heading.Parent; ast.NodeDocument != root.Type; root = root.Parent {
-	}
-
-	idOccurs := map[string]int{}
-	ast.Walk(root, func(n *ast.Node, entering bool) ast.WalkStatus {
-		if entering {
-			if ast.NodeHeading == n.Type {
-				id := normalizeHeadingID(n)
-				for ; 0 < idOccurs[id]; id += "-" {
-				}
-				n.HeadingNormalizedID = id
-				idOccurs[id] = 1
-			}
-		}
-		return ast.WalkContinue
-	})
-}
-
-func normalizeHeadingID(heading *ast.Node) (ret string) {
-	headingID := heading.ChildByType(ast.NodeHeadingID)
-	var id string
-	if nil != headingID {
-		id = util.BytesToStr(headingID.Tokens)
-	}
-	if "" == id {
-		id = heading.Text()
-	}
-
-	id = strings.TrimLeft(id, "#")
-	id = strings.ReplaceAll(id, util.Caret, "")
-	for _, r := range id {
-		if unicode.IsLetter(r) || unicode.IsDigit(r) {
-			ret += string(r)
-		} else {
-			ret += "-"
+		var root *ast.Node
+		for root = heading.Parent; ast.NodeDocument != root.Type; root = root.Parent {
 		}
+		GenerateHeadingIDs(root, nil)
 	}
-	return
+	return heading.HeadingNormalizedID
 }
 
+// headings 返回生成 ToC 的标题，标题层级超过 ToCDepth 的话不纳入。
 func (r *BaseRenderer) headings() (ret []*ast.Node) {
-	// 仅有顶级标题（直接挂在根上的）才纳入 ToC 生成，挂在其他元素下的标题不生成 ToC https://github.com/88250/lute/issues/38
-	for n := r.Tree.Root.FirstChild; nil != n; n = n.Next {
-		if ast.NodeHeading == n.Type {
-			ret = append(ret, n)
+	var walk func(outline []*OutlineHeading, depth int)
+	walk = func(outline []*OutlineHeading, depth int) {
+		if 0 < r.Option.ToCDepth && depth > r.Option.ToCDepth {
+			return
+		}
+		for _, heading := range outline {
+			ret = append(ret, heading.Node)
+			walk(heading.Children, depth+1)
 		}
 	}
+	walk(Outline(r.Tree), 1)
 	return
 }
 
@@ -289,6 +363,29 @@ func (r *BaseRenderer) renderListStyle(node *ast.Node, attrs *[][]string) {
 	}
 }
 
+// blockQueryEmbedResult 使用宿主应用设置的解析器获取内容块查询嵌入 node 的查询结果，并返回结果渲染后的 HTML。
+// 没有设置解析器的话返回 nil，解析器返回错误的话返回错误信息段落。
+//
+// 该方法只在内置的 NodeBlockQueryEmbed 渲染函数中调用，用户注册的扩展渲染函数会绕过这里。
+func (r *BaseRenderer) blockQueryEmbedResult(node *ast.Node) []byte {
+	resolver := r.Option.BlockQueryEmbedResolver
+	if nil == resolver {
+		return nil
+	}
+
+	script := parse.BlockQueryEmbedScript(node.ChildByType(ast.NodeBlockQueryEmbedScript).Tokens)
+	markdown, err := resolver(script)
+	if nil != err {
+		return []byte("<p class=\"block-query-embed__error\">" + html.EscapeString(err.Error()) + "</p>\n")
+	}
+
+	option := *r.Option
+	option.BlockQueryEmbedResolver = nil // 查询结果中的查询嵌入不再展开，避免循环查询
+	option.VditorWYSIWYG, option.VditorIR, option.VditorSV = false, false, false
+	tree := parse.Parse("", []byte(markdown), &option)
+	return NewHtmlRenderer(tree).Render()
+}
+
 func (r *BaseRenderer) isLastNode(treeRoot, node *ast.Node) bool {
 	if treeRoot == node {
 		return true
@@ -340,3 +437,41 @@ func (r *BaseRenderer) NodeAttrsStr(node *ast.Node) (ret string) {
 	}
 	return
 }
+
+// admonitionOpenMarker 返回提示块 node 的开始标记符，比如 :::tip 标题 或者 GitHub 风格的 [!NOTE]。
+// trimFrontMatterContent 去掉 Front Matter 内容首尾的空白，但保留首行的缩进，JSON Front Matter 的首行通常是缩进的。
+func trimFrontMatterContent(tokens []byte) []byte {
+	tokens = bytes.TrimRightFunc(tokens, unicode.IsSpace)
+	content := bytes.TrimLeftFunc(tokens, unicode.IsSpace)
+	if newline := bytes.LastIndexByte(tokens[:len(tokens)-len(content)], '\n'); 0 <= newline {
+		return tokens[newline+1:]
+	}
+	return tokens
+}
+
+func admonitionOpenMarker(node *ast.Node) string {
+	if 0 == node.AdmonitionFenceLen {
+		return "[!" + strings.ToUpper(node.AdmonitionType) + "]"
+	}
+	ret := strings.Repeat(":", node.AdmonitionFenceLen) + node.AdmonitionType
+	if 0 < len(node.AdmonitionTitle) {
+		ret += " " + util.BytesToStr(node.AdmonitionTitle)
+	}
+	return ret
+}
+
+// admonitionMarker 返回提示块 node 在 Vditor DOM 中的 data-marker 属性值，用于从 DOM 还原提示块语法。
+func admonitionMarker(node *ast.Node) string {
+	if 0 == node.AdmonitionFenceLen {
+		return ">"
+	}
+	return strings.Repeat(":", node.AdmonitionFenceLen)
+}
+
+// admonitionTitle 返回提示块 node 的标题，没有标题的话使用首字母大写的类型。
+func admonitionTitle(node *ast.Node) []byte {
+	if 0 < len(node.AdmonitionTitle) || "" == node.AdmonitionType {
+		return node.AdmonitionTitle
+	}
+	return []byte(strings.ToUpper(node.AdmonitionType[:1]) + node.AdmonitionType[1:])
+}
@@ -13,34 +13,193 @@ package render
 import (
 	"bytes"
 	"io"
+	"net/url"
 	"strings"
 
 	"github.com/88250/lute/html"
 	"github.com/88250/lute/util"
 )
 
-// 没有实现可扩展的策略，仅过滤不安全的标签和属性。
+// SanitizePolicy 描述了 HTML 安全过滤策略，包括允许的元素、元素允许的属性、链接地址允许的协议、iframe 允许的域名以及允许的 class 和 style。
+// 策略的配置方法都返回策略本身，可以链式调用。配置完成的策略可以在多个 goroutine 中并发使用，但不能再修改。
 // 鸣谢 https://github.com/microcosm-cc/bluemonday
+type SanitizePolicy struct {
+	allowAllElements bool                       // 是否允许所有元素
+	allowAllAttrs    bool                       // 是否允许除事件属性以外的所有属性
+	elements         map[string]bool            // 允许的元素
+	attrs            map[string]map[string]bool // 元素允许的属性，键为空字符串时表示所有元素都允许的属性
+	skipContent      map[string]bool            // 需要连同内容一起去掉的元素
+	urlSchemes       map[string]bool            // 链接地址允许的协议，为 nil 时仅过滤 src 中的 JavaScript 和 SVG
+	relativeURLs     bool                       // 链接地址是否允许使用相对地址
+	noFollowNoOpener bool                       // 是否为链接添加 rel="nofollow noopener"
+	iframeHosts      map[string]bool            // iframe 允许的域名（包括子域名），为 nil 时不限制
+	classes          []string                   // 允许的 class，以 * 结尾的按前缀匹配，为 nil 时不过滤
+	styles           map[string]bool            // 允许的 style 属性，为 nil 时不过滤
+}
+
+// NewSanitizePolicy 创建一个不允许任何元素和属性的过滤策略，脚本、样式等元素会连同内容一起去掉。
+func NewSanitizePolicy() *SanitizePolicy {
+	ret := &SanitizePolicy{elements: map[string]bool{}, attrs: map[string]map[string]bool{}, skipContent: map[string]bool{}}
+	ret.SkipElementsContent("frame", "frameset", "noembed", "noframes", "noscript", "nostyle", "object", "script", "style", "title")
+	return ret
+}
+
+// TrustedPolicy 返回用于可信内容的过滤策略：允许所有元素（包括任意来源的 iframe）和属性，仅去掉脚本、样式等元素和事件属性，并过滤 src 中的 JavaScript 和 SVG。
+// 启用安全过滤但是没有设置过滤策略时使用该策略。
+func TrustedPolicy() *SanitizePolicy {
+	ret := NewSanitizePolicy()
+	ret.allowAllElements = true
+	ret.allowAllAttrs = true
+	return ret
+}
+
+// UGCPolicy 返回用于用户生成内容（User Generated Content）的过滤策略：仅允许常用的排版元素，链接地址只能使用 http、https、mailto 协议或者相对地址，
+// 链接会添加 rel="nofollow noopener"，不允许 iframe，class 仅允许 language-*，style 仅允许 text-align。
+func UGCPolicy() *SanitizePolicy {
+	ret := NewSanitizePolicy()
+	ret.AllowElements("p", "br", "hr", "h1", "h2", "h3", "h4", "h5", "h6", "blockquote", "pre", "code", "em", "strong", "b", "i", "u",
+		"del", "s", "ins", "mark", "sub", "sup", "kbd", "abbr", "small", "span", "div", "ul", "ol", "li", "dl", "dt", "dd",
+		"table", "thead", "tbody", "tfoot", "tr", "th", "td", "caption", "details", "summary", "figure", "figcaption", "a", "img")
+	ret.AllowAttrs("title")
+	ret.AllowAttrs("href", "a")
+	ret.AllowAttrs("src", "img")
+	ret.AllowAttrs("data-src", "img")
+	ret.AllowAttrs("alt", "img")
+	ret.AllowAttrs("width", "img")
+	ret.AllowAttrs("height", "img")
+	ret.AllowAttrs("start", "ol")
+	ret.AllowAttrs("open", "details")
+	ret.AllowAttrs("colspan", "th", "td")
+	ret.AllowAttrs("rowspan", "th", "td")
+	ret.AllowAttrs("align", "th", "td")
+	ret.AllowURLSchemes("http", "https", "mailto")
+	ret.AllowRelativeURLs(true)
+	ret.RequireNoFollowNoOpener(true)
+	ret.AllowClasses("language-*")
+	ret.AllowStyles("text-align")
+	return ret
+}
+
+// StrictPolicy 返回严格的过滤策略：去掉所有元素，仅保留转义后的文本。
+func StrictPolicy() *SanitizePolicy {
+	return NewSanitizePolicy()
+}
+
+// AllowElements 允许元素 names。
+func (p *SanitizePolicy) AllowElements(names ...string) *SanitizePolicy {
+	for _, name := range names {
+		p.elements[strings.ToLower(name)] = true
+	}
+	return p
+}
 
-var setOfElementsToSkipContent = map[string]interface{}{
-	"frame":    nil,
-	"frameset": nil,
-	//"iframe":   nil,
-	"noembed":  nil,
-	"noframes": nil,
-	"noscript": nil,
-	"nostyle":  nil,
-	"object":   nil,
-	"script":   nil,
-	"style":    nil,
-	"title":    nil,
+// AllowAttrs 允许元素 elements 使用属性 attr，不指定 elements 的话所有元素都允许使用该属性。事件属性总是会被去掉。
+func (p *SanitizePolicy) AllowAttrs(attr string, elements ...string) *SanitizePolicy {
+	if 1 > len(elements) {
+		elements = []string{""}
+	}
+	attr = strings.ToLower(attr)
+	for _, element := range elements {
+		element = strings.ToLower(element)
+		if nil == p.attrs[element] {
+			p.attrs[element] = map[string]bool{}
+		}
+		p.attrs[element][attr] = true
+	}
+	return p
 }
 
-func sanitize(tokens []byte) []byte {
+// AllowURLSchemes 设置链接地址（href、src 等属性）允许使用的协议，比如 http、https 和 mailto。
+func (p *SanitizePolicy) AllowURLSchemes(schemes ...string) *SanitizePolicy {
+	if nil == p.urlSchemes {
+		p.urlSchemes = map[string]bool{}
+	}
+	for _, scheme := range schemes {
+		p.urlSchemes[strings.ToLower(scheme)] = true
+	}
+	return p
+}
+
+// AllowRelativeURLs 设置链接地址是否允许使用相对地址，仅在设置了允许的协议时生效。
+func (p *SanitizePolicy) AllowRelativeURLs(b bool) *SanitizePolicy {
+	p.relativeURLs = b
+	return p
+}
+
+// RequireNoFollowNoOpener 设置是否为带有 href 的 a 元素添加 rel="nofollow noopener"，已有的 rel 属性会被替换。
+func (p *SanitizePolicy) RequireNoFollowNoOpener(b bool) *SanitizePolicy {
+	p.noFollowNoOpener = b
+	return p
+}
+
+// AllowIframeHosts 允许嵌入域名 hosts（包括子域名）下的 iframe，src 不是这些域名的 iframe 会连同内容一起去掉。
+func (p *SanitizePolicy) AllowIframeHosts(hosts ...string) *SanitizePolicy {
+	if nil == p.iframeHosts {
+		p.iframeHosts = map[string]bool{}
+	}
+	for _, host := range hosts {
+		p.iframeHosts[strings.ToLower(host)] = true
+	}
+	p.AllowElements("iframe")
+	for _, attr := range []string{"src", "width", "height", "frameborder", "allowfullscreen", "scrolling", "border", "framespacing"} {
+		p.AllowAttrs(attr, "iframe")
+	}
+	return p
+}
+
+// AllowClasses 允许所有元素使用 class 属性中的 classes，以 * 结尾的按前缀匹配，比如 language-*。其他 class 会被去掉。
+func (p *SanitizePolicy) AllowClasses(classes ...string) *SanitizePolicy {
+	p.classes = append(p.classes, classes...)
+	return p
+}
+
+// AllowStyles 允许所有元素使用 style 属性中的 CSS 属性 properties，其他 CSS 属性以及包含 url()、expression() 的值会被去掉。
+func (p *SanitizePolicy) AllowStyles(properties ...string) *SanitizePolicy {
+	if nil == p.styles {
+		p.styles = map[string]bool{}
+	}
+	for _, property := range properties {
+		p.styles[strings.ToLower(property)] = true
+	}
+	return p
+}
+
+// SkipElementsContent 设置元素 names 需要连同内容一起去掉。
+func (p *SanitizePolicy) SkipElementsContent(names ...string) *SanitizePolicy {
+	for _, name := range names {
+		p.skipContent[strings.ToLower(name)] = true
+	}
+	return p
+}
+
+// trustedPolicy 是没有设置过滤策略时使用的默认策略。
+var trustedPolicy = TrustedPolicy()
+
+// sanitize 使用配置的过滤策略过滤 tokens，没有配置的话使用 TrustedPolicy。
+func (r *BaseRenderer) sanitize(tokens []byte) []byte {
+	if nil != r.Option.Sanitizer {
+		return r.Option.Sanitizer.Sanitize(tokens)
+	}
+	return trustedPolicy.Sanitize(tokens)
+}
+
+// sanitizeFrom 在开启 Sanitize 时过滤输出缓冲中从 offset 开始的内容，用于过滤渲染生成的标签。
+func (r *BaseRenderer) sanitizeFrom(offset int) {
+	if !r.Option.Sanitize || 0 > offset || offset >= r.Writer.Len() {
+		return
+	}
+	tokens := r.sanitize(r.Writer.Bytes()[offset:])
+	r.Writer.Truncate(offset)
+	r.Writer.Write(tokens)
+}
+
+// Sanitize 使用该策略过滤 HTML 片段 tokens，返回过滤后的 HTML。
+func (p *SanitizePolicy) Sanitize(tokens []byte) []byte {
 	var (
 		buff                     bytes.Buffer
 		skipElementContent       bool
 		skippingElementsCount    int64
+		skippingIframesCount     int64
 		mostRecentlyStartedToken string
 	)
 
@@ -72,31 +231,29 @@ func sanitize(tokens []byte) []byte {
 		case html.StartTagToken:
 			mostRecentlyStartedToken = token.Data
 
-			if _, ok := setOfElementsToSkipContent[token.Data]; ok {
+			skipIframe := "iframe" == token.Data && !p.allowIframe(token.Attr)
+			if p.skipContent[token.Data] || skipIframe {
+				if skipIframe {
+					skippingIframesCount++
+				}
 				skipElementContent = true
 				skippingElementsCount++
 				buff.WriteString(" ")
 				break
 			}
 
-			if len(token.Attr) != 0 {
-				token.Attr = sanitizeAttrs(token.Attr)
-			}
-
-			if !skipElementContent {
-				// do not escape multiple query parameters
-				if linkable(token.Data) {
-					writeLinkableBuf(&buff, &token)
-				} else {
-					buff.WriteString(token.String())
-				}
+			if !skipElementContent && p.allowElement(token.Data) {
+				p.writeTag(&buff, &token)
 			}
 		case html.EndTagToken:
 			if mostRecentlyStartedToken == token.Data {
 				mostRecentlyStartedToken = ""
 			}
 
-			if _, ok := setOfElementsToSkipContent[token.Data]; ok {
+			if p.skipContent[token.Data] || ("iframe" == token.Data && 0 < skippingIframesCount) {
+				if "iframe" == token.Data {
+					skippingIframesCount--
+				}
 				skippingElementsCount--
 				if skippingElementsCount == 0 {
 					skipElementContent = false
@@ -105,21 +262,16 @@ func sanitize(tokens []byte) []byte {
 				break
 			}
 
-			if !skipElementContent {
+			if !skipElementContent && p.allowElement(token.Data) {
 				buff.WriteString(token.String())
 			}
 		case html.SelfClosingTagToken:
-			if len(token.Attr) != 0 {
-				token.Attr = sanitizeAttrs(token.Attr)
+			if p.skipContent[token.Data] || ("iframe" == token.Data && !p.allowIframe(token.Attr)) {
+				break
 			}
 
-			if !skipElementContent {
-				// do not escape multiple query parameters
-				if linkable(token.Data) {
-					writeLinkableBuf(&buff, &token)
-				} else {
-					buff.WriteString(token.String())
-				}
+			if !skipElementContent && p.allowElement(token.Data) {
+				p.writeTag(&buff, &token)
 			}
 		case html.TextToken:
 			if !skipElementContent {
@@ -141,6 +293,69 @@ func sanitize(tokens []byte) []byte {
 	}
 }
 
+// writeTag 过滤开始标签 token 的属性后写入 buff。
+func (p *SanitizePolicy) writeTag(buff *bytes.Buffer, token *html.Token) {
+	if len(token.Attr) != 0 {
+		token.Attr = p.sanitizeAttrs(token.Data, token.Attr)
+	}
+	if "a" == token.Data && p.noFollowNoOpener {
+		p.addRel(token)
+	}
+
+	// do not escape multiple query parameters
+	if linkable(token.Data) {
+		writeLinkableBuf(buff, token)
+	} else {
+		buff.WriteString(token.String())
+	}
+}
+
+func (p *SanitizePolicy) allowElement(name string) bool {
+	return p.allowAllElements || p.elements[name]
+}
+
+// allowIframe 判断 src 为 attrs 中 src 属性值的 iframe 是否在允许的域名下。
+func (p *SanitizePolicy) allowIframe(attrs []*html.Attribute) bool {
+	if nil == p.iframeHosts {
+		return true
+	}
+
+	for _, attr := range attrs {
+		if "src" != attr.Key {
+			continue
+		}
+		u, err := url.Parse(strings.TrimSpace(attr.Val))
+		if nil != err || ("" != u.Scheme && "http" != strings.ToLower(u.Scheme) && "https" != strings.ToLower(u.Scheme)) {
+			return false
+		}
+		host := strings.ToLower(u.Hostname())
+		for allowed := range p.iframeHosts {
+			if host == allowed || strings.HasSuffix(host, "."+allowed) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// addRel 为带有 href 的 a 元素 token 设置 rel="nofollow noopener"。
+func (p *SanitizePolicy) addRel(token *html.Token) {
+	var hasHref bool
+	var attrs []*html.Attribute
+	for _, attr := range token.Attr {
+		if "href" == attr.Key {
+			hasHref = true
+		}
+		if "rel" != attr.Key {
+			attrs = append(attrs, attr)
+		}
+	}
+	if hasHref {
+		token.Attr = append(attrs, &html.Attribute{Key: "rel", Val: "nofollow noopener"})
+	}
+}
+
 func linkable(elementName string) bool {
 	switch elementName {
 	case "a", "area", "blockquote", "img", "link", "script":
@@ -179,15 +394,32 @@ func writeLinkableBuf(buff *bytes.Buffer, token *html.Token) {
 	buff.WriteString(tokenBuff.String())
 }
 
-func sanitizeAttrs(attrs []*html.Attribute) (ret []*html.Attribute) {
+func (p *SanitizePolicy) sanitizeAttrs(element string, attrs []*html.Attribute) (ret []*html.Attribute) {
 	for _, attr := range attrs {
-		if !allowAttr(attr.Key) {
+		if !p.allowAttr(element, attr.Key) {
 			continue
 		}
-		if "src" == attr.Key {
-			if strings.HasPrefix(attr.Val, "data:image/svg+xml") || strings.HasPrefix(attr.Val, "javascript") {
+
+		switch attr.Key {
+		case "href", "src", "data-src", "cite", "action", "poster":
+			if "iframe" == element && "src" == attr.Key && nil != p.iframeHosts {
+				break // 已经按照允许的域名检查过
+			}
+			if !p.allowURL(attr.Key, attr.Val) {
 				continue
 			}
+		case "class":
+			if nil != p.classes {
+				if attr.Val = p.filterClasses(attr.Val); "" == attr.Val {
+					continue
+				}
+			}
+		case "style":
+			if nil != p.styles {
+				if attr.Val = p.filterStyles(attr.Val); "" == attr.Val {
+					continue
+				}
+			}
 		}
 
 		ret = append(ret, attr)
@@ -195,13 +427,70 @@ func sanitizeAttrs(attrs []*html.Attribute) (ret []*html.Attribute) {
 	return
 }
 
-func allowAttr(attrName string) bool {
-	for name := range eventAttrs {
-		if attrName == name {
-			return false
+func (p *SanitizePolicy) allowAttr(element, attrName string) bool {
+	if util.CaretReplacement == attrName {
+		return true
+	}
+	if _, ok := eventAttrs[attrName]; ok {
+		return false
+	}
+	if p.allowAllAttrs {
+		return true
+	}
+	if ("class" == attrName && nil != p.classes) || ("style" == attrName && nil != p.styles) {
+		return true
+	}
+	return p.attrs[""][attrName] || p.attrs[element][attrName]
+}
+
+// allowURL 判断属性 attrName 的链接地址 val 是否使用了允许的协议。
+func (p *SanitizePolicy) allowURL(attrName, val string) bool {
+	if nil == p.urlSchemes {
+		return "src" != attrName || !(strings.HasPrefix(val, "data:image/svg+xml") || strings.HasPrefix(val, "javascript"))
+	}
+
+	u, err := url.Parse(strings.TrimSpace(val))
+	if nil != err {
+		return false
+	}
+	if "" == u.Scheme {
+		return p.relativeURLs
+	}
+	return p.urlSchemes[strings.ToLower(u.Scheme)]
+}
+
+// filterClasses 去掉 class 属性值 val 中不允许的 class。
+func (p *SanitizePolicy) filterClasses(val string) string {
+	var ret []string
+	for _, class := range strings.Fields(val) {
+		for _, allowed := range p.classes {
+			if class == allowed || (strings.HasSuffix(allowed, "*") && strings.HasPrefix(class, allowed[:len(allowed)-1])) {
+				ret = append(ret, class)
+				break
+			}
+		}
+	}
+	return strings.Join(ret, " ")
+}
+
+// filterStyles 去掉 style 属性值 val 中不允许的 CSS 属性，以及可能加载外部资源或者执行脚本的值。
+func (p *SanitizePolicy) filterStyles(val string) string {
+	var ret []string
+	for _, declaration := range strings.Split(val, ";") {
+		colon := strings.Index(declaration, ":")
+		if 0 > colon {
+			continue
+		}
+		property := strings.ToLower(strings.TrimSpace(declaration[:colon]))
+		value := strings.TrimSpace(declaration[colon+1:])
+		lowerValue := strings.ToLower(value)
+		if !p.styles[property] || "" == value || strings.Contains(lowerValue, "url(") || strings.Contains(lowerValue, "expression(") ||
+			strings.Contains(lowerValue, "javascript:") || strings.Contains(value, "\\") {
+			continue
 		}
+		ret = append(ret, property+": "+value)
 	}
-	return true
+	return strings.Join(ret, "; ")
 }
 
 // HTML 事件属性。https://www.w3schools.com/tags/ref_eventattributes.asp
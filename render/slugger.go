@@ -0,0 +1,132 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// GenerateHeadingIDs 使用 slugger 为 root 下的所有标题生成 ID，slugger 为 nil 的话使用 UnicodeSlugger。
+//
+// 自定义标题 ID（{#id}）优先占用，根据标题文本生成的 ID 和已有 ID 重复的话依次追加 -1、-2 等后缀，保证文档内的 ID 唯一，
+// 所以 ToC、标题锚点和标题本身使用的 ID 总是一致的。
+func GenerateHeadingIDs(root *ast.Node, slugger parse.Slugger) {
+	if nil == slugger {
+		slugger = UnicodeSlugger
+	}
+
+	var generated []*ast.Node
+	occurs := map[string]bool{}
+	ast.Walk(root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering || ast.NodeHeading != n.Type {
+			return ast.WalkContinue
+		}
+
+		n.HeadingNormalizedID = ""
+		if headingID := n.ChildByType(ast.NodeHeadingID); nil != headingID {
+			if id := parse.UnicodeSlugger(parse.HeadingIDText(util.BytesToStr(headingID.Tokens))); "" != id {
+				n.HeadingNormalizedID = id
+				occurs[id] = true
+				return ast.WalkContinue
+			}
+		}
+		generated = append(generated, n)
+		return ast.WalkContinue
+	})
+
+	for _, n := range generated {
+		slug := slugger(parse.HeadingIDText(n.Text()))
+		if "" != n.HeadingNumber {
+			slug = parse.HeadingNumberID(n.HeadingNumber) + "-" + slug
+		}
+		id := slug
+		for i := 1; occurs[id]; i++ {
+			id = slug + "-" + strconv.Itoa(i)
+		}
+		occurs[id] = true
+		n.HeadingNormalizedID = id
+	}
+}
+
+// UnicodeSlugger 保留所有语言的字母和数字（区分大小写），其他字符都替换为 -，这是默认的标题 ID 生成策略。
+func UnicodeSlugger(text string) (slug string) {
+	return parse.UnicodeSlugger(text)
+}
+
+// GitHubSlugger 使用和 GitHub 一致的规则生成标题 ID：转为小写，去掉除 - 和 _ 以外的标点符号，空格替换为 -。
+func GitHubSlugger(text string) (slug string) {
+	buf := &strings.Builder{}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsNumber(r) || unicode.Is(unicode.Pc, r) || '-' == r:
+			buf.WriteRune(r)
+		case ' ' == r:
+			buf.WriteByte('-')
+		}
+	}
+	return buf.String()
+}
+
+// PinyinSlugger 将汉字转换为不带声调的拼音后按照 GitHubSlugger 生成标题 ID，拼音音节之间使用 - 分隔，比如“Go 语言入门”生成 go-yu-yan-ru-men。
+//
+// 连续的 - 会合并为一个，开头和结尾的 - 会被去掉。
+func PinyinSlugger(text string) (slug string) {
+	buf := &strings.Builder{}
+	han := false
+	for _, r := range text {
+		if syllable := pinyin(r); "" != syllable {
+			if 0 < buf.Len() {
+				buf.WriteByte(' ')
+			}
+			buf.WriteString(syllable)
+			han = true
+			continue
+		}
+		if han {
+			buf.WriteByte(' ')
+			han = false
+		}
+		buf.WriteRune(r)
+	}
+
+	slug = GitHubSlugger(buf.String())
+	for strings.Contains(slug, "--") {
+		slug = strings.ReplaceAll(slug, "--", "-")
+	}
+	return strings.Trim(slug, "-")
+}
+
+var pinyinRunes map[rune]string
+var pinyinOnce sync.Once
+
+// pinyin 返回汉字 r 不带声调的拼音，r 不是汉字或者没有收录的话返回空字符串。
+func pinyin(r rune) string {
+	if 0x4E00 > r || 0x9FFF < r {
+		return ""
+	}
+
+	pinyinOnce.Do(func() {
+		pinyinRunes = map[rune]string{}
+		for syllable, chars := range pinyinSyllables {
+			for _, c := range chars {
+				pinyinRunes[c] = syllable
+			}
+		}
+	})
+	return pinyinRunes[r]
+}
@@ -11,6 +11,7 @@
 package render
 
 import (
+	"bytes"
 	"unicode"
 	"unicode/utf8"
 
@@ -25,39 +26,45 @@ func (r *BaseRenderer) Space(textNode *ast.Node) {
 	textNode.Tokens = util.StrToBytes(text)
 }
 
-func Space0(text string) (ret string) {
+func Space0(text string) string {
 	runes := []rune(text)
 	length := len(runes)
+	buf := &bytes.Buffer{}
+	buf.Grow(len(text))
 	var r rune
 	for i := 0; i < length; {
 		r = runes[i]
 		if i < length-3 && 'i' == runes[i+1] && 'n' == runes[i+2] && 'g' == runes[i+3] && unicode.Is(unicode.Han, runes[i]) {
 			// ing 前不需要空格，如 打码ing https://github.com/88250/lute/issues/9
-			ret += string(r) + "ing"
+			buf.WriteRune(r)
+			buf.WriteString("ing")
 			i += 4
 			continue
 		}
-		ret = addSpaceAtBoundary(ret, r)
+		addSpaceAtBoundary(buf, r)
 		i++
 	}
-	return
+	return buf.String()
 }
 
-func addSpaceAtBoundary(prefix string, nextChar rune) string {
-	if 0 == len(prefix) {
-		return string(nextChar)
+// addSpaceAtBoundary 将字符 nextChar 写入 buf，需要的话在 nextChar 前加上空格。
+func addSpaceAtBoundary(buf *bytes.Buffer, nextChar rune) {
+	if 0 == buf.Len() {
+		buf.WriteRune(nextChar)
+		return
 	}
 
-	if "1" <= prefix && "9" >= prefix && 65039 == nextChar { // Emoji 1-9
+	if prefix := util.BytesToStr(buf.Bytes()); "1" <= prefix && "9" >= prefix && 65039 == nextChar { // Emoji 1-9
 		// 在这里处理并不是太合适，应该在 emoji.go 中直接将 Unicode Emoji 解析为节点
-		return prefix + string(nextChar)
+		buf.WriteRune(nextChar)
+		return
 	}
 
-	currentChar, _ := utf8.DecodeLastRuneInString(prefix)
+	currentChar, _ := utf8.DecodeLastRune(buf.Bytes())
 	if allowSpace(currentChar, nextChar) {
-		return prefix + " " + string(nextChar)
+		buf.WriteByte(' ')
 	}
-	return prefix + string(nextChar)
+	buf.WriteRune(nextChar)
 }
 
 func allowSpace(currentChar, nextChar rune) bool {
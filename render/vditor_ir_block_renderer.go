@@ -12,6 +12,7 @@ package render
 
 import (
 	"bytes"
+	"io"
 	"strconv"
 	"strings"
 	"unicode/utf8"
@@ -66,6 +67,7 @@ func NewVditorIRBlockRenderer(tree *parse.Tree) *VditorIRBlockRenderer {
 	ret.RendererFuncs[ast.NodeStrongU8eCloseMarker] = ret.renderStrongU8eCloseMarker
 	ret.RendererFuncs[ast.NodeBlockquote] = ret.renderBlockquote
 	ret.RendererFuncs[ast.NodeBlockquoteMarker] = ret.renderBlockquoteMarker
+	ret.RendererFuncs[ast.NodeAdmonition] = ret.renderAdmonition
 	ret.RendererFuncs[ast.NodeHeading] = ret.renderHeading
 	ret.RendererFuncs[ast.NodeHeadingC8hMarker] = ret.renderHeadingC8hMarker
 	ret.RendererFuncs[ast.NodeHeadingID] = ret.renderHeadingID
@@ -80,6 +82,8 @@ func NewVditorIRBlockRenderer(tree *parse.Tree) *VditorIRBlockRenderer {
 	ret.RendererFuncs[ast.NodeImage] = ret.renderImage
 	ret.RendererFuncs[ast.NodeBang] = ret.renderBang
 	ret.RendererFuncs[ast.NodeOpenBracket] = ret.renderOpenBracket
+	ret.RendererFuncs[ast.NodeOpenBrace] = ret.renderOpenBrace
+	ret.RendererFuncs[ast.NodeCloseBrace] = ret.renderCloseBrace
 	ret.RendererFuncs[ast.NodeCloseBracket] = ret.renderCloseBracket
 	ret.RendererFuncs[ast.NodeOpenParen] = ret.renderOpenParen
 	ret.RendererFuncs[ast.NodeCloseParen] = ret.renderCloseParen
@@ -122,6 +126,7 @@ func NewVditorIRBlockRenderer(tree *parse.Tree) *VditorIRBlockRenderer {
 	ret.RendererFuncs[ast.NodeMark2CloseMarker] = ret.renderMark2CloseMarker
 	ret.RendererFuncs[ast.NodeKramdownBlockIAL] = ret.renderKramdownBlockIAL
 	ret.RendererFuncs[ast.NodeBlockQueryEmbed] = ret.renderBlockQueryEmbed
+	ret.RendererFuncs[ast.NodeLinkRefDefBlock] = ret.renderLinkRefDefBlock
 	ret.RendererFuncs[ast.NodeBlockQueryEmbedScript] = ret.renderBlockQueryEmbedScript
 	ret.RendererFuncs[ast.NodeBlockEmbed] = ret.renderBlockEmbed
 	ret.RendererFuncs[ast.NodeBlockEmbedID] = ret.renderBlockEmbedID
@@ -139,7 +144,26 @@ func (r *VditorIRBlockRenderer) Render() (output []byte) {
 		return
 	}
 
-	// 将链接引用定义添加到末尾
+	r.renderLinkRefDefs()
+	output = r.Writer.Bytes()
+	return
+}
+
+// RenderTo 渲染输出到 w，每渲染完一个顶层块节点后就写入 w 一次。
+func (r *VditorIRBlockRenderer) RenderTo(w io.Writer) (err error) {
+	if err = r.BaseRenderer.RenderTo(w); nil != err {
+		return
+	}
+	if 1 > len(r.Tree.Context.LinkRefDefs) || r.needRenderFootnotesDef {
+		return
+	}
+
+	r.renderLinkRefDefs()
+	return r.Flush(w)
+}
+
+// renderLinkRefDefs 将链接引用定义添加到末尾。
+func (r *VditorIRBlockRenderer) renderLinkRefDefs() {
 	r.WriteString("<div data-block=\"0\" data-type=\"link-ref-defs-block\">")
 	for _, node := range r.Tree.Context.LinkRefDefs {
 		label := node.LinkRefLabel
@@ -152,8 +176,6 @@ func (r *VditorIRBlockRenderer) Render() (output []byte) {
 		r.WriteString(destStr + "\n")
 	}
 	r.WriteString("</div>")
-	output = r.Writer.Bytes()
-	return
 }
 
 func (r *VditorIRBlockRenderer) renderTag(node *ast.Node, entering bool) ast.WalkStatus {
@@ -256,16 +278,22 @@ func (r *VditorIRBlockRenderer) render() (output []byte) {
 
 func (r *VditorIRBlockRenderer) renderBlockQueryEmbedScript(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.Write(node.Tokens)
+		r.Write(html.EscapeHTML(node.Tokens))
 	}
 	return ast.WalkContinue
 }
 
+func (r *VditorIRBlockRenderer) renderLinkRefDefBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkSkipChildren // 链接引用定义不进行渲染
+}
+
 func (r *VditorIRBlockRenderer) renderBlockQueryEmbed(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.renderDivNode(node)
 	} else {
-		r.WriteString("<div data-render=\"2\" data-type=\"block-render\"></div>")
+		r.WriteString("<div data-render=\"2\" data-type=\"block-render\">")
+		r.Write(r.blockQueryEmbedResult(node))
+		r.WriteString("</div>")
 		r.WriteString("</div>")
 	}
 	return ast.WalkContinue
@@ -353,17 +381,17 @@ func (r *VditorIRBlockRenderer) renderBlockRefText(node *ast.Node, entering bool
 
 func (r *VditorIRBlockRenderer) renderYamlFrontMatterCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	r.tag("span", [][]string{{"data-type", "yaml-front-matter-close-marker"}}, false)
-	r.Write(parse.YamlFrontMatterMarker)
+	r.Write(parse.FrontMatterCloseMarker(node.Parent.FrontMatterType))
 	r.tag("/span", nil, false)
 	return ast.WalkStop
 }
 
 func (r *VditorIRBlockRenderer) renderYamlFrontMatterContent(node *ast.Node, entering bool) ast.WalkStatus {
-	node.Tokens = bytes.TrimSpace(node.Tokens)
+	node.Tokens = trimFrontMatterContent(node.Tokens)
 	codeLen := len(node.Tokens)
 	codeIsEmpty := 1 > codeLen || (len(util.Caret) == codeLen && util.Caret == string(node.Tokens))
 	r.tag("pre", [][]string{{"class", "vditor-ir__marker--pre"}}, false)
-	r.tag("code", [][]string{{"data-type", "yaml-front-matter"}, {"class", "language-yaml"}}, false)
+	r.tag("code", [][]string{{"data-type", "yaml-front-matter"}, {"class", "language-" + parse.FrontMatterLanguage(node.Parent.FrontMatterType)}}, false)
 	if codeIsEmpty {
 		r.WriteString(util.FrontEndCaret + "\n")
 	} else {
@@ -375,7 +403,7 @@ func (r *VditorIRBlockRenderer) renderYamlFrontMatterContent(node *ast.Node, ent
 
 func (r *VditorIRBlockRenderer) renderYamlFrontMatterOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	r.tag("span", [][]string{{"data-type", "yaml-front-matter-open-marker"}}, false)
-	r.Write(parse.YamlFrontMatterMarker)
+	r.Write(parse.FrontMatterOpenMarker(node.Parent.FrontMatterType))
 	r.tag("/span", nil, false)
 	return ast.WalkStop
 }
@@ -918,6 +946,20 @@ func (r *VditorIRBlockRenderer) renderOpenBracket(node *ast.Node, entering bool)
 	return ast.WalkStop
 }
 
+func (r *VditorIRBlockRenderer) renderCloseBrace(node *ast.Node, entering bool) ast.WalkStatus {
+	r.tag("span", [][]string{{"class", "vditor-ir__marker vditor-ir__marker--brace"}}, false)
+	r.WriteByte(lex.ItemCloseCurlyBrace)
+	r.tag("/span", nil, false)
+	return ast.WalkStop
+}
+
+func (r *VditorIRBlockRenderer) renderOpenBrace(node *ast.Node, entering bool) ast.WalkStatus {
+	r.tag("span", [][]string{{"class", "vditor-ir__marker vditor-ir__marker--brace"}}, false)
+	r.WriteByte(lex.ItemOpenCurlyBrace)
+	r.tag("/span", nil, false)
+	return ast.WalkStop
+}
+
 func (r *VditorIRBlockRenderer) renderBang(node *ast.Node, entering bool) ast.WalkStatus {
 	r.tag("span", [][]string{{"class", "vditor-ir__marker"}}, false)
 	if ast.NodeBlockEmbed == node.Parent.Type && nil != node.Parent.Previous && ast.NodeTaskListItemMarker == node.Parent.Previous.Type {
@@ -956,14 +998,7 @@ func (r *VditorIRBlockRenderer) renderImage(node *ast.Node, entering bool) ast.W
 		r.tag("img", attrs, true)
 
 		// XSS 过滤
-		buf := r.Writer.Bytes()
-		idx := bytes.LastIndex(buf, []byte("<img src="))
-		imgBuf := buf[idx:]
-		if r.Option.Sanitize {
-			imgBuf = sanitize(imgBuf)
-		}
-		r.Writer.Truncate(idx)
-		r.Writer.Write(imgBuf)
+		r.sanitizeFrom(bytes.LastIndex(r.Writer.Bytes(), []byte("<img src=")))
 
 		r.tag("/span", nil, false)
 	}
@@ -997,7 +1032,7 @@ func (r *VditorIRBlockRenderer) renderHTML(node *ast.Node, entering bool) ast.Wa
 	r.tag("pre", [][]string{{"class", "vditor-ir__preview"}, {"data-render", "2"}}, false)
 	tokens = bytes.ReplaceAll(tokens, util.CaretTokens, nil)
 	if r.Option.Sanitize {
-		tokens = sanitize(tokens)
+		tokens = r.sanitize(tokens)
 	}
 	bilibili := []byte("<iframe src=\"//player.bilibili.com/player.html")
 	if bytes.HasPrefix(tokens, bilibili) {
@@ -1067,7 +1102,9 @@ func (r *VditorIRBlockRenderer) renderInlineHTML(node *ast.Node, entering bool)
 		}
 		if !rendered {
 			if !bytes.Equal([]byte("<>"), content) {
+				offset := r.Writer.Len()
 				r.Write(content)
+				r.sanitizeFrom(offset)
 			}
 
 			r.renderSpanNode(node)
@@ -1307,6 +1344,25 @@ func (r *VditorIRBlockRenderer) renderBlockquoteMarker(node *ast.Node, entering
 	return ast.WalkStop
 }
 
+func (r *VditorIRBlockRenderer) renderAdmonition(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		attrs := [][]string{{"data-block", "0"}, {"data-node-id", r.NodeID(node)}, {"data-type", "admonition"}, {"data-admonition", node.AdmonitionType},
+			{"data-title", util.BytesToStr(html.EscapeHTML(node.AdmonitionTitle))}, {"data-marker", admonitionMarker(node)},
+			{"class", "vditor-admonition vditor-admonition--" + node.AdmonitionType}}
+		ial := r.NodeAttrs(node)
+		if 0 < len(ial) {
+			attrs = append(attrs, ial...)
+		}
+		r.tag("div", attrs, false)
+		r.tag("div", [][]string{{"class", "vditor-admonition__title"}, {"data-render", "2"}, {"contenteditable", "false"}}, false)
+		r.Write(html.EscapeHTML(admonitionTitle(node)))
+		r.WriteString("</div>")
+	} else {
+		r.WriteString("</div>")
+	}
+	return ast.WalkContinue
+}
+
 func (r *VditorIRBlockRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		text := r.Text(node)
@@ -12,6 +12,7 @@ package render
 
 import (
 	"bytes"
+	"io"
 	"strconv"
 	"strings"
 
@@ -64,6 +65,7 @@ func NewVditorIRRenderer(tree *parse.Tree) *VditorIRRenderer {
 	ret.RendererFuncs[ast.NodeStrongU8eCloseMarker] = ret.renderStrongU8eCloseMarker
 	ret.RendererFuncs[ast.NodeBlockquote] = ret.renderBlockquote
 	ret.RendererFuncs[ast.NodeBlockquoteMarker] = ret.renderBlockquoteMarker
+	ret.RendererFuncs[ast.NodeAdmonition] = ret.renderAdmonition
 	ret.RendererFuncs[ast.NodeHeading] = ret.renderHeading
 	ret.RendererFuncs[ast.NodeHeadingC8hMarker] = ret.renderHeadingC8hMarker
 	ret.RendererFuncs[ast.NodeHeadingID] = ret.renderHeadingID
@@ -78,6 +80,11 @@ func NewVditorIRRenderer(tree *parse.Tree) *VditorIRRenderer {
 	ret.RendererFuncs[ast.NodeImage] = ret.renderImage
 	ret.RendererFuncs[ast.NodeBang] = ret.renderBang
 	ret.RendererFuncs[ast.NodeOpenBracket] = ret.renderOpenBracket
+	ret.RendererFuncs[ast.NodeOpenBrace] = ret.renderOpenBrace
+	ret.RendererFuncs[ast.NodeCloseBrace] = ret.renderCloseBrace
+	ret.RendererFuncs[ast.NodeBlockQueryEmbed] = ret.renderBlockQueryEmbed
+	ret.RendererFuncs[ast.NodeLinkRefDefBlock] = ret.renderLinkRefDefBlock
+	ret.RendererFuncs[ast.NodeBlockQueryEmbedScript] = ret.renderBlockQueryEmbedScript
 	ret.RendererFuncs[ast.NodeCloseBracket] = ret.renderCloseBracket
 	ret.RendererFuncs[ast.NodeOpenParen] = ret.renderOpenParen
 	ret.RendererFuncs[ast.NodeCloseParen] = ret.renderCloseParen
@@ -120,11 +127,30 @@ func NewVditorIRRenderer(tree *parse.Tree) *VditorIRRenderer {
 
 func (r *VditorIRRenderer) Render() (output []byte) {
 	output = r.BaseRenderer.Render()
+	if 1 > len(r.Tree.Context.LinkRefDefs) || r.needRenderFootnotesDef || nil != r.err {
+		return
+	}
+
+	r.renderLinkRefDefs()
+	output = r.Writer.Bytes()
+	return
+}
+
+// RenderTo 渲染输出到 w，每渲染完一个顶层块节点后就写入 w 一次。
+func (r *VditorIRRenderer) RenderTo(w io.Writer) (err error) {
+	if err = r.BaseRenderer.RenderTo(w); nil != err {
+		return
+	}
 	if 1 > len(r.Tree.Context.LinkRefDefs) || r.needRenderFootnotesDef {
 		return
 	}
 
-	// 将链接引用定义添加到末尾
+	r.renderLinkRefDefs()
+	return r.Flush(w)
+}
+
+// renderLinkRefDefs 将链接引用定义添加到末尾。
+func (r *VditorIRRenderer) renderLinkRefDefs() {
 	r.WriteString("<div data-block=\"0\" data-type=\"link-ref-defs-block\">")
 	for _, node := range r.Tree.Context.LinkRefDefs {
 		label := node.LinkRefLabel
@@ -137,8 +163,6 @@ func (r *VditorIRRenderer) Render() (output []byte) {
 		r.WriteString(destStr + "\n")
 	}
 	r.WriteString("</div>")
-	output = r.Writer.Bytes()
-	return
 }
 
 func (r *VditorIRRenderer) renderKramdownBlockIAL(node *ast.Node, entering bool) ast.WalkStatus {
@@ -193,17 +217,17 @@ func (r *VditorIRRenderer) renderMark2CloseMarker(node *ast.Node, entering bool)
 
 func (r *VditorIRRenderer) renderYamlFrontMatterCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	r.tag("span", [][]string{{"data-type", "yaml-front-matter-close-marker"}}, false)
-	r.Write(parse.YamlFrontMatterMarker)
+	r.Write(parse.FrontMatterCloseMarker(node.Parent.FrontMatterType))
 	r.tag("/span", nil, false)
 	return ast.WalkStop
 }
 
 func (r *VditorIRRenderer) renderYamlFrontMatterContent(node *ast.Node, entering bool) ast.WalkStatus {
-	node.Tokens = bytes.TrimSpace(node.Tokens)
+	node.Tokens = trimFrontMatterContent(node.Tokens)
 	codeLen := len(node.Tokens)
 	codeIsEmpty := 1 > codeLen || (len(util.Caret) == codeLen && util.Caret == string(node.Tokens))
 	r.tag("pre", [][]string{{"class", "vditor-ir__marker--pre"}}, false)
-	r.tag("code", [][]string{{"data-type", "yaml-front-matter"}, {"class", "language-yaml"}}, false)
+	r.tag("code", [][]string{{"data-type", "yaml-front-matter"}, {"class", "language-" + parse.FrontMatterLanguage(node.Parent.FrontMatterType)}}, false)
 	if codeIsEmpty {
 		r.WriteString(util.FrontEndCaret + "\n")
 	} else {
@@ -215,7 +239,7 @@ func (r *VditorIRRenderer) renderYamlFrontMatterContent(node *ast.Node, entering
 
 func (r *VditorIRRenderer) renderYamlFrontMatterOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	r.tag("span", [][]string{{"data-type", "yaml-front-matter-open-marker"}}, false)
-	r.Write(parse.YamlFrontMatterMarker)
+	r.Write(parse.FrontMatterOpenMarker(node.Parent.FrontMatterType))
 	r.tag("/span", nil, false)
 	return ast.WalkStop
 }
@@ -546,6 +570,27 @@ func (r *VditorIRRenderer) renderMathBlockOpenMarker(node *ast.Node, entering bo
 	return ast.WalkStop
 }
 
+func (r *VditorIRRenderer) renderBlockQueryEmbedScript(node *ast.Node, entering bool) ast.WalkStatus {
+	r.Write(html.EscapeHTML(node.Tokens))
+	return ast.WalkStop
+}
+
+func (r *VditorIRRenderer) renderLinkRefDefBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkSkipChildren // 链接引用定义不进行渲染
+}
+
+func (r *VditorIRRenderer) renderBlockQueryEmbed(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.renderDivNode(node)
+	} else {
+		r.WriteString("<div data-render=\"2\" data-type=\"block-render\">")
+		r.Write(r.blockQueryEmbedResult(node))
+		r.WriteString("</div>")
+		r.WriteString("</div>")
+	}
+	return ast.WalkContinue
+}
+
 func (r *VditorIRRenderer) renderMathBlock(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.renderDivNode(node)
@@ -753,6 +798,20 @@ func (r *VditorIRRenderer) renderOpenBracket(node *ast.Node, entering bool) ast.
 	return ast.WalkStop
 }
 
+func (r *VditorIRRenderer) renderCloseBrace(node *ast.Node, entering bool) ast.WalkStatus {
+	r.tag("span", [][]string{{"class", "vditor-ir__marker vditor-ir__marker--brace"}}, false)
+	r.WriteByte(lex.ItemCloseCurlyBrace)
+	r.tag("/span", nil, false)
+	return ast.WalkStop
+}
+
+func (r *VditorIRRenderer) renderOpenBrace(node *ast.Node, entering bool) ast.WalkStatus {
+	r.tag("span", [][]string{{"class", "vditor-ir__marker vditor-ir__marker--brace"}}, false)
+	r.WriteByte(lex.ItemOpenCurlyBrace)
+	r.tag("/span", nil, false)
+	return ast.WalkStop
+}
+
 func (r *VditorIRRenderer) renderBang(node *ast.Node, entering bool) ast.WalkStatus {
 	r.tag("span", [][]string{{"class", "vditor-ir__marker"}}, false)
 	r.WriteByte(lex.ItemBang)
@@ -802,14 +861,7 @@ func (r *VditorIRRenderer) renderImage(node *ast.Node, entering bool) ast.WalkSt
 		r.tag("img", attrs, true)
 
 		// XSS 过滤
-		buf := r.Writer.Bytes()
-		idx := bytes.LastIndex(buf, []byte("<img src="))
-		imgBuf := buf[idx:]
-		if r.Option.Sanitize {
-			imgBuf = sanitize(imgBuf)
-		}
-		r.Writer.Truncate(idx)
-		r.Writer.Write(imgBuf)
+		r.sanitizeFrom(bytes.LastIndex(r.Writer.Bytes(), []byte("<img src=")))
 
 		r.tag("/span", nil, false)
 	}
@@ -835,10 +887,9 @@ func (r *VditorIRRenderer) renderHTML(node *ast.Node, entering bool) ast.WalkSta
 
 	r.tag("pre", [][]string{{"class", "vditor-ir__preview"}, {"data-render", "2"}}, false)
 	tokens = bytes.ReplaceAll(tokens, util.CaretTokens, nil)
-	if r.Option.Sanitize {
-		tokens = sanitize(tokens)
-	}
+	offset := r.Writer.Len()
 	r.Write(tokens)
+	r.sanitizeFrom(offset)
 	r.WriteString("</pre>")
 
 	r.WriteString("</div>")
@@ -1075,6 +1126,20 @@ func (r *VditorIRRenderer) renderBlockquoteMarker(node *ast.Node, entering bool)
 	return ast.WalkStop
 }
 
+func (r *VditorIRRenderer) renderAdmonition(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.tag("div", [][]string{{"data-block", "0"}, {"data-type", "admonition"}, {"data-admonition", node.AdmonitionType},
+			{"data-title", util.BytesToStr(html.EscapeHTML(node.AdmonitionTitle))}, {"data-marker", admonitionMarker(node)},
+			{"class", "vditor-admonition vditor-admonition--" + node.AdmonitionType}}, false)
+		r.tag("div", [][]string{{"class", "vditor-admonition__title"}, {"data-render", "2"}, {"contenteditable", "false"}}, false)
+		r.Write(html.EscapeHTML(admonitionTitle(node)))
+		r.WriteString("</div>")
+	} else {
+		r.WriteString("</div>")
+	}
+	return ast.WalkContinue
+}
+
 func (r *VditorIRRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		text := r.Text(node)
@@ -1318,6 +1383,8 @@ func (r *VditorIRRenderer) renderDivNode(node *ast.Node) {
 		attrs = append(attrs, []string{"data-type", "math-block"})
 	case ast.NodeYamlFrontMatter:
 		attrs = append(attrs, []string{"data-type", "yaml-front-matter"})
+	case ast.NodeBlockQueryEmbed:
+		attrs = append(attrs, []string{"data-type", "block-query-embed"})
 	}
 
 	if strings.Contains(text, util.Caret) {
@@ -1335,7 +1402,7 @@ func (r *VditorIRRenderer) Text(node *ast.Node) (ret string) {
 	ast.Walk(node, func(n *ast.Node, entering bool) ast.WalkStatus {
 		if entering {
 			switch n.Type {
-			case ast.NodeText, ast.NodeLinkText, ast.NodeLinkDest, ast.NodeLinkTitle, ast.NodeCodeBlockCode, ast.NodeCodeSpanContent, ast.NodeInlineMathContent, ast.NodeMathBlockContent, ast.NodeYamlFrontMatterContent, ast.NodeHTMLBlock, ast.NodeInlineHTML, ast.NodeEmojiAlias:
+			case ast.NodeText, ast.NodeLinkText, ast.NodeLinkDest, ast.NodeLinkTitle, ast.NodeCodeBlockCode, ast.NodeCodeSpanContent, ast.NodeInlineMathContent, ast.NodeMathBlockContent, ast.NodeYamlFrontMatterContent, ast.NodeHTMLBlock, ast.NodeInlineHTML, ast.NodeEmojiAlias, ast.NodeBlockQueryEmbedScript:
 				ret += string(n.Tokens)
 			case ast.NodeCodeBlockFenceInfoMarker:
 				ret += string(n.CodeBlockInfo)
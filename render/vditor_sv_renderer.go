@@ -12,6 +12,7 @@ package render
 
 import (
 	"bytes"
+	"io"
 	"strconv"
 	"strings"
 
@@ -28,7 +29,8 @@ type VditorSVRenderer struct {
 	*BaseRenderer
 	nodeWriterStack        []*bytes.Buffer // 节点输出缓冲栈
 	needRenderFootnotesDef bool
-	LastOut                []byte // 最新输出的 newline 长度个字节
+	LastOut                []byte      // 最新输出的 newline 长度个字节
+	stream                 *trimWriter // 流式输出，仅在 RenderTo 时使用
 }
 
 var NewlineSV = []byte("<span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span>")
@@ -103,6 +105,7 @@ func NewVditorSVRenderer(tree *parse.Tree) *VditorSVRenderer {
 	ret.RendererFuncs[ast.NodeStrongU8eCloseMarker] = ret.renderStrongU8eCloseMarker
 	ret.RendererFuncs[ast.NodeBlockquote] = ret.renderBlockquote
 	ret.RendererFuncs[ast.NodeBlockquoteMarker] = ret.renderBlockquoteMarker
+	ret.RendererFuncs[ast.NodeAdmonition] = ret.renderAdmonition
 	ret.RendererFuncs[ast.NodeHeading] = ret.renderHeading
 	ret.RendererFuncs[ast.NodeHeadingC8hMarker] = ret.renderHeadingC8hMarker
 	ret.RendererFuncs[ast.NodeHeadingID] = ret.renderHeadingID
@@ -117,6 +120,11 @@ func NewVditorSVRenderer(tree *parse.Tree) *VditorSVRenderer {
 	ret.RendererFuncs[ast.NodeImage] = ret.renderImage
 	ret.RendererFuncs[ast.NodeBang] = ret.renderBang
 	ret.RendererFuncs[ast.NodeOpenBracket] = ret.renderOpenBracket
+	ret.RendererFuncs[ast.NodeOpenBrace] = ret.renderOpenBrace
+	ret.RendererFuncs[ast.NodeCloseBrace] = ret.renderCloseBrace
+	ret.RendererFuncs[ast.NodeBlockQueryEmbed] = ret.renderBlockQueryEmbed
+	ret.RendererFuncs[ast.NodeLinkRefDefBlock] = ret.renderLinkRefDefBlock
+	ret.RendererFuncs[ast.NodeBlockQueryEmbedScript] = ret.renderBlockQueryEmbedScript
 	ret.RendererFuncs[ast.NodeCloseBracket] = ret.renderCloseBracket
 	ret.RendererFuncs[ast.NodeOpenParen] = ret.renderOpenParen
 	ret.RendererFuncs[ast.NodeCloseParen] = ret.renderCloseParen
@@ -159,11 +167,33 @@ func NewVditorSVRenderer(tree *parse.Tree) *VditorSVRenderer {
 
 func (r *VditorSVRenderer) Render() (output []byte) {
 	output = r.BaseRenderer.Render()
+	if 1 > len(r.Tree.Context.LinkRefDefs) || r.needRenderFootnotesDef || nil != r.err {
+		return
+	}
+
+	r.renderLinkRefDefs()
+	output = r.Writer.Bytes()
+	return
+}
+
+// RenderTo 渲染输出到 w，每渲染完一个顶层块节点后就写入 w 一次。
+func (r *VditorSVRenderer) RenderTo(w io.Writer) (err error) {
+	r.stream = &trimWriter{w: w}
+	defer func() { r.stream = nil }()
+	if err = r.BaseRenderer.RenderTo(r.stream); nil != err {
+		return
+	}
+	r.LastOut = bytes.TrimRight(r.LastOut, " \t\n")
 	if 1 > len(r.Tree.Context.LinkRefDefs) || r.needRenderFootnotesDef {
 		return
 	}
 
-	// 将链接引用定义添加到末尾
+	r.renderLinkRefDefs()
+	return r.Flush(w)
+}
+
+// renderLinkRefDefs 将链接引用定义添加到末尾。
+func (r *VditorSVRenderer) renderLinkRefDefs() {
 	for _, node := range r.Tree.Context.LinkRefDefs {
 		label := node.LinkRefLabel
 		dest := node.ChildByType(ast.NodeLinkDest).Tokens
@@ -187,8 +217,6 @@ func (r *VditorSVRenderer) Render() (output []byte) {
 	}
 	r.Newline()
 	r.Write(NewlineSV)
-	output = r.Writer.Bytes()
-	return
 }
 
 func (r *VditorSVRenderer) renderKramdownBlockIAL(node *ast.Node, entering bool) ast.WalkStatus {
@@ -244,7 +272,7 @@ func (r *VditorSVRenderer) renderMark2CloseMarker(node *ast.Node, entering bool)
 func (r *VditorSVRenderer) renderYamlFrontMatterCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	r.Newline()
 	r.tag("span", [][]string{{"data-type", "yaml-front-matter-close-marker"}, {"class", "vditor-sv__marker"}}, false)
-	r.Write(parse.YamlFrontMatterMarker)
+	r.Write(parse.FrontMatterCloseMarker(node.Parent.FrontMatterType))
 	r.tag("/span", nil, false)
 	r.Newline()
 	r.Write(NewlineSV)
@@ -253,7 +281,7 @@ func (r *VditorSVRenderer) renderYamlFrontMatterCloseMarker(node *ast.Node, ente
 
 func (r *VditorSVRenderer) renderYamlFrontMatterContent(node *ast.Node, entering bool) ast.WalkStatus {
 	r.tag("span", [][]string{{"data-type", "text"}}, false)
-	tokens := html.EscapeHTML(bytes.TrimSpace(node.Tokens))
+	tokens := html.EscapeHTML(trimFrontMatterContent(node.Tokens))
 	newline := append([]byte(`<span data-type="padding"></span>`), NewlineSV...)
 	tokens = bytes.ReplaceAll(tokens, []byte("\n"), newline)
 	r.Write(tokens)
@@ -263,7 +291,7 @@ func (r *VditorSVRenderer) renderYamlFrontMatterContent(node *ast.Node, entering
 
 func (r *VditorSVRenderer) renderYamlFrontMatterOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	r.tag("span", [][]string{{"data-type", "yaml-front-matter-open-marker"}, {"class", "vditor-sv__marker"}}, false)
-	r.Write(parse.YamlFrontMatterMarker)
+	r.Write(parse.FrontMatterOpenMarker(node.Parent.FrontMatterType))
 	r.tag("/span", nil, false)
 	r.Newline()
 	return ast.WalkStop
@@ -520,6 +548,28 @@ func (r *VditorSVRenderer) renderMathBlockOpenMarker(node *ast.Node, entering bo
 	return ast.WalkStop
 }
 
+func (r *VditorSVRenderer) renderBlockQueryEmbedScript(node *ast.Node, entering bool) ast.WalkStatus {
+	r.tag("span", [][]string{{"data-type", "text"}}, false)
+	tokens := html.EscapeHTML(node.Tokens)
+	newline := append([]byte(`<span data-type="padding"></span>`), NewlineSV...)
+	tokens = bytes.ReplaceAll(tokens, []byte("\n"), newline)
+	r.Write(tokens)
+	r.WriteString("</span>")
+	return ast.WalkStop
+}
+
+func (r *VditorSVRenderer) renderLinkRefDefBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkSkipChildren // 链接引用定义不进行渲染
+}
+
+func (r *VditorSVRenderer) renderBlockQueryEmbed(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.Newline()
+		r.Write(NewlineSV)
+	}
+	return ast.WalkContinue
+}
+
 func (r *VditorSVRenderer) renderMathBlock(node *ast.Node, entering bool) ast.WalkStatus {
 	return ast.WalkContinue
 }
@@ -678,6 +728,20 @@ func (r *VditorSVRenderer) renderOpenBracket(node *ast.Node, entering bool) ast.
 	return ast.WalkStop
 }
 
+func (r *VditorSVRenderer) renderCloseBrace(node *ast.Node, entering bool) ast.WalkStatus {
+	r.tag("span", [][]string{{"class", "vditor-sv__marker--brace"}}, false)
+	r.WriteByte(lex.ItemCloseCurlyBrace)
+	r.tag("/span", nil, false)
+	return ast.WalkStop
+}
+
+func (r *VditorSVRenderer) renderOpenBrace(node *ast.Node, entering bool) ast.WalkStatus {
+	r.tag("span", [][]string{{"class", "vditor-sv__marker--brace"}}, false)
+	r.WriteByte(lex.ItemOpenCurlyBrace)
+	r.tag("/span", nil, false)
+	return ast.WalkStop
+}
+
 func (r *VditorSVRenderer) renderBang(node *ast.Node, entering bool) ast.WalkStatus {
 	r.tag("span", [][]string{{"class", "vditor-sv__marker"}}, false)
 	r.WriteByte(lex.ItemBang)
@@ -729,6 +793,9 @@ func (r *VditorSVRenderer) renderDocument(node *ast.Node, entering bool) ast.Wal
 		r.nodeWriterStack = append(r.nodeWriterStack, r.Writer)
 	} else {
 		r.nodeWriterStack = r.nodeWriterStack[:len(r.nodeWriterStack)-1]
+		if nil != r.stream { // 流式输出时首尾空白由 trimWriter 去掉
+			return ast.WalkContinue
+		}
 		buf := bytes.Trim(r.Writer.Bytes(), " \t\n")
 		r.Writer.Reset()
 		r.Write(buf)
@@ -928,6 +995,36 @@ func (r *VditorSVRenderer) renderBlockquoteMarker(node *ast.Node, entering bool)
 	return ast.WalkStop
 }
 
+func (r *VditorSVRenderer) renderAdmonition(node *ast.Node, entering bool) ast.WalkStatus {
+	if 0 == node.AdmonitionFenceLen {
+		// GitHub 风格的提示块按块引用输出，第一行是类型标记 [!NOTE]
+		if entering {
+			r.renderBlockquote(node, entering)
+			r.tag("span", [][]string{{"data-type", "admonition-open-marker"}, {"class", "vditor-sv__marker"}}, false)
+			r.Write(html.EscapeHTML([]byte(admonitionOpenMarker(node))))
+			r.tag("/span", nil, false)
+			r.Newline()
+			return ast.WalkContinue
+		}
+		return r.renderBlockquote(node, entering)
+	}
+
+	if entering {
+		r.tag("span", [][]string{{"data-type", "admonition-open-marker"}, {"class", "vditor-sv__marker"}}, false)
+		r.Write(html.EscapeHTML([]byte(admonitionOpenMarker(node))))
+		r.tag("/span", nil, false)
+		r.Newline()
+	} else {
+		r.Newline()
+		r.tag("span", [][]string{{"data-type", "admonition-close-marker"}, {"class", "vditor-sv__marker"}}, false)
+		r.WriteString(admonitionMarker(node))
+		r.tag("/span", nil, false)
+		r.Newline()
+		r.Write(NewlineSV)
+	}
+	return ast.WalkContinue
+}
+
 func (r *VditorSVRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.Writer = &bytes.Buffer{}
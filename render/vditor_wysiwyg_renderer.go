@@ -12,6 +12,7 @@ package render
 
 import (
 	"bytes"
+	"io"
 	"strconv"
 	"strings"
 	"unicode"
@@ -66,6 +67,7 @@ func NewVditorRenderer(tree *parse.Tree) *VditorRenderer {
 	ret.RendererFuncs[ast.NodeStrongU8eCloseMarker] = ret.renderStrongU8eCloseMarker
 	ret.RendererFuncs[ast.NodeBlockquote] = ret.renderBlockquote
 	ret.RendererFuncs[ast.NodeBlockquoteMarker] = ret.renderBlockquoteMarker
+	ret.RendererFuncs[ast.NodeAdmonition] = ret.renderAdmonition
 	ret.RendererFuncs[ast.NodeHeading] = ret.renderHeading
 	ret.RendererFuncs[ast.NodeHeadingC8hMarker] = ret.renderHeadingC8hMarker
 	ret.RendererFuncs[ast.NodeHeadingID] = ret.renderHeadingID
@@ -80,6 +82,8 @@ func NewVditorRenderer(tree *parse.Tree) *VditorRenderer {
 	ret.RendererFuncs[ast.NodeImage] = ret.renderImage
 	ret.RendererFuncs[ast.NodeBang] = ret.renderBang
 	ret.RendererFuncs[ast.NodeOpenBracket] = ret.renderOpenBracket
+	ret.RendererFuncs[ast.NodeBlockQueryEmbed] = ret.renderBlockQueryEmbed
+	ret.RendererFuncs[ast.NodeLinkRefDefBlock] = ret.renderLinkRefDefBlock
 	ret.RendererFuncs[ast.NodeCloseBracket] = ret.renderCloseBracket
 	ret.RendererFuncs[ast.NodeOpenParen] = ret.renderOpenParen
 	ret.RendererFuncs[ast.NodeCloseParen] = ret.renderCloseParen
@@ -122,11 +126,30 @@ func NewVditorRenderer(tree *parse.Tree) *VditorRenderer {
 
 func (r *VditorRenderer) Render() (output []byte) {
 	output = r.BaseRenderer.Render()
+	if 1 > len(r.Tree.Context.LinkRefDefs) || r.needRenderFootnotesDef || nil != r.err {
+		return
+	}
+
+	r.renderLinkRefDefs()
+	output = r.Writer.Bytes()
+	return
+}
+
+// RenderTo 渲染输出到 w，每渲染完一个顶层块节点后就写入 w 一次。
+func (r *VditorRenderer) RenderTo(w io.Writer) (err error) {
+	if err = r.BaseRenderer.RenderTo(w); nil != err {
+		return
+	}
 	if 1 > len(r.Tree.Context.LinkRefDefs) || r.needRenderFootnotesDef {
 		return
 	}
 
-	// 将链接引用定义添加到末尾
+	r.renderLinkRefDefs()
+	return r.Flush(w)
+}
+
+// renderLinkRefDefs 将链接引用定义添加到末尾。
+func (r *VditorRenderer) renderLinkRefDefs() {
 	r.WriteString("<div data-block=\"0\" data-type=\"link-ref-defs-block\">")
 	for _, node := range r.Tree.Context.LinkRefDefs {
 		label := node.LinkRefLabel
@@ -139,8 +162,6 @@ func (r *VditorRenderer) Render() (output []byte) {
 		r.WriteString(destStr + "\n")
 	}
 	r.WriteString("</div>")
-	output = r.Writer.Bytes()
-	return
 }
 
 func (r *VditorRenderer) renderKramdownBlockIAL(node *ast.Node, entering bool) ast.WalkStatus {
@@ -185,7 +206,7 @@ func (r *VditorRenderer) renderYamlFrontMatterCloseMarker(node *ast.Node, enteri
 }
 
 func (r *VditorRenderer) renderYamlFrontMatterContent(node *ast.Node, entering bool) ast.WalkStatus {
-	previewTokens := bytes.TrimSpace(node.Tokens)
+	previewTokens := trimFrontMatterContent(node.Tokens)
 	codeLen := len(previewTokens)
 	codeIsEmpty := 1 > codeLen || (len(util.Caret) == codeLen && util.Caret == string(node.Tokens))
 	r.tag("pre", nil, false)
@@ -205,6 +226,11 @@ func (r *VditorRenderer) renderYamlFrontMatterOpenMarker(node *ast.Node, enterin
 
 func (r *VditorRenderer) renderYamlFrontMatter(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
+		if parse.FrontMatterYaml != node.FrontMatterType {
+			// 通过标记符区分 TOML 和 JSON Front Matter
+			r.WriteString(`<div class="vditor-wysiwyg__block" data-type="yaml-front-matter" data-block="0" data-marker="` + string(parse.FrontMatterOpenMarker(node.FrontMatterType)) + `">`)
+			return ast.WalkContinue
+		}
 		r.WriteString(`<div class="vditor-wysiwyg__block" data-type="yaml-front-matter" data-block="0">`)
 	} else {
 		r.WriteString("</div>")
@@ -427,6 +453,30 @@ func (r *VditorRenderer) renderMathBlockOpenMarker(node *ast.Node, entering bool
 	return ast.WalkStop
 }
 
+func (r *VditorRenderer) renderLinkRefDefBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkSkipChildren // 链接引用定义不进行渲染
+}
+
+func (r *VditorRenderer) renderBlockQueryEmbed(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString(`<div class="vditor-wysiwyg__block" data-type="block-query-embed" data-block="0">`)
+		script := node.ChildByType(ast.NodeBlockQueryEmbedScript).Tokens
+		if ast.NodeText == node.LastChild.Type { // 插入符
+			script = append(append([]byte{}, script...), node.LastChild.Tokens...)
+		}
+		r.tag("pre", nil, false)
+		r.tag("code", [][]string{{"data-type", "block-query-embed"}}, false)
+		r.Write(html.EscapeHTML(script))
+		r.WriteString("</code></pre>")
+		r.WriteString(`<div data-render="2" data-type="block-render">`)
+		r.Write(r.blockQueryEmbedResult(node))
+		r.WriteString("</div>")
+		return ast.WalkSkipChildren
+	}
+	r.WriteString("</div>")
+	return ast.WalkContinue
+}
+
 func (r *VditorRenderer) renderMathBlock(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.WriteString(`<div class="vditor-wysiwyg__block" data-type="math-block" data-block="0">`)
@@ -589,14 +639,7 @@ func (r *VditorRenderer) renderImage(node *ast.Node, entering bool) ast.WalkStat
 			r.WriteString(" />")
 
 			// XSS 过滤
-			buf := r.Writer.Bytes()
-			idx := bytes.LastIndex(buf, []byte("<img src="))
-			imgBuf := buf[idx:]
-			if r.Option.Sanitize {
-				imgBuf = sanitize(imgBuf)
-			}
-			r.Writer.Truncate(idx)
-			r.Writer.Write(imgBuf)
+			r.sanitizeFrom(bytes.LastIndex(r.Writer.Bytes(), []byte("<img src=")))
 
 			return ast.WalkStop
 		}
@@ -628,14 +671,7 @@ func (r *VditorRenderer) renderImage(node *ast.Node, entering bool) ast.WalkStat
 		r.WriteString(" />")
 
 		// XSS 过滤
-		buf := r.Writer.Bytes()
-		idx := bytes.LastIndex(buf, []byte("<img src="))
-		imgBuf := buf[idx:]
-		if r.Option.Sanitize {
-			imgBuf = sanitize(imgBuf)
-		}
-		r.Writer.Truncate(idx)
-		r.Writer.Write(imgBuf)
+		r.sanitizeFrom(bytes.LastIndex(r.Writer.Bytes(), []byte("<img src=")))
 	}
 	return ast.WalkContinue
 }
@@ -667,14 +703,18 @@ func (r *VditorRenderer) renderLink(node *ast.Node, entering bool) ast.WalkStatu
 			text.Tokens = append(text.Tokens, util.CaretTokens...)
 			destTokens = bytes.ReplaceAll(destTokens, util.CaretTokens, nil)
 		}
-		attrs := [][]string{{"href", string(destTokens)}}
+		attrs := [][]string{{"href", string(html.EscapeHTML(destTokens))}}
 		if title := node.ChildByType(ast.NodeLinkTitle); nil != title && nil != title.Tokens {
 			title.Tokens = bytes.ReplaceAll(title.Tokens, util.CaretTokens, nil)
-			attrs = append(attrs, []string{"title", string(title.Tokens)})
+			attrs = append(attrs, []string{"title", string(html.EscapeHTML(title.Tokens))})
 		}
+		offset := r.Writer.Len()
 		r.tag("a", attrs, false)
+		r.sanitizeFrom(offset)
 	} else {
+		offset := r.Writer.Len()
 		r.tag("/a", nil, false)
+		r.sanitizeFrom(offset)
 	}
 	return ast.WalkContinue
 }
@@ -689,10 +729,9 @@ func (r *VditorRenderer) renderHTML(node *ast.Node, entering bool) ast.WalkStatu
 
 	r.tag("pre", [][]string{{"class", "vditor-wysiwyg__preview"}, {"data-render", "2"}}, false)
 	tokens = bytes.ReplaceAll(tokens, util.CaretTokens, nil)
-	if r.Option.Sanitize {
-		tokens = sanitize(tokens)
-	}
+	offset := r.Writer.Len()
 	r.Write(tokens)
+	r.sanitizeFrom(offset)
 	r.WriteString("</pre>")
 
 	r.WriteString("</div>")
@@ -870,6 +909,20 @@ func (r *VditorRenderer) renderBlockquoteMarker(node *ast.Node, entering bool) a
 	return ast.WalkStop
 }
 
+func (r *VditorRenderer) renderAdmonition(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.tag("div", [][]string{{"data-block", "0"}, {"data-type", "admonition"}, {"data-admonition", node.AdmonitionType},
+			{"data-title", util.BytesToStr(html.EscapeHTML(node.AdmonitionTitle))}, {"data-marker", admonitionMarker(node)},
+			{"class", "vditor-admonition vditor-admonition--" + node.AdmonitionType}}, false)
+		r.tag("div", [][]string{{"class", "vditor-admonition__title"}, {"data-render", "2"}, {"contenteditable", "false"}}, false)
+		r.Write(html.EscapeHTML(admonitionTitle(node)))
+		r.WriteString("</div>")
+	} else {
+		r.WriteString("</div>")
+	}
+	return ast.WalkContinue
+}
+
 func (r *VditorRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.WriteString("<h" + headingLevel[node.HeadingLevel:node.HeadingLevel+1] + " data-block=\"0\"")
@@ -0,0 +1,85 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var admonitionTests = []parseTest{
+
+	{"8", "> [!foo]\n> bar\n", "<blockquote>\n<p>[!foo]<br />\nbar</p>\n</blockquote>\n"},
+	{"7", ":::\nfoo\n:::\n", "<p>:::<br />\nfoo<br />\n:::</p>\n"},
+	{"6", "- a\n\n  :::tip\n  b\n  :::\n", "<ul>\n<li>\n<p>a</p>\n<div class=\"admonition tip\">\n<p class=\"admonition-title\">Tip</p>\n<p>b</p>\n</div>\n</li>\n</ul>\n"},
+	{"5", ":::note\n```\n:::\n```\n:::\n", "<div class=\"admonition note\">\n<p class=\"admonition-title\">Note</p>\n<pre><code class=\"highlight-chroma\">:::\n</code></pre>\n</div>\n"},
+	{"4", ":::note\n:::warning\nfoo\n:::\nbar\n:::\n", "<div class=\"admonition note\">\n<p class=\"admonition-title\">Note</p>\n<div class=\"admonition warning\">\n<p class=\"admonition-title\">Warning</p>\n<p>foo</p>\n</div>\n<p>bar</p>\n</div>\n"},
+	{"3", "> [!WARNING]\n> foo\nbar\n\nbaz\n", "<div class=\"admonition warning\">\n<p class=\"admonition-title\">Warning</p>\n<p>foo<br />\nbar</p>\n</div>\n<p>baz</p>\n"},
+	{"2", "> [!NOTE]\n> foo\n", "<div class=\"admonition note\">\n<p class=\"admonition-title\">Note</p>\n<p>foo</p>\n</div>\n"},
+	{"1", ":::tip 小心 <\"x\">\nfoo *bar*\n", "<div class=\"admonition tip\">\n<p class=\"admonition-title\">小心 &lt;&quot;x&quot;&gt;</p>\n<p>foo <em>bar</em></p>\n</div>\n"},
+	{"0", ":::tip\nfoo\n:::\nbar\n", "<div class=\"admonition tip\">\n<p class=\"admonition-title\">Tip</p>\n<p>foo</p>\n</div>\n<p>bar</p>\n"},
+}
+
+func TestAdmonition(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetAdmonition(true)
+
+	for _, test := range admonitionTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+var admonitionFormatTests = []parseTest{
+
+	{"3", "- a\n\n  :::tip\n  b\n  :::\n", "- a\n\n  :::tip\n  b\n  :::\n"},
+	{"2", "::::note\n:::warning\nfoo\n:::\n::::\n", "::::note\n:::warning\nfoo\n:::\n::::\n"},
+	{"1", "> [!NOTE]\n> foo\nbar\n", "> [!NOTE]\n> foo\n> bar\n"},
+	{"0", ":::Tip  标题\nfoo  *bar*\n\n- a\n:::\nbaz\n", ":::tip 标题\nfoo  *bar*\n\n- a\n:::\n\nbaz\n"},
+}
+
+func TestAdmonitionFormat(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetAdmonition(true)
+
+	for _, test := range admonitionFormatTests {
+		formatted := luteEngine.FormatStr(test.name, test.from)
+		if test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, formatted, test.from)
+		}
+	}
+}
+
+func TestAdmonitionVditorDOM(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetAdmonition(true)
+
+	for _, test := range admonitionFormatTests {
+		if md := luteEngine.VditorDOM2Md(luteEngine.Md2VditorDOM(test.from)); test.to != md {
+			t.Fatalf("wysiwyg test case [%s] failed\nexpected\n\t%q\ngot\n\t%q", test.name, test.to, md)
+		}
+		if md := luteEngine.VditorIRDOM2Md(luteEngine.Md2VditorIRDOM(test.from)); test.to != md {
+			t.Fatalf("ir test case [%s] failed\nexpected\n\t%q\ngot\n\t%q", test.name, test.to, md)
+		}
+		if md := luteEngine.VditorIRBlockDOM2Md(luteEngine.Md2VditorIRBlockDOM(test.from)); test.to != md {
+			t.Fatalf("ir block test case [%s] failed\nexpected\n\t%q\ngot\n\t%q", test.name, test.to, md)
+		}
+	}
+
+	svDOM := luteEngine.Md2VditorSVDOM(":::tip\nfoo\n:::\n")
+	expected := "<span data-type=\"admonition-open-marker\" class=\"vditor-sv__marker\">:::tip</span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span><span data-type=\"text\">foo</span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span><span data-type=\"admonition-close-marker\" class=\"vditor-sv__marker\">:::</span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span>"
+	if expected != svDOM {
+		t.Fatalf("sv test failed\nexpected\n\t%q\ngot\n\t%q", expected, svDOM)
+	}
+}
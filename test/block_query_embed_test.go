@@ -0,0 +1,131 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var blockQueryEmbedTests = []parseTest{
+
+	{"7", "!{{ x\n\n!{{ y }}\n", "<p>!{{ x</p>\n<div class=\"block-query-embed\" data-script=\"y\"></div>\n"},
+	{"6", "!{{ x\n\n# foo\n\n- bar\n", "<p>!{{ x</p>\n<h1 id=\"foo\">foo</h1>\n<ul>\n<li>bar</li>\n</ul>\n"},
+	{"5", "!{{ x\n", "<p>!{{ x</p>\n"},
+	{"4", "- a\n  !{{ SELECT\n  x }}\n- b\n", "<ul>\n<li>a\n<div class=\"block-query-embed\" data-script=\"SELECT\nx\"></div>\n</li>\n<li>b</li>\n</ul>\n"},
+	{"3", "!{{ a \\} \\\\ b }}\n", "<div class=\"block-query-embed\" data-script=\"a } \\ b\"></div>\n"},
+	{"2", "!{{\nSELECT * FROM blocks\n\nWHERE content = '\\}}'\n}}\nfoo\n", "<div class=\"block-query-embed\" data-script=\"SELECT * FROM blocks\n\nWHERE content = &#39;}}&#39;\"></div>\n<p>foo</p>\n"},
+	{"1", "！{{SELECT * FROM blocks}}\nfoo\n", "<div class=\"block-query-embed\" data-script=\"SELECT * FROM blocks\"></div>\n<p>foo</p>\n"},
+	{"0", "!{{SELECT * FROM blocks WHERE content LIKE '%<x>%'}}\n", "<div class=\"block-query-embed\" data-script=\"SELECT * FROM blocks WHERE content LIKE &#39;%&lt;x&gt;%&#39;\"></div>\n"},
+}
+
+func TestBlockQueryEmbed(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetBlockRef(true)
+
+	for _, test := range blockQueryEmbedTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+var blockQueryEmbedFormatTests = []parseTest{
+
+	{"3", "!{{SELECT‸}}\n", "!{{SELECT‸}}\n"},
+	{"2", "- a\n  !{{ SELECT\n  x }}\n- b\n", "- a\n  !{{ SELECT\n  x }}\n- b\n"},
+	{"1", "！{{\nSELECT * FROM blocks\n\nWHERE content = '\\}}'\n}}\nfoo\n", "!{{\nSELECT * FROM blocks\n\nWHERE content = '\\}}'\n}}\n\nfoo\n"},
+	{"0", "!{{ SELECT * FROM blocks }}\n", "!{{ SELECT * FROM blocks }}\n"},
+}
+
+func TestBlockQueryEmbedFormat(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetBlockRef(true)
+
+	for _, test := range blockQueryEmbedFormatTests {
+		formatted := luteEngine.FormatStr(test.name, test.from)
+		if test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, formatted, test.from)
+		}
+	}
+}
+
+func TestBlockQueryEmbedVditorDOM(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetBlockRef(true)
+
+	for _, test := range blockQueryEmbedFormatTests {
+		if md := luteEngine.VditorDOM2Md(luteEngine.Md2VditorDOM(test.from)); test.to != md {
+			t.Fatalf("wysiwyg test case [%s] failed\nexpected\n\t%q\ngot\n\t%q", test.name, test.to, md)
+		}
+		if md := luteEngine.VditorIRDOM2Md(luteEngine.Md2VditorIRDOM(test.from)); test.to != md {
+			t.Fatalf("ir test case [%s] failed\nexpected\n\t%q\ngot\n\t%q", test.name, test.to, md)
+		}
+		if md := luteEngine.VditorIRBlockDOM2Md(luteEngine.Md2VditorIRBlockDOM(test.from)); test.to != md {
+			t.Fatalf("ir block test case [%s] failed\nexpected\n\t%q\ngot\n\t%q", test.name, test.to, md)
+		}
+	}
+
+	svDOM := luteEngine.Md2VditorSVDOM("!{{a\nb}}\n")
+	expected := "<span class=\"vditor-sv__marker\">!</span><span class=\"vditor-sv__marker--brace\">{</span><span class=\"vditor-sv__marker--brace\">{</span><span data-type=\"text\">a<span data-type=\"padding\"></span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span>b</span><span class=\"vditor-sv__marker--brace\">}</span><span class=\"vditor-sv__marker--brace\">}</span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span>"
+	if expected != svDOM {
+		t.Fatalf("sv test failed\nexpected\n\t%q\ngot\n\t%q", expected, svDOM)
+	}
+}
+
+func TestBlockQueryEmbedResolver(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetBlockRef(true)
+	luteEngine.SetBlockQueryEmbedResolver(func(script string) (string, error) {
+		if "err" == script {
+			return "", errors.New("invalid <query>")
+		}
+		return "* " + script + "\n\n!{{ nested }}\n", nil
+	})
+
+	html := luteEngine.MarkdownStr("", "!{{ a \\} b }}\n")
+	expected := "<div class=\"block-query-embed\" data-script=\"a } b\">\n<ul>\n<li>a } b</li>\n</ul>\n<div class=\"block-query-embed\" data-script=\"nested\"></div>\n</div>\n"
+	if expected != html {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, html)
+	}
+
+	html = luteEngine.MarkdownStr("", "!{{err}}\n")
+	expected = "<div class=\"block-query-embed\" data-script=\"err\">\n<p class=\"block-query-embed__error\">invalid &lt;query&gt;</p>\n</div>\n"
+	if expected != html {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, html)
+	}
+}
+
+func TestBlockQueryEmbedLint(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetBlockRef(true)
+
+	lintTests := []parseTest{
+		{"4", "!{{ SELECT\n\nfoo\n", "1:1: error: block query embed is not closed with }} [unclosed-block-query-embed]"},
+		{"3", "!{{ SELECT * FROM blocks WHERE content = 'x }}\n", "1:1: warning: block query embed script has an unclosed quote ' [invalid-block-query-embed]"},
+		{"2", "!{{ SELECT (1 }}\n", "1:1: warning: block query embed script has unbalanced parentheses [invalid-block-query-embed]"},
+		{"1", "!{{ }}\n", "1:1: warning: block query embed script is empty [invalid-block-query-embed]"},
+		{"0", "foo\n\n!{{ SELECT\n", "3:1: error: block query embed is not closed with }} [unclosed-block-query-embed]"},
+	}
+	for _, test := range lintTests {
+		diagnostics := luteEngine.Lint(test.name, []byte(test.from))
+		if 1 != len(diagnostics) || test.to != diagnostics[0].String() {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%v", test.name, test.to, diagnostics)
+		}
+	}
+
+	if diagnostics := luteEngine.Lint("", []byte("!{{ SELECT * FROM blocks WHERE content = 'it''s (' }}\n")); 0 < len(diagnostics) {
+		t.Fatalf("unexpected diagnostics %v", diagnostics)
+	}
+}
@@ -0,0 +1,169 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+)
+
+const (
+	nodeAdmonition ast.NodeType = 600 + iota
+	nodeTomlFrontMatter
+)
+
+var blockSyntaxTests = []parseTest{
+
+	{"6", "> :::tip\n> foo\n\nbar\n", "<blockquote>\n<div class=\"tip\">\n<p>foo</p>\n</div>\n</blockquote>\n<p>bar</p>\n"},
+	{"5", "foo\n+++\nbar\n", "<p>foo<br />\n+++<br />\nbar</p>\n"},
+	{"4", "+++\ntitle = \"foo\"\n+++\n\n# bar\n", "<pre class=\"toml\">title = &quot;foo&quot;\n</pre>\n<h1 id=\"bar\">bar</h1>\n"},
+	{"3", ":::warn\n- foo\n- bar\n:::\n", "<div class=\"warn\">\n<ul>\n<li>foo</li>\n<li>bar</li>\n</ul>\n</div>\n"},
+	{"2", ":::note\n:::tip\nfoo\n:::\n:::\n", "<div class=\"note\">\n<div class=\"tip\">\n<p>foo</p>\n</div>\n</div>\n"},
+	{"1", ":::note\nfoo *bar*\n", "<div class=\"note\">\n<p>foo <em>bar</em></p>\n</div>\n"},
+	{"0", ":::note\nfoo\n\nbar\n:::\nbaz\n", "<div class=\"note\">\n<p>foo</p>\n<p>bar</p>\n</div>\n<p>baz</p>\n"},
+}
+
+// admonition 实现了 :::note 提示块，是一个可以包含任意块的容器块。
+type admonition struct{}
+
+func (admonition) NodeType() ast.NodeType                { return nodeAdmonition }
+func (admonition) AcceptLines() bool                     { return false }
+func (admonition) CanContain(nodeType ast.NodeType) bool { return ast.NodeListItem != nodeType }
+func (admonition) Finalize(n *ast.Node)                  {}
+
+func (admonition) TryStart(container *ast.Node, line []byte) (ret *ast.Node, consumed int) {
+	if !bytes.HasPrefix(line, []byte(":::")) {
+		return nil, 0
+	}
+	kind := bytes.TrimSpace(line[3:])
+	if 1 > len(kind) {
+		return nil, 0
+	}
+	return &ast.Node{Tokens: kind}, len(line)
+}
+
+func (admonition) Continue(n *ast.Node, line []byte) (ret, consumed int) {
+	if last := n.LastChild; nil != last && !last.Close && nodeAdmonition == last.Type {
+		return 0, 0 // 闭合标记符留给嵌套的提示块
+	}
+	if bytes.Equal(bytes.TrimSpace(line), []byte(":::")) {
+		return 2, 0
+	}
+	return 0, 0
+}
+
+// tomlFrontMatter 实现了 +++ TOML Front Matter，是一个仅能出现在文档开头的叶子块。
+type tomlFrontMatter struct{}
+
+func (tomlFrontMatter) NodeType() ast.NodeType                { return nodeTomlFrontMatter }
+func (tomlFrontMatter) AcceptLines() bool                     { return true }
+func (tomlFrontMatter) CanContain(nodeType ast.NodeType) bool { return false }
+
+func (tomlFrontMatter) TryStart(container *ast.Node, line []byte) (ret *ast.Node, consumed int) {
+	if ast.NodeDocument != container.Type || nil != container.FirstChild || !bytes.Equal(line, []byte("+++\n")) {
+		return nil, 0
+	}
+	return &ast.Node{}, len(line)
+}
+
+func (tomlFrontMatter) Continue(n *ast.Node, line []byte) (ret, consumed int) {
+	if bytes.Equal(line, []byte("+++\n")) {
+		return 2, 0
+	}
+	return 0, 0
+}
+
+func (tomlFrontMatter) Finalize(n *ast.Node) {
+	n.Tokens = bytes.TrimLeft(n.Tokens, "\n")
+}
+
+func newBlockSyntaxLute() *lute.Lute {
+	luteEngine := lute.New()
+	luteEngine.RegisterBlockSyntax(admonition{})
+	luteEngine.RegisterBlockSyntax(tomlFrontMatter{})
+
+	luteEngine.Md2HTMLRendererFuncs[nodeAdmonition] = func(n *ast.Node, entering bool) (string, ast.WalkStatus) {
+		if entering {
+			return "<div class=\"" + string(n.Tokens) + "\">\n", ast.WalkContinue
+		}
+		return "</div>\n", ast.WalkContinue
+	}
+	luteEngine.Md2HTMLRendererFuncs[nodeTomlFrontMatter] = func(n *ast.Node, entering bool) (string, ast.WalkStatus) {
+		if !entering {
+			return "", ast.WalkContinue
+		}
+		toml := bytes.ReplaceAll(n.Tokens, []byte("\""), []byte("&quot;"))
+		return "<pre class=\"toml\">" + string(toml) + "</pre>\n", ast.WalkContinue
+	}
+	return luteEngine
+}
+
+func TestBlockSyntax(t *testing.T) {
+	luteEngine := newBlockSyntaxLute()
+	for _, test := range blockSyntaxTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+func TestBlockSyntaxNode(t *testing.T) {
+	luteEngine := newBlockSyntaxLute()
+	tree := parse.Parse("", []byte(":::note\nfoo\n:::\n"), luteEngine.Options)
+	note := tree.Root.FirstChild
+	if nodeAdmonition != note.Type || !note.IsBlock() || note.AcceptLines() || !note.CanContain(ast.NodeParagraph) {
+		t.Fatalf("unexpected admonition node %s", note.Type)
+	}
+	if 1 != note.StartPos.Line || 3 != note.EndPos.Line || ast.NodeParagraph != note.FirstChild.Type || !note.FirstChild.Close {
+		t.Fatalf("unexpected admonition node range %d-%d", note.StartPos.Line, note.EndPos.Line)
+	}
+}
+
+func TestBlockSyntaxFormat(t *testing.T) {
+	luteEngine := newBlockSyntaxLute()
+	from := "+++\na = 1\n+++\n:::note\nfoo  *bar*\n:::\n- x\n  :::tip\n  baz\n  :::\n"
+	expected := "+++\na = 1\n+++\n\n:::note\nfoo  *bar*\n:::\n\n- x\n  :::tip\n  baz\n  :::\n"
+	if formatted := luteEngine.FormatStr("", from); expected != formatted {
+		t.Fatalf("format failed\nexpected\n\t%q\ngot\n\t%q", expected, formatted)
+	}
+}
+
+func TestBlockSyntaxPerEngine(t *testing.T) {
+	luteEngine := lute.New()
+	forked := luteEngine.With()
+	forked.RegisterBlockSyntax(admonition{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			tree := parse.Parse("", []byte(":::note\nfoo\n:::\n"), forked.Options)
+			if nodeAdmonition != tree.Root.FirstChild.Type || !tree.Root.FirstChild.IsBlock() {
+				t.Errorf("forked engine should parse admonition, got %s", tree.Root.FirstChild.Type)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			tree := parse.Parse("", []byte(":::note\nfoo\n:::\n"), luteEngine.Options)
+			if nodeAdmonition == tree.Root.FirstChild.Type || (&ast.Node{Type: nodeAdmonition}).IsBlock() || 0 != len(luteEngine.BlockSyntaxes) {
+				t.Errorf("block syntax registered on forked engine leaked, got %s", tree.Root.FirstChild.Type)
+			}
+		}()
+	}
+	wg.Wait()
+}
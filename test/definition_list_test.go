@@ -0,0 +1,96 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var definitionListTests = []parseTest{
+
+	{"8", "[a]: /u\nTerm\n: [def][a]\n", "<dl>\n<dt>Term</dt>\n<dd><a href=\"/u\">def</a></dd>\n</dl>\n"},
+	{"7", "- a\n  : b\n", "<ul>\n<li>\n<dl>\n<dt>a</dt>\n<dd>b</dd>\n</dl>\n</li>\n</ul>\n"},
+	{"6", "foo\n\n: bar\n", "<p>foo</p>\n<p>: bar</p>\n"},
+	{"5", "Term\n:foo\n", "<p>Term<br />\n:foo</p>\n"},
+	{"4", "Term\n: foo\n\nbar\n", "<dl>\n<dt>Term</dt>\n<dd>foo</dd>\n</dl>\n<p>bar</p>\n"},
+	{"3", "Apple\n:   fruit\n\n    more\n\nOrange\n: citrus\n", "<dl>\n<dt>Apple</dt>\n<dd>\n<p>fruit</p>\n<p>more</p>\n</dd>\n<dt>Orange</dt>\n<dd>\n<p>citrus</p>\n</dd>\n</dl>\n"},
+	{"2", "Apple\n: red\n\nOrange\n: orange\n", "<dl>\n<dt>Apple</dt>\n<dd>red</dd>\n<dt>Orange</dt>\n<dd>orange</dd>\n</dl>\n"},
+	{"1", "Apple\nPear\n: fruit a\n: fruit b\nlazy\n", "<dl>\n<dt>Apple</dt>\n<dt>Pear</dt>\n<dd>fruit a</dd>\n<dd>fruit b<br />\nlazy</dd>\n</dl>\n"},
+	{"0", "*Apple*\n: Pomaceous fruit\n", "<dl>\n<dt><em>Apple</em></dt>\n<dd>Pomaceous fruit</dd>\n</dl>\n"},
+}
+
+func TestDefinitionList(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetDefinitionList(true)
+
+	for _, test := range definitionListTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+func TestDefinitionListDisabled(t *testing.T) {
+	luteEngine := lute.New()
+
+	html := luteEngine.MarkdownStr("", "Term\n: foo\n")
+	if expected := "<p>Term<br />\n: foo</p>\n"; expected != html {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, html)
+	}
+}
+
+var definitionListFormatTests = []parseTest{
+
+	{"3", "T\n:     code\n", "T\n: ```\n  code\n  ```\n"},
+	{"2", "Apple\n:   fruit\n\n    more\n\nOrange\n: citrus\n", "Apple\n: fruit\n\n  more\n\nOrange\n: citrus\n"},
+	{"1", "Apple\nPear\n:   fruit a\n: fruit b\nlazy\n", "Apple\nPear\n: fruit a\n: fruit b\n  lazy\n"},
+	{"0", "Apple\n: red\n\nOrange\n: orange\n- a\n", "Apple\n: red\n\nOrange\n: orange\n\n- a\n"},
+}
+
+func TestDefinitionListFormat(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetDefinitionList(true)
+
+	for _, test := range definitionListFormatTests {
+		formatted := luteEngine.FormatStr(test.name, test.from)
+		if test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, formatted, test.from)
+		}
+	}
+}
+
+var definitionListHTML2MdTests = []parseTest{
+
+	{"2", "<dl><dt>A</dt><dd><ul><li>x</li><li>y</li></ul></dd></dl>", "A\n: * x\n  * y\n"},
+	{"1", "<dl>\n  <dt>Apple</dt>\n  <dd>\n    <p>fruit</p>\n    <p>more</p>\n  </dd>\n  <dt>B</dt>\n  <dd><p>b</p></dd>\n</dl>\n", "Apple\n: fruit\n\n  more\n\nB\n: b\n"},
+	{"0", "<dl><dt>Apple</dt><dd>Pomaceous <em>fruit</em></dd><dt>Orange</dt><dt>Citrus</dt><dd>a</dd><dd>b</dd></dl><p>x</p>", "Apple\n: Pomaceous *fruit*\n\nOrange\nCitrus\n: a\n: b\n\nx\n"},
+}
+
+func TestDefinitionListHTML2Md(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetDefinitionList(true)
+
+	for _, test := range definitionListHTML2MdTests {
+		md := luteEngine.HTML2Md(test.from)
+		if test.to != md {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal html\n\t%q", test.name, test.to, md, test.from)
+		}
+
+		// 转换后的 Markdown 需要能够解析回定义列表
+		if html := luteEngine.MarkdownStr(test.name, md); !strings.HasPrefix(html, "<dl>") {
+			t.Fatalf("test case [%s] round trip failed\n\t%q", test.name, html)
+		}
+	}
+}
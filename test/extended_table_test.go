@@ -0,0 +1,96 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var extendedTableTests = []parseTest{
+
+	{"5", "| a | b |\n| - | - |\n| c | d |\n[]\n", "<table>\n<thead>\n<tr>\n<th>a</th>\n<th>b</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>c</td>\n<td>d</td>\n</tr>\n<tr>\n<td>[]</td>\n<td></td>\n</tr>\n</tbody>\n</table>\n"},
+	{"4", "| a | b |\n| - | - |\n| c | d |\n[Table <1>]\n", "<table>\n<caption>Table &lt;1&gt;</caption>\n<thead>\n<tr>\n<th>a</th>\n<th>b</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>c</td>\n<td>d</td>\n</tr>\n</tbody>\n</table>\n"},
+	{"3", "| h1 | h2 |\n| -- | -- |\n| - a |\\\n| - b | q |\n", "<table>\n<thead>\n<tr>\n<th>h1</th>\n<th>h2</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>\n<ul>\n<li>a</li>\n<li>b</li>\n</ul>\n</td>\n<td>q</td>\n</tr>\n</tbody>\n</table>\n"},
+	{"2", "| a | b |\n| - | - |\n| foo | x |\\\n| bar | |\n", "<table>\n<thead>\n<tr>\n<th>a</th>\n<th>b</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>foo<br />\nbar</td>\n<td>x</td>\n</tr>\n</tbody>\n</table>\n"},
+	{"1", "| a | b |\n| - | - |\n| x | y |\n| ^^ | z |\n| ^^ | w |\n", "<table>\n<thead>\n<tr>\n<th>a</th>\n<th>b</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td rowspan=\"3\">x</td>\n<td>y</td>\n</tr>\n<tr>\n<td>z</td>\n</tr>\n<tr>\n<td>w</td>\n</tr>\n</tbody>\n</table>\n"},
+	{"0", "| a || c |\n| - | - | - |\n| x | y ||\n", "<table>\n<thead>\n<tr>\n<th colspan=\"2\">a</th>\n<th>c</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>x</td>\n<td colspan=\"2\">y</td>\n</tr>\n</tbody>\n</table>\n"},
+}
+
+func TestExtendedTable(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetExtendedTable(true)
+
+	for _, test := range extendedTableTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+func TestExtendedTableDisabled(t *testing.T) {
+	luteEngine := lute.New()
+
+	html := luteEngine.MarkdownStr("", "| a || c |\n| - | - | - |\n| ^^ | y |\n[Cap]\n")
+	if expected := "<table>\n<thead>\n<tr>\n<th>a</th>\n<th></th>\n<th>c</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>^^</td>\n<td>y</td>\n<td></td>\n</tr>\n<tr>\n<td>[Cap]</td>\n<td></td>\n<td></td>\n</tr>\n</tbody>\n</table>\n"; expected != html {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, html)
+	}
+}
+
+var extendedTableFormatTests = []parseTest{
+
+	{"4", "| a long header ||\n|---|---|\n| 1 | 2 |\n", "| a long header ||\n| - | ---------- |\n| 1 | 2          |\n"},
+	{"3", "| a ||\n|---|---|\n| 1 | 2 |\n", "| a    ||\n| - | - |\n| 1 | 2 |\n"},
+	{"2", "| a | b |\n| - | - |\n| foo | x |\\\n| bar | |\n[Cap]\n", "| a   | b |\n| --- | - |\n| foo | x |\\\n| bar |  |\n[Cap]\n"},
+	{"1", "|a|b|\n|-|-|\n|x|y|\n|^^|z|\n", "| a  | b |\n| -- | - |\n| x  | y |\n| ^^ | z |\n"},
+	{"0", "|a||c|\n|-|:-:|-:|\n|x|y||\n", "| a   || c |\n| - | :-: | -: |\n| x |  y  ||\n"},
+}
+
+func TestExtendedTableFormat(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetExtendedTable(true)
+
+	for _, test := range extendedTableFormatTests {
+		formatted := luteEngine.FormatStr(test.name, test.from)
+		if test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, formatted, test.from)
+		}
+		if again := luteEngine.FormatStr(test.name, formatted); formatted != again {
+			t.Fatalf("test case [%s] is not idempotent\n\t%q\n\t%q", test.name, formatted, again)
+		}
+	}
+}
+
+var extendedTableHTML2MdTests = []parseTest{
+
+	{"3", "<table><thead><tr><th>a</th><th>b</th></tr></thead><tbody><tr><td>foo</td><td rowspan=\"2\">barbaz</td></tr><tr><td>xxxxxxx</td></tr></tbody></table>", "| a       | b      |\n| ------- | ------ |\n| foo     | barbaz |\n| xxxxxxx | ^^     |\n"},
+	{"2", "<table><tr><th rowspan=\"2\">a</th><th>b</th></tr><tr><td>bar</td></tr></table>", "| a  | b   |\n| -- | --- |\n| ^^ | bar |\n"},
+	{"1", "<table><tr><td>a</td><td>b</td></tr><tr><td><p>p1</p><p>p2</p></td><td>d</td></tr></table>", "| a  | b |\n| -- | - |\n| p1 | d |\\\n|  |  |\\\n| p2 |  |\n"},
+	{"0", "<table><caption> Cap </caption><thead><tr><th colspan=\"2\">a</th><th>c</th></tr></thead><tbody><tr><td rowspan=\"2\">x</td><td>y</td><td rowspan=\"3\">z</td></tr><tr><td>w</td></tr><tr><td>p</td><td>q</td></tr></tbody></table>", "| a     || c  |\n| -- | - | -- |\n| x  | y | z  |\n| ^^ | w | ^^ |\n| p  | q | ^^ |\n[Cap]\n"},
+}
+
+func TestExtendedTableHTML2Md(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetExtendedTable(true)
+
+	for _, test := range extendedTableHTML2MdTests {
+		md := luteEngine.HTML2Md(test.from)
+		if test.to != md {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal html\n\t%q", test.name, test.to, md, test.from)
+		}
+		// 转换结果和格式化结果一致，包括 ^^ 占位单元格的对齐填充
+		if formatted := luteEngine.FormatStr("", md); md != formatted {
+			t.Fatalf("test case [%s] failed\nformatted\n\t%q\ngot\n\t%q", test.name, formatted, md)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+type formatCheckTest struct {
+	name         string
+	original     string // 原始的 Markdown 文本
+	diff         string // 统一差异格式文本
+	changedLines string // 原文中需要修改的行范围
+}
+
+var formatCheckTests = []formatCheckTest{
+
+	{"3", "*  a\n\n1\n\n2\n\n3\n\n4\n\n5\n\n6\n\n7\n\n8\n\n#  b\n", "--- a/3.md\n+++ b/3.md\n@@ -1,4 +1,4 @@\n-*  a\n+* a\n \n 1\n \n@@ -16,4 +16,4 @@\n \n 8\n \n-#  b\n+# b\n", "[1-1 19-19]"},
+	{"2", "a\n\n\n\nb", "--- a/2.md\n+++ b/2.md\n@@ -1,5 +1,3 @@\n a\n \n-\n-\n-b\n\\ No newline at end of file\n+b\n", "[3-5]"},
+	{"1", "*  a\n*  b\n\nfoo\n", "--- a/1.md\n+++ b/1.md\n@@ -1,4 +1,4 @@\n-*  a\n-*  b\n+* a\n+* b\n \n foo\n", "[1-2]"},
+	{"0", "# a\n\nfoo\n", "", "[]"},
+}
+
+func TestFormatCheck(t *testing.T) {
+	luteEngine := lute.New()
+
+	for _, test := range formatCheckTests {
+		result := luteEngine.FormatCheck(test.name+".md", []byte(test.original))
+		if result.Formatted != ("" == test.diff) {
+			t.Fatalf("test case [%s] failed, formatted [%v]", test.name, result.Formatted)
+		}
+		if test.diff != result.Diff {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.diff, result.Diff, test.original)
+		}
+		var ranges []string
+		for _, r := range result.ChangedLines {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", r.Start, r.End))
+		}
+		if changedLines := fmt.Sprint(ranges); test.changedLines != changedLines {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%s\ngot\n\t%s", test.name, test.changedLines, changedLines)
+		}
+	}
+}
+
+func TestFormatCheckLargeDiff(t *testing.T) {
+	luteEngine := lute.New()
+
+	// 每一行都需要修改的长文本超出最大编辑距离后整体替换，不能占用过多的内存
+	original := strings.Repeat("foo\r\n\r\n", 8000)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	result := luteEngine.FormatCheck("large.md", []byte(original))
+	runtime.ReadMemStats(&after)
+	if result.Formatted || 1 != len(result.ChangedLines) || 1 != result.ChangedLines[0].Start || 16000 != result.ChangedLines[0].End {
+		t.Fatalf("large diff failed, got changed lines %v", result.ChangedLines)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; 256<<20 < allocated {
+		t.Fatalf("large diff allocated too much memory [%d]", allocated)
+	}
+
+	// 编辑距离较小时仍然使用最短编辑脚本
+	original = strings.Repeat("foo\n\n", 8000) + "#  bar\n"
+	result = luteEngine.FormatCheck("large.md", []byte(original))
+	if 1 != len(result.ChangedLines) || 16001 != result.ChangedLines[0].Start || 16001 != result.ChangedLines[0].End {
+		t.Fatalf("large diff failed, got changed lines %v", result.ChangedLines)
+	}
+}
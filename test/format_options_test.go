@@ -0,0 +1,106 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/render"
+)
+
+var prettierFormatTests = []formatTest{
+
+	{"13", "*foo __bar *baz bim__ bam*\n", "*foo __bar *baz bim__ bam*\n"},
+	{"12", "*foo _bar* baz_\n", "*foo _bar* baz_\n"},
+	{"11", "Foo *bar\nbaz*\n===\n", "Foo _bar\nbaz_\n===\n"},
+	{"10", "*a*_b_ *__c__*\n", "*a*_b_ *__c__*\n"},
+	{"9", "foo __*__\n", "foo __*__\n"},
+	{"8", "foo *_*\n", "foo *_*\n"},
+	{"7", "*_foo_*\n", "*_foo_*\n"},
+	{"6", "*foo* _*bar*_ **_baz_**\n", "_foo_ _*bar*_ **_baz_**\n"},
+
+	{"5", "```go\ncode\n```\n\n    indented\n", "```go\ncode\n```\n\n```\nindented\n```\n"},
+	{"4", "~~~\n```\n~~~\n", "````\n```\n````\n"},
+	{"3", "1. a\n1. b\n", "1. a\n2. b\n"},
+	{"2", "*em* foo*bar*baz __strong__ a__b__c\n", "_em_ foo*bar*baz **strong** a__b__c\n"},
+	{"1", "Title\n=====\n\nSub\n---\n", "# Title\n\n## Sub\n"},
+	{"0", "* a\n* b\n\n+ c\n\n- d\n", "- a\n- b\n\n* c\n\n- d\n"},
+}
+
+func TestPrettierFormatOptions(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetFormatOptions(render.NewPrettierFormatOptions())
+
+	for _, test := range prettierFormatTests {
+		formatted := luteEngine.FormatStr(test.name, test.original)
+		if test.formatted != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.formatted, formatted, test.original)
+		}
+		if expected, html := luteEngine.MarkdownStr(test.name, test.original), luteEngine.MarkdownStr(test.name, formatted); expected != html {
+			t.Fatalf("test case [%s] changed html\nexpected\n\t%q\ngot\n\t%q", test.name, expected, html)
+		}
+	}
+}
+
+var houseStyleFormatTests = []formatTest{
+
+	{"9", "> - # Foo\n", "> + # Foo\n"},
+	{"8", "> # Foo\n>\n> ## Bar\n", "> # Foo\n>\n> ## Bar\n"},
+	{"7", "## \n\nfoo\n", "## \n\nfoo\n"},
+	{"6", "_a_ _*b*_ __**c**__\n", "*a* _*b*_ __**c**__\n"},
+
+	{"5", "| a | bbbbbb |\n|:-:|--:|\n| c | d |\n", "| a | bbbbbb |\n| :-: | -: |\n| c | d |\n"},
+	{"4", "this is a long paragraph - that wraps 1. at twenty\n", "this is a long\nparagraph - that\nwraps 1. at twenty\n"},
+	{"3", "> quoted long paragraph that wraps\n", "> quoted long\n> paragraph that wraps\n"},
+	{"2", "3. a\n4. b\n", "3. a\n3. b\n"},
+	{"1", "```\n~~~~\n```\n", "~~~~~\n~~~~\n~~~~~\n"},
+	{"0", "# H1\n\n## H2\n\n### H3\n\n- a\n\n_x_ **y**\n", "H1\n==\n\nH2\n--\n\n### H3\n\n+ a\n\n*x* __y__\n"},
+}
+
+func TestHouseStyleFormatOptions(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetSoftBreak2HardBreak(false)
+	luteEngine.Setext = true
+	luteEngine.SetFormatOptions(&render.FormatOptions{
+		BulletChar:           '+',
+		EmphasisMarker:       '*',
+		StrongMarker:         '_',
+		HeadingStyle:         2,
+		OrderedListNumbering: 1,
+		FenceChar:            '~',
+		FenceLen:             3,
+		HardWrapColumn:       20,
+	})
+
+	for _, test := range houseStyleFormatTests {
+		formatted := luteEngine.FormatStr(test.name, test.original)
+		if test.formatted != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.formatted, formatted, test.original)
+		}
+		if again := luteEngine.FormatStr(test.name, formatted); formatted != again {
+			t.Fatalf("test case [%s] is not idempotent\n\t%q\n\t%q", test.name, formatted, again)
+		}
+		// 折行只会把空格变为换行，比较 HTML 时忽略空白差异
+		expected, html := luteEngine.MarkdownStr(test.name, test.original), luteEngine.MarkdownStr(test.name, formatted)
+		if strings.Join(strings.Fields(expected), " ") != strings.Join(strings.Fields(html), " ") {
+			t.Fatalf("test case [%s] changed html\nexpected\n\t%q\ngot\n\t%q", test.name, expected, html)
+		}
+	}
+
+	// 没有开启 Setext 标题解析时不转换为 Setext 标题
+	luteEngine = lute.New()
+	luteEngine.SetFormatOptions(&render.FormatOptions{HeadingStyle: 2, TableAlignPadding: true})
+	if formatted := luteEngine.FormatStr("", "# Foo\n"); "# Foo\n" != formatted {
+		t.Fatalf("setext disabled failed, got\n\t%q", formatted)
+	}
+}
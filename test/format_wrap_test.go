@@ -0,0 +1,92 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/render"
+)
+
+var reflowFormatTests = []formatTest{
+
+	{"9", "aaaa <http://foo.bar/baz bim>\n", "aaaa <http://foo.bar/baz bim>\n"},
+	{"8", "aaaa bbbb cccc dddd [foo bar]: /url\n", "aaaa bbbb cccc dddd [foo\nbar]: /url\n"},
+	{"7", "- aaaa bbbb cccc dddd eeee\n  ffff\n", "- aaaa bbbb cccc dddd\n  eeee ffff\n"},
+	{"6", "aaaa bbbb - cccc 1. dddd\n", "aaaa bbbb - cccc 1.\ndddd\n"},
+	{"5", "hard\\\nbreak kept here and there\n", "hard\\\nbreak kept here and\nthere\n"},
+	{"4", "see ((20200101 \"a block ref\")) and `some code span` here\n", "see\n((20200101 \"a block ref\"))\nand `some code span`\nhere\n"},
+	{"3", "aaaa bbbb cccc dddd eeee\nffff\n", "aaaa bbbb cccc dddd\neeee ffff\n"},
+	{"2", "一二三四五六七八九十，一二三\n", "一二三四五六七八九\n十，一二三\n"},
+	{"1", "这是一段很长的中文文本，用来测试折行的效果。\n", "这是一段很长的中文文\n本，用来测试折行的效\n果。\n"},
+	{"0", "中文\nEnglish\n", "中文 English\n"},
+}
+
+func TestReflowFormat(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetSoftBreak2HardBreak(false)
+	luteEngine.SetBlockRef(true)
+	luteEngine.SetFormatOptions(&render.FormatOptions{HardWrapColumn: 20, Reflow: true, TableAlignPadding: true})
+
+	for _, test := range reflowFormatTests {
+		formatted := luteEngine.FormatStr(test.name, test.original)
+		if test.formatted != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.formatted, formatted, test.original)
+		}
+		if again := luteEngine.FormatStr(test.name, formatted); formatted != again {
+			t.Fatalf("test case [%s] is not idempotent\n\t%q\n\t%q", test.name, formatted, again)
+		}
+	}
+}
+
+func TestReflowFormatOptions(t *testing.T) {
+	options := &render.FormatOptions{HardWrapColumn: 20, Reflow: true, TableAlignPadding: true}
+
+	// 关闭 AutoSpace 时中西文之间的软换行直接合并
+	luteEngine := lute.New()
+	luteEngine.SetSoftBreak2HardBreak(false)
+	luteEngine.SetAutoSpace(false)
+	luteEngine.SetFormatOptions(options)
+	if formatted := luteEngine.FormatStr("", "中文\nEnglish\n"); "中文English\n" != formatted {
+		t.Fatalf("auto space disabled failed, got\n\t%q", formatted)
+	}
+
+	// 软换行渲染为硬换行时不折行，否则渲染结果会发生变化
+	luteEngine = lute.New()
+	luteEngine.SetFormatOptions(options)
+	if formatted := luteEngine.FormatStr("", "aaaa bbbb cccc dddd eeee\nffff\n"); "aaaa bbbb cccc dddd eeee\nffff\n" != formatted {
+		t.Fatalf("soft break to hard break failed, got\n\t%q", formatted)
+	}
+
+	// 折行后的行首不能构成链接引用定义，格式化前后渲染结果需要一致
+	luteEngine = lute.New()
+	luteEngine.SetSoftBreak2HardBreak(false)
+	luteEngine.SetFormatOptions(&render.FormatOptions{HardWrapColumn: 10, Reflow: true, TableAlignPadding: true})
+	for _, markdown := range []string{"x [foo]: /url \"title\" ok\n", "[foo]: /url \"title\" ok\n", "[foo bar]: /url \"title\" ok\n", "xxxxxxx [foo bar]: /url \"title\" ok\n", "xxxxxxx [^foo bar]: baz\n"} {
+		formatted := luteEngine.FormatStr("", markdown)
+		// 软换行和空格渲染结果等价
+		expected := strings.ReplaceAll(luteEngine.MarkdownStr("", markdown), "\n", " ")
+		if html := strings.ReplaceAll(luteEngine.MarkdownStr("", formatted), "\n", " "); expected != html {
+			t.Fatalf("round trip failed\nexpected\n\t%q\ngot\n\t%q\nformatted markdown text\n\t%q", expected, html, formatted)
+		}
+	}
+
+	// 段落开头空两格时首行宽度需要减去缩进
+	luteEngine = lute.New()
+	luteEngine.SetSoftBreak2HardBreak(false)
+	luteEngine.SetChineseParagraphBeginningSpace(true)
+	luteEngine.SetFormatOptions(options)
+	if formatted := luteEngine.FormatStr("", "一二三四五六七八九十一二三\n"); "一二三四五六七八\n九十一二三\n" != formatted {
+		t.Fatalf("chinese paragraph beginning space failed, got\n\t%q", formatted)
+	}
+}
@@ -11,16 +11,20 @@
 package test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/88250/lute"
+	"github.com/88250/lute/render"
 )
 
 var headingIDTests = []parseTest{
 
-	{"3", "# Same\n\n ## Same\n\n # Same-\n\n ## Test\n\n ## Test-\n\n ## Test\n\n ## Same\n\n ## Test\n", "<h1 id=\"Same\">Same</h1>\n<h2 id=\"Same-\">Same</h2>\n<h1 id=\"Same--\">Same-</h1>\n<h2 id=\"Test\">Test</h2>\n<h2 id=\"Test-\">Test-</h2>\n<h2 id=\"Test--\">Test</h2>\n<h2 id=\"Same---\">Same</h2>\n<h2 id=\"Test---\">Test</h2>\n"},
-	{"2", "# Same\n\n ## Same\n\n # Same-\n", "<h1 id=\"Same\">Same</h1>\n<h2 id=\"Same-\">Same</h2>\n<h1 id=\"Same--\">Same-</h1>\n"},
-	{"1", "# Same\n\n ## Same\n", "<h1 id=\"Same\">Same</h1>\n<h2 id=\"Same-\">Same</h2>\n"},
+	{"5", "# Foo\n\n# Foo\n\n# Bar {#Foo-1}\n", "<h1 id=\"Foo\">Foo</h1>\n<h1 id=\"Foo-2\">Foo</h1>\n<h1 id=\"Foo-1\">Bar</h1>\n"},
+	{"4", "# Foo 1\n\n# Foo\n\n# Foo\n", "<h1 id=\"Foo-1\">Foo 1</h1>\n<h1 id=\"Foo\">Foo</h1>\n<h1 id=\"Foo-2\">Foo</h1>\n"},
+	{"3", "# Same\n\n ## Same\n\n # Same-\n\n ## Test\n\n ## Test-\n\n ## Test\n\n ## Same\n\n ## Test\n", "<h1 id=\"Same\">Same</h1>\n<h2 id=\"Same-1\">Same</h2>\n<h1 id=\"Same-\">Same-</h1>\n<h2 id=\"Test\">Test</h2>\n<h2 id=\"Test-\">Test-</h2>\n<h2 id=\"Test-1\">Test</h2>\n<h2 id=\"Same-2\">Same</h2>\n<h2 id=\"Test-2\">Test</h2>\n"},
+	{"2", "# Same\n\n ## Same\n\n # Same-\n", "<h1 id=\"Same\">Same</h1>\n<h2 id=\"Same-1\">Same</h2>\n<h1 id=\"Same-\">Same-</h1>\n"},
+	{"1", "# Same\n\n ## Same\n", "<h1 id=\"Same\">Same</h1>\n<h2 id=\"Same-1\">Same</h2>\n"},
 	{"0", "### Heading3 {#custom-id}\n", "<h3 id=\"custom-id\">Heading3</h3>\n"},
 }
 
@@ -53,3 +57,33 @@ func TestHeadingAnchor(t *testing.T) {
 		}
 	}
 }
+
+var headingIDSluggerTests = []parseTest{
+
+	{"2", "[toc]\n\n# Go 语言入门\n\n## 中文，标题\n\n## 中文标题\n", "<div class=\"vditor-toc\"><span class=\"toc-h1\"><a class=\"toc-a\" href=\"#go-yu-yan-ru-men\">Go 语言入门</a></span><br>&emsp;&emsp;<span class=\"toc-h2\"><a class=\"toc-a\" href=\"#zhong-wen-biao-ti\">中文，标题</a></span><br>&emsp;&emsp;<span class=\"toc-h2\"><a class=\"toc-a\" href=\"#zhong-wen-biao-ti-1\">中文标题</a></span><br></div>\n<h1 id=\"go-yu-yan-ru-men\">Go 语言入门</h1>\n<h2 id=\"zhong-wen-biao-ti\">中文，标题</h2>\n<h2 id=\"zhong-wen-biao-ti-1\">中文标题</h2>\n"},
+	{"1", "# Hello, World!\n\n# Hello World\n\n# snake_case & 中文\n", "<h1 id=\"hello-world\">Hello, World!</h1>\n<h1 id=\"hello-world-1\">Hello World</h1>\n<h1 id=\"snake_case--中文\">snake_case &amp; 中文</h1>\n"},
+	{"0", "# Foo\n\n# Foo\n", "<h1 id=\"Foo\">Foo</h1>\n<h1 id=\"Foo-1\">Foo</h1>\n"},
+}
+
+func TestHeadingIDSlugger(t *testing.T) {
+	sluggers := map[string]func(string) string{"0": render.UnicodeSlugger, "1": render.GitHubSlugger, "2": render.PinyinSlugger}
+	for _, test := range headingIDSluggerTests {
+		luteEngine := lute.New()
+		luteEngine.SetToC(true)
+		luteEngine.SetHeadingIDSlugger(sluggers[test.name])
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+func TestHeadingIDSluggerAnchor(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetHeadingAnchor(true)
+	luteEngine.SetHeadingIDSlugger(render.GitHubSlugger)
+	html := luteEngine.MarkdownStr("", "# Foo\n\n# Foo\n")
+	if expected := "href=\"#foo-1\""; !strings.Contains(html, expected) || !strings.Contains(html, "<h1 id=\"foo-1\">") {
+		t.Fatalf("heading anchor should use the de-duplicated ID, got\n\t%q", html)
+	}
+}
@@ -0,0 +1,162 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/render"
+)
+
+var htmlDocumentTests = []parseTest{
+
+	{"2", "no heading\n", "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n<title>2</title>\n</head>\n<body>\n<p>no heading</p>\n</body>\n</html>\n"},
+	{"1", "# Heading *em*\n\nfoo\n", "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n<title>Heading em</title>\n</head>\n<body>\n<h1 id=\"Heading-em\">Heading <em>em</em></h1>\n<p>foo</p>\n</body>\n</html>\n"},
+	{"0", "---\ntitle: \"Lute <doc>\"\ndescription: a & b # comment\nlang: zh-CN\ntags:\n  - x\n---\n\n# Heading\n", "<!DOCTYPE html>\n<html lang=\"zh-CN\">\n<head>\n<meta charset=\"utf-8\">\n<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n<title>Lute &lt;doc&gt;</title>\n<meta name=\"description\" content=\"a &amp; b\">\n</head>\n<body>\n<h1 id=\"Heading\">Heading</h1>\n</body>\n</html>\n"},
+}
+
+func TestHTMLDocument(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetCodeSyntaxHighlight(false)
+
+	for _, test := range htmlDocumentTests {
+		html, err := luteEngine.MarkdownDocument(test.name, []byte(test.from), nil)
+		if nil != err {
+			t.Fatalf("test case [%s] failed: %s", test.name, err)
+		}
+		if test.to != string(html) {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+func TestHTMLDocumentStyle(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetCodeSyntaxHighlightStyleName("monokai")
+	html, err := luteEngine.MarkdownDocument("", []byte("```go\nfunc main() {}\n```\n"), nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(html), "<style>\n/* Background */ .highlight-chroma { color: #f8f8f2; background-color: #272822 }") {
+		t.Fatalf("chroma style not embedded, got\n\t%q", html)
+	}
+
+	// 内联样式不需要嵌入样式表
+	luteEngine.SetCodeSyntaxHighlightInlineStyle(true)
+	if html, _ = luteEngine.MarkdownDocument("", []byte("```go\nfunc main() {}\n```\n"), nil); strings.Contains(string(html), "<style>") {
+		t.Fatalf("unexpected style, got\n\t%q", html)
+	}
+}
+
+func TestHTMLDocumentTemplate(t *testing.T) {
+	luteEngine := lute.New()
+	tpl := template.Must(template.New("").Parse("{{.Title}}|{{.Meta.author}}|{{.Body}}"))
+	html, err := luteEngine.MarkdownDocument("", []byte("---\ntitle: T\nauthor: 'D'\n---\n\n**b**\n"), &render.DocumentOptions{Template: tpl})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if expected := "T|D|<p><strong>b</strong></p>\n"; expected != string(html) {
+		t.Fatalf("template failed\nexpected\n\t%q\ngot\n\t%q", expected, html)
+	}
+}
+
+func TestHTMLDocumentInlineImages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lute")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = ioutil.WriteFile(filepath.Join(dir, "a b.png"), []byte("\x89PNG\r\n\x1a\n"), 0644); nil != err {
+		t.Fatal(err)
+	}
+
+	luteEngine := lute.New()
+	luteEngine.SetLinkBase("http://b3log.org/")
+	tpl := template.Must(template.New("").Parse("{{.Body}}"))
+	html, err := luteEngine.MarkdownDocument("", []byte("![a](a%20b.png) ![b](missing.png) ![c](https://b3log.org/c.png)\n"), &render.DocumentOptions{Template: tpl, InlineImages: true, ImageDir: dir})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if expected := "<p><img src=\"data:image/png;base64,iVBORw0KGgo=\" alt=\"a\" /> <img src=\"http://b3log.org/missing.png\" alt=\"b\" /> <img src=\"https://b3log.org/c.png\" alt=\"c\" /></p>\n"; expected != string(html) {
+		t.Fatalf("inline images failed\nexpected\n\t%q\ngot\n\t%q", expected, html)
+	}
+}
+
+func TestHTMLDocumentInlineImagesOutsideDir(t *testing.T) {
+	root, err := ioutil.TempDir("", "lute")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	dir := filepath.Join(root, "doc")
+	if err = os.Mkdir(dir, 0755); nil != err {
+		t.Fatal(err)
+	}
+	secret := filepath.Join(root, "secret.png")
+	if err = ioutil.WriteFile(secret, []byte("\x89PNG\r\n\x1a\n"), 0644); nil != err {
+		t.Fatal(err)
+	}
+	symlinkErr := os.Symlink(secret, filepath.Join(dir, "link.png"))
+
+	// 只内联 ImageDir 下的图片，../、符号链接和绝对路径指向目录外的图片保持原样
+	luteEngine := lute.New()
+	tpl := template.Must(template.New("").Parse("{{.Body}}"))
+	absDest := filepath.ToSlash(secret)
+	markdown := "![a](../secret.png) ![b](" + absDest + ") ![c](link.png)\n"
+	html, err := luteEngine.MarkdownDocument("", []byte(markdown), &render.DocumentOptions{Template: tpl, InlineImages: true, ImageDir: dir})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(html), "data:") {
+		t.Fatalf("images outside image dir inlined\n\t%q", html)
+	}
+
+	// 显式允许的话内联绝对路径，但相对路径仍然限制在 ImageDir 下
+	html, err = luteEngine.MarkdownDocument("", []byte(markdown), &render.DocumentOptions{Template: tpl, InlineImages: true, ImageDir: dir, AllowAbsoluteImagePaths: true})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if 1 != strings.Count(string(html), "data:image/png;base64,iVBORw0KGgo=") || !strings.Contains(string(html), "../secret.png") {
+		t.Fatalf("absolute image path not inlined\n\t%q", html)
+	}
+	if nil == symlinkErr && !strings.Contains(string(html), "link.png") {
+		t.Fatalf("image symlinked outside image dir inlined\n\t%q", html)
+	}
+}
+
+func TestHTMLDocumentInlineImagesLinkSchemes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lute")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = ioutil.WriteFile(filepath.Join(dir, "a.png"), []byte("\x89PNG\r\n\x1a\n"), 0644); nil != err {
+		t.Fatal(err)
+	}
+
+	// 内联生成的 data URI 不受协议白名单限制，Markdown 中直接书写的 data URI 仍然会被置空
+	luteEngine := lute.New()
+	luteEngine.SetLinkSchemes([]string{"http", "https", "mailto"})
+	tpl := template.Must(template.New("").Parse("{{.Body}}"))
+	html, err := luteEngine.MarkdownDocument("", []byte("![a](a.png) ![b](data:image/png;base64,iVBORw0KGgo=)\n"), &render.DocumentOptions{Template: tpl, InlineImages: true, ImageDir: dir})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if expected := "<p><img src=\"data:image/png;base64,iVBORw0KGgo=\" alt=\"a\" /> <img src=\"\" alt=\"b\" /></p>\n"; expected != string(html) {
+		t.Fatalf("inline images with link schemes failed\nexpected\n\t%q\ngot\n\t%q", expected, html)
+	}
+}
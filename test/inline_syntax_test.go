@@ -0,0 +1,127 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/lex"
+	"github.com/88250/lute/parse"
+)
+
+const (
+	nodeMention ast.NodeType = 512 + iota
+	nodeSpoiler
+	nodeWikilink
+)
+
+var inlineSyntaxTests = []parseTest{
+
+	{"7", "\\@foo\n", "<p>@foo</p>\n"},
+	{"6", "`@foo` [[bar]]\n", "<p><code>@foo</code> <a href=\"/wiki/bar\">bar</a></p>\n"},
+	{"5", "[[foo]] [bar](/bar)\n", "<p><a href=\"/wiki/foo\">foo</a> <a href=\"/bar\">bar</a></p>\n"},
+	{"4", "!! foo !\n", "<p>!! foo !</p>\n"},
+	{"3", "!!foo **bar** @baz!!\n", "<p><span class=\"spoiler\">foo <strong>bar</strong> <a href=\"/u/baz\">@baz</a></span></p>\n"},
+	{"2", "a @ b\n", "<p>a @ b</p>\n"},
+	{"1", "mail foo@bar.com\n", "<p>mail foo<a href=\"/u/bar\">@bar</a>.com</p>\n"},
+	{"0", "hi @foo!\n", "<p>hi <a href=\"/u/foo\">@foo</a>!</p>\n"},
+}
+
+func newInlineSyntaxLute() *lute.Lute {
+	luteEngine := lute.New()
+	luteEngine.RegisterInlineSyntax(&parse.InlineSyntax{Trigger: '@', NodeType: nodeMention, Parse: parseMention})
+	luteEngine.RegisterInlineSyntax(&parse.InlineSyntax{Trigger: '!', NodeType: nodeSpoiler, Parse: parseSpoiler, Nested: true})
+	luteEngine.RegisterInlineSyntax(&parse.InlineSyntax{Trigger: '[', NodeType: nodeWikilink, Parse: parseWikilink})
+
+	luteEngine.Md2HTMLRendererFuncs[nodeMention] = func(n *ast.Node, entering bool) (string, ast.WalkStatus) {
+		if !entering {
+			return "", ast.WalkContinue
+		}
+		return "<a href=\"/u/" + string(n.Tokens) + "\">@" + string(n.Tokens) + "</a>", ast.WalkContinue
+	}
+	luteEngine.Md2HTMLRendererFuncs[nodeSpoiler] = func(n *ast.Node, entering bool) (string, ast.WalkStatus) {
+		if entering {
+			return "<span class=\"spoiler\">", ast.WalkContinue
+		}
+		return "</span>", ast.WalkContinue
+	}
+	luteEngine.Md2HTMLRendererFuncs[nodeWikilink] = func(n *ast.Node, entering bool) (string, ast.WalkStatus) {
+		if !entering {
+			return "", ast.WalkContinue
+		}
+		return "<a href=\"/wiki/" + string(n.Tokens) + "\">" + string(n.Tokens) + "</a>", ast.WalkContinue
+	}
+	return luteEngine
+}
+
+func parseMention(tokens []byte, pos int) (ret *ast.Node, end int) {
+	for end = pos + 1; end < len(tokens) && (lex.IsASCIILetterNum(tokens[end]) || '_' == tokens[end]); end++ {
+	}
+	if pos+1 == end {
+		return nil, 0
+	}
+	return &ast.Node{Tokens: tokens[pos+1 : end]}, end
+}
+
+func parseSpoiler(tokens []byte, pos int) (ret *ast.Node, end int) {
+	if !bytes.HasPrefix(tokens[pos:], []byte("!!")) {
+		return nil, 0
+	}
+	i := bytes.Index(tokens[pos+2:], []byte("!!"))
+	if 1 > i {
+		return nil, 0
+	}
+	end = pos + 2 + i + 2
+	return &ast.Node{Tokens: tokens[pos+2 : end-2]}, end
+}
+
+func parseWikilink(tokens []byte, pos int) (ret *ast.Node, end int) {
+	if !bytes.HasPrefix(tokens[pos:], []byte("[[")) {
+		return nil, 0
+	}
+	i := bytes.Index(tokens[pos+2:], []byte("]]"))
+	if 1 > i {
+		return nil, 0
+	}
+	end = pos + 2 + i + 2
+	return &ast.Node{Tokens: tokens[pos+2 : end-2]}, end
+}
+
+func TestInlineSyntax(t *testing.T) {
+	luteEngine := newInlineSyntaxLute()
+	for _, test := range inlineSyntaxTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+func TestInlineSyntaxFormat(t *testing.T) {
+	luteEngine := newInlineSyntaxLute()
+	from := "hi  @foo, !!foo  **bar**!! [[baz]]\n"
+	expected := "hi  @foo, !!foo  **bar**!! [[baz]]\n"
+	if formatted := luteEngine.FormatStr("", from); expected != formatted {
+		t.Fatalf("format failed\nexpected\n\t%q\ngot\n\t%q", expected, formatted)
+	}
+
+	tree := parse.Parse("", []byte(from), luteEngine.Options)
+	spoiler := tree.Root.FirstChild.FirstChild.Next.Next.Next
+	if nodeSpoiler != spoiler.Type {
+		t.Fatalf("expected spoiler node, got %s", spoiler.Type)
+	}
+	if strong := spoiler.LastChild; ast.NodeStrong != strong.Type || 18 != strong.StartPos.Column || 25 != strong.EndPos.Column {
+		t.Fatalf("unexpected nested node %s at %d-%d", strong.Type, strong.StartPos.Column, strong.EndPos.Column)
+	}
+}
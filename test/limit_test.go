@@ -0,0 +1,186 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/parse"
+)
+
+var maxNestingDepthTests = []parseTest{
+
+	{"5", "*a **b [*c*](d)** e*\n", "<p>*a **b [*c*](d)** e*\n</p>\n"},
+	{"4", "*a *b *c *d *e* [[[[f](g)\n", "<p>*a *b *c *d <em>e</em> [[[<a href=\"g\">f</a></p>\n"},
+	{"3", "[[[[a](b)](c)](d)](e)\n", "<p>[[[<a href=\"b\">a</a>](c)](d)](e)</p>\n"},
+	{"2", "*a* *b* *c* *d* *e*\n", "<p><em>a</em> <em>b</em> <em>c</em> <em>d</em> <em>e</em></p>\n"},
+	{"1", "- a\n  - b\n    - c\n", "<p>- a\n  - b\n    - c\n</p>\n"},
+	{"0", "> > > > > foo\n", "<p>&gt; &gt; &gt; &gt; &gt; foo\n</p>\n"},
+}
+
+func TestMaxNestingDepth(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetMaxNestingDepth(3)
+
+	for _, test := range maxNestingDepthTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+
+	_, err := luteEngine.MarkdownE("", []byte("> > > > > foo\n"))
+	if limitErr, ok := err.(*parse.LimitError); !ok || "MaxNestingDepth" != limitErr.Name || 3 != limitErr.Limit {
+		t.Fatalf("limit error expected, got\n\t%v", err)
+	}
+}
+
+func TestMaxNestingDepthPathological(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetMaxNestingDepth(16)
+
+	// 没有匹配的开始标记符不计入嵌套深度，也不会导致耗时增长
+	for _, markdown := range []string{strings.Repeat("*a ", 30000) + strings.Repeat("b* ", 30000), strings.Repeat("[", 50000)} {
+		start := time.Now()
+		html, err := luteEngine.MarkdownE("", []byte(markdown))
+		if elapsed := time.Since(start); 3*time.Second < elapsed {
+			t.Fatalf("pathological input took too long [%s]", elapsed)
+		}
+		if nil == html && nil == err {
+			t.Fatalf("unexpected empty result")
+		}
+	}
+}
+
+func TestMaxBytes(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetMaxBytes(10)
+
+	// 超出限制时降级为转义后的文本段落，截断时不会拆开多字节字符
+	if html := luteEngine.MarkdownStr("", "# 中文中文 <b>foo</b>\n"); "<p># 中文</p>\n" != html {
+		t.Fatalf("max bytes failed, got\n\t%q", html)
+	}
+	if html := luteEngine.MarkdownStr("", "<b>foo</b>\n"); "<p>&lt;b&gt;foo&lt;/b&gt;</p>\n" != html {
+		t.Fatalf("max bytes failed, got\n\t%q", html)
+	}
+
+	_, err := luteEngine.MarkdownE("", []byte("# 中文中文 <b>foo</b>\n"))
+	if limitErr, ok := err.(*parse.LimitError); !ok || "MaxBytes" != limitErr.Name || 10 != limitErr.Limit {
+		t.Fatalf("limit error expected, got\n\t%v", err)
+	}
+	if html, err := luteEngine.MarkdownE("", []byte("*foo*\n")); nil != err || "<p><em>foo</em></p>\n" != string(html) {
+		t.Fatalf("markdown failed, got\n\t%q\n\t%v", html, err)
+	}
+}
+
+func TestMaxNodes(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetMaxNodes(5)
+
+	// 链接引用定义也计入节点数
+	if html := luteEngine.MarkdownStr("", "# a *b* c\n\n[x]: y\n"); "<p># a *b* c\n\n[x]: y\n</p>\n" != html {
+		t.Fatalf("max nodes failed, got\n\t%q", html)
+	}
+	if _, err := luteEngine.FormatE("", []byte("# a *b* c\n\n[x]: y\n")); "exceeded limit [MaxNodes=5]" != err.Error() {
+		t.Fatalf("limit error expected, got\n\t%v", err)
+	}
+	if formatted, err := luteEngine.FormatE("", []byte("foo\n")); nil != err || "foo\n" != string(formatted) {
+		t.Fatalf("format failed, got\n\t%q\n\t%v", formatted, err)
+	}
+}
+
+func TestMaxOutputBytes(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetMaxOutputBytes(12)
+
+	if html := luteEngine.MarkdownStr("", "foo\n\nbar\n\nbaz\n"); "<p>foo</p>\n<" != html {
+		t.Fatalf("max output bytes failed, got\n\t%q", html)
+	}
+	html, err := luteEngine.MarkdownE("", []byte("foo\n\nbar\n\nbaz\n"))
+	if limitErr, ok := err.(*parse.LimitError); !ok || "MaxOutputBytes" != limitErr.Name || nil != html {
+		t.Fatalf("limit error expected, got\n\t%q\n\t%v", html, err)
+	}
+
+	buf := &strings.Builder{}
+	if err = luteEngine.MarkdownTo(buf, "", strings.NewReader("foo\n\nbar\n\nbaz\n")); nil == err || "<p>foo</p>\n" != buf.String() {
+		t.Fatalf("stream max output bytes failed, got\n\t%q\n\t%v", buf.String(), err)
+	}
+}
+
+func TestMarkdownContext(t *testing.T) {
+	luteEngine := lute.New()
+	markdown := []byte(strings.Repeat("foo *bar* baz\n\n", 100000))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	html, err := luteEngine.MarkdownContext(ctx, "", markdown)
+	if context.DeadlineExceeded != err || nil != html {
+		t.Fatalf("deadline exceeded expected, got\n\t%v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err = luteEngine.FormatContext(ctx, "", markdown); context.Canceled != err {
+		t.Fatalf("canceled expected, got\n\t%v", err)
+	}
+
+	if html, err = luteEngine.MarkdownContext(context.Background(), "", []byte("foo\n")); nil != err || "<p>foo</p>\n" != string(html) {
+		t.Fatalf("markdown failed, got\n\t%q\n\t%v", html, err)
+	}
+}
+
+func TestMarkdownContextOvershoot(t *testing.T) {
+	luteEngine := lute.New()
+
+	// 单个块或者单行内容很大时也需要及时响应取消，不能等到整个块解析完成
+	refs := &strings.Builder{}
+	for i := 0; i < 50000; i++ {
+		refs.WriteString("[a" + strconv.Itoa(i) + "]: /u\n")
+	}
+	for _, markdown := range []string{refs.String(), strings.Repeat(">", 20000) + " foo\n", strings.Repeat("*a* [b](c) ", 100000)} {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		start := time.Now()
+		_, err := luteEngine.MarkdownContext(ctx, "", []byte(markdown))
+		cancel()
+		if elapsed := time.Since(start); time.Second < elapsed {
+			t.Fatalf("deadline overshoot [%s]", elapsed)
+		}
+		if context.DeadlineExceeded != err {
+			t.Fatalf("deadline exceeded expected, got\n\t%v", err)
+		}
+	}
+}
+
+func TestMaxNodesEarlyAbort(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetMaxNodes(1000)
+
+	// 超出最大节点数后应尽快中止，不能等到整个段落或者所有链接引用定义解析完成
+	refs := &strings.Builder{}
+	for i := 0; i < 50000; i++ {
+		refs.WriteString("[a" + strconv.Itoa(i) + "]: /u\n")
+	}
+	for _, markdown := range []string{refs.String(), strings.Repeat("*a* [b](c) ", 100000)} {
+		start := time.Now()
+		_, err := luteEngine.MarkdownE("", []byte(markdown))
+		if elapsed := time.Since(start); time.Second < elapsed {
+			t.Fatalf("max nodes abort took too long [%s]", elapsed)
+		}
+		if limitErr, ok := err.(*parse.LimitError); !ok || "MaxNodes" != limitErr.Name {
+			t.Fatalf("limit error expected, got\n\t%v", err)
+		}
+	}
+}
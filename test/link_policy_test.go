@@ -0,0 +1,96 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/parse"
+)
+
+var linkSchemesTests = []parseTest{
+
+	{"6", "<javascript:alert(1)>\n", "<p><a href=\"\">javascript:alert(1)</a></p>\n"},
+	{"5", "[foo][bar]\n\n[bar]: vbscript:msgbox(1)\n", "<p><a href=\"\">foo</a></p>\n"},
+	{"4", "![foo](data:image/svg+xml;base64,bar)\n", "<p><img src=\"\" alt=\"foo\" /></p>\n"},
+	{"3", "[foo](JaVa&#9;ScRiPt:alert(1)) [bar](JaVaScRiPt:alert(1))\n", "<p><a href=\"JaVa%09ScRiPt:alert(1)\">foo</a> <a href=\"\">bar</a></p>\n"},
+	{"2", "[foo](bar/baz:qux) [foo](#bar)\n", "<p><a href=\"bar/baz:qux\">foo</a> <a href=\"#bar\">foo</a></p>\n"},
+	{"1", "[foo](mailto:foo@bar.com)\n", "<p><a href=\"mailto:foo@bar.com\">foo</a></p>\n"},
+	{"0", "[foo](https://b3log.org)\n", "<p><a href=\"https://b3log.org\">foo</a></p>\n"},
+}
+
+func TestLinkSchemes(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetLinkSchemes([]string{"http", "https", "mailto"})
+
+	for _, test := range linkSchemesTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+var externalLinkTests = []parseTest{
+
+	{"5", "![foo](https://evil.com/foo.png)\n", "<p><img src=\"https://evil.com/foo.png\" alt=\"foo\" /></p>\n"},
+	{"4", "https://evil.com/foo?a=1&b=2\n", "<p><a href=\"/goto?url=https%3A%2F%2Fevil.com%2Ffoo%3Fa%3D1%26b%3D2\" target=\"_blank\" rel=\"noopener noreferrer nofollow\">https://evil.com/foo?a=1&amp;b=2</a></p>\n"},
+	{"3", "[foo][bar]\n\n[bar]: //evil.com\n", "<p><a href=\"/goto?url=%2F%2Fevil.com\" target=\"_blank\" rel=\"noopener noreferrer nofollow\">foo</a></p>\n"},
+	{"2", "[foo](/bar) [baz](mailto:foo@bar.com)\n", "<p><a href=\"/bar\">foo</a> <a href=\"mailto:foo@bar.com\">baz</a></p>\n"},
+	{"1", "[foo](https://ld246.com/article) [bar](http://B3LOG.org)\n", "<p><a href=\"https://ld246.com/article\">foo</a> <a href=\"http://B3LOG.org\">bar</a></p>\n"},
+	{"0", "[foo](https://github.com/88250/lute \"bar\")\n", "<p><a href=\"/goto?url=https%3A%2F%2Fgithub.com%2F88250%2Flute\" title=\"bar\" target=\"_blank\" rel=\"noopener noreferrer nofollow\">foo</a></p>\n"},
+}
+
+func TestExternalLink(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetExternalLinkTarget(true)
+	luteEngine.SetInternalLinkHosts([]string{"b3log.org", "ld246.com"})
+	luteEngine.SetExternalLinkRedirect("/goto?url=")
+
+	for _, test := range externalLinkTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+func TestLinkCallback(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetExternalLinkTarget(true)
+	var dests []string
+	luteEngine.SetLinkCallback(func(link *parse.Link) {
+		dests = append(dests, link.Dest)
+		if link.Image {
+			link.Attrs = append(link.Attrs, []string{"loading", "lazy"})
+			return
+		}
+		if strings.Contains(link.Dest, "evil") {
+			link.Dest = ""
+			link.Attrs = nil
+			return
+		}
+		if link.External {
+			link.Attrs = append(link.Attrs, []string{"data-external", "\"true\""})
+		}
+	})
+
+	html := luteEngine.MarkdownStr("", "[foo](https://evil.com) https://b3log.org ![bar](bar.png)\n")
+	expected := "<p><a href=\"\">foo</a> <a href=\"https://b3log.org\" target=\"_blank\" rel=\"noopener noreferrer nofollow\" data-external=\"&quot;true&quot;\">https://b3log.org</a> <img src=\"bar.png\" loading=\"lazy\" alt=\"bar\" /></p>\n"
+	if expected != html {
+		t.Fatalf("link callback failed\nexpected\n\t%q\ngot\n\t%q", expected, html)
+	}
+	if "https://evil.com,https://b3log.org,bar.png" != strings.Join(dests, ",") {
+		t.Fatalf("link callback dests failed, got\n\t%q", dests)
+	}
+}
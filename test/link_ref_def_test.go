@@ -0,0 +1,75 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var keepLinkRefDefsFormatTests = []formatTest{
+
+	{"9", "[a]:\n/u\n'multi\nline'\n\n[a]\n", "[a]: /u \"multi\nline\"\n\n[a]\n"},
+	{"8", "[e]: <>\n", "[e]: <>\n"},
+	{"7", "[a]: /u\nTitle\n===\n", "[a]: /u\nTitle\n=====\n"},
+	{"6", "> [A]: /u \"t\"\n>\n> [A]\n", "> [A]: /u \"t\"\n>\n> [A]\n"},
+	{"5", "- [a]: /u\n  foo\n- b [a]\n", "- [a]: /u\n  foo\n- b [a]\n"},
+	{"4", "[a]: /u (x \"y\" 'z')\n", "[a]: /u (x \"y\" 'z')\n"},
+	{"3", "[a]: /u (x \"y\")\n", "[a]: /u 'x \"y\"'\n"},
+	{"2", "[a]: </x y> (t)\n", "[a]: /x%20y \"t\"\n"},
+	{"1", "[Foo]: /u\n\n# H\n\n[foo]: /dup\n[Bar]: /b\n", "[Foo]: /u\n\n# H\n\n[foo]: /dup\n[Bar]: /b\n"},
+	{"0", "[z]: /z\n[a]: /a \"t\"\nText [z] [a]\n", "[z]: /z\n[a]: /a \"t\"\nText [z] [a]\n"},
+}
+
+func TestKeepLinkRefDefsFormat(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKeepLinkRefDefs(true)
+
+	for _, test := range keepLinkRefDefsFormatTests {
+		formatted := luteEngine.FormatStr(test.name, test.original)
+		if test.formatted != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.formatted, formatted, test.original)
+		}
+
+		// 格式化需要是幂等的，并且不改变渲染结果
+		if again := luteEngine.FormatStr(test.name, formatted); formatted != again {
+			t.Fatalf("test case [%s] is not idempotent\n\t%q\n\t%q", test.name, formatted, again)
+		}
+		if expected, html := luteEngine.MarkdownStr(test.name, test.original), luteEngine.MarkdownStr(test.name, formatted); expected != html {
+			t.Fatalf("test case [%s] changed html\nexpected\n\t%q\ngot\n\t%q", test.name, expected, html)
+		}
+	}
+}
+
+func TestKeepLinkRefDefsHTML(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKeepLinkRefDefs(true)
+
+	html := luteEngine.MarkdownStr("", "[a]: /u \"t\"\n[b]: /b\nfoo [a]\n")
+	if expected := "<p>foo <a href=\"/u\" title=\"t\">a</a></p>\n"; expected != html {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, html)
+	}
+}
+
+func TestLinkRefDefsFormatOrder(t *testing.T) {
+	luteEngine := lute.New()
+
+	// 未开启 KeepLinkRefDefs 时定义仍然追加到末尾，但按原文顺序输出并保留标题
+	original := "[z]: /z\n[B]: /b 'x'\n[a]: /a\n\n[z] [b] [a]\n"
+	expected := "[z] [b] [a]\n\n[z]: /z\n[B]: /b \"x\"\n[a]: /a\n"
+	for i := 0; i < 16; i++ {
+		formatted := luteEngine.FormatStr("", original)
+		if expected != formatted {
+			t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, formatted)
+		}
+	}
+}
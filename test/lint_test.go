@@ -0,0 +1,92 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+)
+
+var lintTests = []parseTest{
+
+	{"17", "# foo bar {#foo-bar}\n\n# foo bar\n", "3:1-3:10: warning: duplicate heading ID [foo-bar], first defined at line 1 [duplicate-heading-id]\n"},
+	{"16", "# foo bar\n\n# foo-bar\n", "3:1-3:10: warning: duplicate heading ID [foo-bar], first defined at line 1 [duplicate-heading-id]\n"},
+	{"15", "---\ntitle: a\ntags: [a, b\n---\n", "3:1-3:12: error: invalid YAML front matter: expected , or ] in flow sequence [invalid-yaml-front-matter]\n"},
+	{"14", "---\n\ntitle: a\n  b: c\n---\n", "4:1-4:7: error: invalid YAML front matter: unexpected indentation [invalid-yaml-front-matter]\n"},
+	{"13", "foo\n", ""},
+	{"12", "[foo]\n\n[foo]: /url\n", ""},
+	{"11", "[x](/url) [y][]\n\n[y]: /y\n", ""},
+	{"10", "- [ ] todo\n", ""},
+	{"9", "((20200813131152-0wk5akh \"text\"\n", "1:1-1:25: warning: block ref ((20200813131152-0wk5akh is not closed with )) [unclosed-block-ref]\n"},
+	{"8", "((20200813131152-0wk5akh))\n", ""},
+	{"7", "# foo {}\n", "1:7-1:9: warning: heading ID {} is empty [empty-heading-id]\n"},
+	{"6", "# foo {#bar}\n\n## baz {#bar}\n", "3:1-3:14: error: duplicate heading ID [bar], first defined at line 1 [duplicate-heading-id]\n"},
+	{"5", "# Foo\n\n# foo\n", ""},
+	{"4", "foo[^1]\n", "1:4-1:8: error: footnote [^1] is not defined [undefined-footnote]\n"},
+	{"3", "a [foo][bar] b\n", "1:3-1:13: error: link reference [bar] is not defined [undefined-link-ref]\n"},
+	{"2", "a [foo] b\n", "1:3-1:8: warning: link reference [foo] is not defined [undefined-link-ref]\n"},
+	{"1", "foo\n\n$$\nbar\n", "3:1-4:4: error: math block is not closed with $$ [unclosed-math-block]\n"},
+	{"0", "foo\n\n```go\nbar\n", "3:1-4:4: error: fenced code block is not closed [unclosed-code-block]\n"},
+}
+
+func TestLint(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.BlockRef = true
+	luteEngine.GFMTaskListItem = false
+
+	for _, test := range lintTests {
+		var buf strings.Builder
+		for _, d := range luteEngine.Lint(test.name, []byte(test.from)) {
+			buf.WriteString(lintPos(d.StartPos) + "-" + lintPos(d.EndPos) + ": " + d.Severity.String() + ": " + d.Message + " [" + d.Code + "]\n")
+		}
+		if got := buf.String(); test.to != got {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, got, test.from)
+		}
+	}
+}
+
+func TestLintHeadingIDSlugger(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetHeadingIDSlugger(render.GitHubSlugger)
+	diagnostics := luteEngine.Lint("", []byte("# Foo\n\n# foo\n"))
+	if 1 != len(diagnostics) || "duplicate heading ID [foo], first defined at line 1" != diagnostics[0].Message {
+		t.Fatalf("expected duplicate heading ID generated by GitHubSlugger, got %v", diagnostics)
+	}
+}
+
+func lintPos(pos ast.Position) string {
+	return strconv.Itoa(pos.Line) + ":" + strconv.Itoa(pos.Column)
+}
+
+func TestReparseDiagnostics(t *testing.T) {
+	luteEngine := lute.New()
+	from := "# foo\n\nbar\n\n[baz]\n\n# foo\n"
+	tree := parse.Parse("", []byte(from), luteEngine.Options)
+	edit := &parse.Edit{Start: 7, End: 10, Text: []byte("[qux]\n\nbar")}
+	if err := tree.Reparse(edit); nil != err {
+		t.Fatalf("reparse failed: %s", err)
+	}
+	expected := parse.Parse("", tree.Source, luteEngine.Options).Diagnostics
+	if len(expected) != len(tree.Diagnostics) {
+		t.Fatalf("expected %d diagnostics, got %d", len(expected), len(tree.Diagnostics))
+	}
+	for i, d := range tree.Diagnostics {
+		if *expected[i] != *d {
+			t.Fatalf("diagnostic [%d] expected %v, got %v", i, expected[i], d)
+		}
+	}
+}
@@ -11,9 +11,14 @@
 package test
 
 import (
-	"github.com/88250/lute/util"
 	"sync"
 	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+	"github.com/88250/lute/util"
 )
 
 func TestRecover(t *testing.T) {
@@ -29,3 +34,86 @@ func TestRecover(t *testing.T) {
 	wg.Wait()
 	t.Log(err)
 }
+
+func TestMarkdownE(t *testing.T) {
+	luteEngine := lute.New()
+	html, err := luteEngine.MarkdownE("", []byte("foo *bar*\n"))
+	if nil != err || "<p>foo <em>bar</em></p>\n" != string(html) {
+		t.Fatalf("markdown failed, got\n\t%q\n\t%v", html, err)
+	}
+
+	luteEngine.Md2HTMLRendererFuncs[ast.NodeEmphasis] = func(n *ast.Node, entering bool) (string, ast.WalkStatus) {
+		panic("bad emphasis")
+	}
+	html, err = luteEngine.MarkdownE("", []byte("foo *bar*\n"))
+	renderErr, ok := err.(*render.RenderError)
+	if !ok || ast.NodeEmphasis != renderErr.NodeType || nil != html {
+		t.Fatalf("render error expected, got\n\t%q\n\t%v", html, err)
+	}
+	if "render [NodeEmphasis] failed: bad emphasis" != err.Error() {
+		t.Fatalf("unexpected error message [%s]", err.Error())
+	}
+
+	// 解析时发生 panic
+	luteEngine = lute.New()
+	luteEngine.RegisterInlineSyntax(&parse.InlineSyntax{Trigger: '%', NodeType: ast.NodeText, Parse: func(tokens []byte, pos int) (*ast.Node, int) {
+		panic("bad syntax")
+	}})
+	_, err = luteEngine.MarkdownE("doc", []byte("foo % bar\n"))
+	if _, ok := err.(*parse.ParseError); !ok || "parse [doc] failed: bad syntax" != err.Error() {
+		t.Fatalf("parse error expected, got\n\t%v", err)
+	}
+}
+
+func TestFormatE(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.FormatRendererFuncs[ast.NodeStrong] = func(n *ast.Node, entering bool) (string, ast.WalkStatus) {
+		panic("bad strong")
+	}
+
+	// 多个协程中发生的 panic 都会被恢复，不会导致进程退出
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			formatted, err := luteEngine.FormatE("", []byte("foo **bar**\n"))
+			if renderErr, ok := err.(*render.RenderError); !ok || ast.NodeStrong != renderErr.NodeType || nil != formatted {
+				t.Errorf("render error expected, got\n\t%q\n\t%v", formatted, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	formatted, err := luteEngine.FormatE("", []byte("foo *bar*\n"))
+	if nil != err || "foo *bar*\n" != string(formatted) {
+		t.Fatalf("format failed, got\n\t%q\n\t%v", formatted, err)
+	}
+}
+
+func TestHTML2MarkdownE(t *testing.T) {
+	luteEngine := lute.New()
+	markdown, err := luteEngine.HTML2MarkdownE("<p>foo <em>bar</em></p>")
+	if nil != err || "foo *bar*\n" != markdown {
+		t.Fatalf("html to markdown failed, got\n\t%q\n\t%v", markdown, err)
+	}
+
+	luteEngine.HTML2MdRendererFuncs[ast.NodeEmphasis] = func(n *ast.Node, entering bool) (string, ast.WalkStatus) {
+		panic("bad emphasis")
+	}
+	markdown, err = luteEngine.HTML2MarkdownE("<p>foo <em>bar</em></p>")
+	if renderErr, ok := err.(*render.RenderError); !ok || ast.NodeEmphasis != renderErr.NodeType || "" != markdown {
+		t.Fatalf("render error expected, got\n\t%q\n\t%v", markdown, err)
+	}
+}
+
+func TestMd2VditorIRDOME(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.Md2VditorIRDOMRendererFuncs[ast.NodeCodeSpan] = func(n *ast.Node, entering bool) (string, ast.WalkStatus) {
+		panic("bad code span")
+	}
+	vHTML, err := luteEngine.Md2VditorIRDOME("foo `bar`\n")
+	if renderErr, ok := err.(*render.RenderError); !ok || ast.NodeCodeSpan != renderErr.NodeType || "" != vHTML {
+		t.Fatalf("render error expected, got\n\t%q\n\t%v", vHTML, err)
+	}
+}
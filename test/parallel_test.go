@@ -11,11 +11,17 @@
 package test
 
 import (
+	"bytes"
+	"encoding/json"
 	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 
 	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
 )
 
 func TestParallel(t *testing.T) {
@@ -47,3 +53,143 @@ func TestParallel(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+// sharedEngineCalls 覆盖了引擎的各个公开方法，用于在多个协程中并发调用同一个引擎。
+var sharedEngineCalls = map[string]func(engine *lute.Lute) string{
+	"Markdown": func(engine *lute.Lute) string { return string(engine.Markdown("", []byte(parallelMarkdown))) },
+	"MarkdownE": func(engine *lute.Lute) string {
+		html, _ := engine.MarkdownE("", []byte(parallelMarkdown))
+		return string(html)
+	},
+	"MarkdownStr": func(engine *lute.Lute) string { return engine.MarkdownStr("", parallelMarkdown) },
+	"MarkdownTo": func(engine *lute.Lute) string {
+		buf := &bytes.Buffer{}
+		engine.MarkdownTo(buf, "", strings.NewReader(parallelMarkdown))
+		return buf.String()
+	},
+	"MarkdownDocument": func(engine *lute.Lute) string {
+		html, _ := engine.MarkdownDocument("", []byte(parallelMarkdown), nil)
+		return string(html)
+	},
+	"Format": func(engine *lute.Lute) string { return string(engine.Format("", []byte(parallelMarkdown))) },
+	"FormatE": func(engine *lute.Lute) string {
+		formatted, _ := engine.FormatE("", []byte(parallelMarkdown))
+		return string(formatted)
+	},
+	"FormatStr": func(engine *lute.Lute) string { return engine.FormatStr("", parallelMarkdown) },
+	"FormatTo": func(engine *lute.Lute) string {
+		buf := &bytes.Buffer{}
+		engine.FormatTo(buf, "", strings.NewReader(parallelMarkdown))
+		return buf.String()
+	},
+	"FormatTree":  func(engine *lute.Lute) string { return string(engine.FormatTree(engine.HTML2Tree(parallelHTML))) },
+	"FormatCheck": func(engine *lute.Lute) string { return engine.FormatCheck("", []byte(parallelMarkdown)).Diff },
+	"Lint":        func(engine *lute.Lute) string { return strconv.Itoa(len(engine.Lint("", []byte(parallelMarkdown)))) },
+	"Outline": func(engine *lute.Lute) string {
+		data, _ := json.Marshal(engine.Outline([]byte(parallelMarkdown)))
+		return string(data)
+	},
+	"TextBundleStr": func(engine *lute.Lute) string {
+		textbundle, _ := engine.TextBundleStr("", parallelMarkdown, []string{"https://b3log.org"})
+		return textbundle
+	},
+	"HTML2Markdown": func(engine *lute.Lute) string {
+		markdown, _ := engine.HTML2Markdown(parallelHTML)
+		return markdown
+	},
+	"HTML2MarkdownE": func(engine *lute.Lute) string {
+		markdown, _ := engine.HTML2MarkdownE(parallelHTML)
+		return markdown
+	},
+	"HTML2Md":                  func(engine *lute.Lute) string { return engine.HTML2Md(parallelHTML) },
+	"HTML2Text":                func(engine *lute.Lute) string { return engine.HTML2Text(parallelHTML) },
+	"Space":                    func(engine *lute.Lute) string { return engine.Space("中文English") },
+	"RenderEChartsJSON":        func(engine *lute.Lute) string { return engine.RenderEChartsJSON(parallelMarkdown) },
+	"Md2HTML":                  func(engine *lute.Lute) string { return engine.Md2HTML(parallelMarkdown) },
+	"SpinVditorDOM":            func(engine *lute.Lute) string { return engine.SpinVditorDOM(parallelHTML) },
+	"HTML2VditorDOM":           func(engine *lute.Lute) string { return engine.HTML2VditorDOM(parallelHTML) },
+	"VditorDOM2HTML":           func(engine *lute.Lute) string { return engine.VditorDOM2HTML(parallelHTML) },
+	"Md2VditorDOM":             func(engine *lute.Lute) string { return engine.Md2VditorDOM(parallelMarkdown) },
+	"VditorDOM2Md":             func(engine *lute.Lute) string { return engine.VditorDOM2Md(parallelHTML) },
+	"SpinVditorIRDOM":          func(engine *lute.Lute) string { return engine.SpinVditorIRDOM(parallelHTML) },
+	"HTML2VditorIRDOM":         func(engine *lute.Lute) string { return engine.HTML2VditorIRDOM(parallelHTML) },
+	"VditorIRDOM2HTML":         func(engine *lute.Lute) string { return engine.VditorIRDOM2HTML(parallelHTML) },
+	"Md2VditorIRDOM":           func(engine *lute.Lute) string { return engine.Md2VditorIRDOM(parallelMarkdown) },
+	"VditorIRDOM2Md":           func(engine *lute.Lute) string { return engine.VditorIRDOM2Md(parallelHTML) },
+	"SpinVditorIRBlockDOM":     func(engine *lute.Lute) string { return engine.SpinVditorIRBlockDOM(parallelHTML) },
+	"HTML2VditorIRBlockDOM":    func(engine *lute.Lute) string { return engine.HTML2VditorIRBlockDOM(parallelHTML) },
+	"VditorIRBlockDOM2HTML":    func(engine *lute.Lute) string { return engine.VditorIRBlockDOM2HTML(parallelHTML) },
+	"Md2VditorIRBlockDOM":      func(engine *lute.Lute) string { return engine.Md2VditorIRBlockDOM(parallelMarkdown) },
+	"VditorIRBlockDOM2Md":      func(engine *lute.Lute) string { return engine.VditorIRBlockDOM2Md(parallelHTML) },
+	"VditorIRBlockDOM2Text":    func(engine *lute.Lute) string { return engine.VditorIRBlockDOM2Text(parallelHTML) },
+	"VditorIRBlockDOMHeadings": func(engine *lute.Lute) string { return engine.VditorIRBlockDOMHeadings(parallelHTML) },
+	"Tree2VditorIRBlockDOM": func(engine *lute.Lute) string {
+		return engine.Tree2VditorIRBlockDOM(engine.HTML2Tree(parallelHTML))
+	},
+	"SpinVditorSVDOM":  func(engine *lute.Lute) string { return engine.SpinVditorSVDOM(parallelMarkdown) },
+	"HTML2VditorSVDOM": func(engine *lute.Lute) string { return engine.HTML2VditorSVDOM(parallelHTML) },
+	"Md2VditorSVDOM":   func(engine *lute.Lute) string { return engine.Md2VditorSVDOM(parallelMarkdown) },
+	"With": func(engine *lute.Lute) string {
+		return engine.With(func(l *lute.Lute) { l.SetToC(true) }).MarkdownStr("", parallelMarkdown)
+	},
+}
+
+const parallelMarkdown = "---\ntitle: foo\n---\n\n# 标题 *foo*\n\n* [ ] 任务 :heart:\n* [x] ~~完成~~ https://b3log.org\n\n| a | b |\n| - | - |\n| c | d |\n\n```go\nfoo\n```\n\n脚注[^1]\n\n[^1]: 定义\n"
+
+const parallelHTML = "<h1>标题 <em>foo</em></h1><ul><li>列表<strong>强调</strong></li></ul><pre><code class=\"language-go\">foo\n</code></pre><p>段落 <a href=\"https://b3log.org\">链接</a></p>"
+
+// nodeIDPattern 用于去掉每次渲染都会重新生成的节点 ID。
+var nodeIDPattern = regexp.MustCompile(`data-node-id="[^"]*"`)
+
+// TestParallelSharedEngine 在多个协程中并发调用同一个引擎的各个公开方法，需要使用 go test -race 检查数据竞争。
+func TestParallelSharedEngine(t *testing.T) {
+	newEngine := func() *lute.Lute {
+		engine := lute.New()
+		engine.SetToC(true)
+		engine.SetKramdownIAL(true)
+		return engine
+	}
+
+	// 串行调用时每个方法使用新的引擎，得到的结果作为期望结果
+	expected := map[string]string{}
+	for name, call := range sharedEngineCalls {
+		expected[name] = nodeIDPattern.ReplaceAllString(call(newEngine()), "")
+	}
+
+	engine := newEngine()
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		for name, call := range sharedEngineCalls {
+			wg.Add(1)
+			go func(name string, call func(engine *lute.Lute) string) {
+				defer wg.Done()
+				if got := nodeIDPattern.ReplaceAllString(call(engine), ""); expected[name] != got {
+					t.Errorf("shared engine call [%s] failed\nexpected\n\t%q\ngot\n\t%q", name, expected[name], got)
+				}
+			}(name, call)
+		}
+	}
+	wg.Wait()
+}
+
+func TestWith(t *testing.T) {
+	engine := lute.New()
+	engine.PutTerms(map[string]string{"lute": "Lute"})
+	derived := engine.With(func(l *lute.Lute) {
+		l.SetAutoSpace(false)
+		l.PutTerms(map[string]string{"vditor": "Vditor"})
+	})
+	derived.Md2HTMLRendererFuncs[ast.NodeStrong] = func(n *ast.Node, entering bool) (string, ast.WalkStatus) {
+		return "strong", ast.WalkSkipChildren
+	}
+
+	if html := engine.MarkdownStr("", "lute vditor中文 **foo**\n"); "<p>Lute vditor 中文 <strong>foo</strong></p>\n" != html {
+		t.Fatalf("engine is changed by derived engine, got\n\t%q", html)
+	}
+	if html := derived.MarkdownStr("", "lute vditor中文 **foo**\n"); "<p>Lute Vditor中文 strongstrong</p>\n" != html {
+		t.Fatalf("derived engine failed, got\n\t%q", html)
+	}
+	if _, ok := derived.GetTerms()["lute"]; !ok {
+		t.Fatalf("derived engine should inherit terms")
+	}
+}
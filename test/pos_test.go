@@ -0,0 +1,62 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+)
+
+var posTests = []parseTest{
+
+	{"6", "x\u0000y _e_\n", "NodeDocument 1:1-1:8 [0,7)\nNodeParagraph 1:1-1:8 [0,7)\nNodeText 1:1-1:5 [0,4)\nNodeEmphasis 1:5-1:8 [4,7)\nNodeEmU8eOpenMarker 1:5-1:6 [4,5)\nNodeText 1:6-1:7 [5,6)\nNodeEmU8eCloseMarker 1:7-1:8 [6,7)\n"},
+	{"5", "| a | b |\n|---|---|\n| c | d |\n", "NodeDocument 1:1-3:10 [0,29)\nNodeTable 1:1-3:10 [0,29)\nNodeTableHead 1:1-1:10 [0,9)\nNodeTableRow 1:1-1:10 [0,9)\nNodeTableCell 1:3-1:4 [2,3)\nNodeText 1:3-1:4 [2,3)\nNodeTableCell 1:7-1:8 [6,7)\nNodeText 1:7-1:8 [6,7)\nNodeTableRow 3:1-3:10 [20,29)\nNodeTableCell 3:3-3:4 [22,23)\nNodeText 3:3-3:4 [22,23)\nNodeTableCell 3:7-3:8 [26,27)\nNodeText 3:7-3:8 [26,27)\n"},
+	{"4", "```go\nfoo\n```\n", "NodeDocument 1:1-3:4 [0,13)\nNodeCodeBlock 1:1-3:4 [0,13)\nNodeCodeBlockFenceOpenMarker 1:1-1:4 [0,3)\nNodeCodeBlockFenceInfoMarker 1:4-1:6 [3,5)\nNodeCodeBlockCode 2:1-3:1 [6,10)\nNodeCodeBlockFenceCloseMarker 3:1-3:4 [10,13)\n"},
+	{"3", "- a\n- b `c`\n\n", "NodeDocument 1:1-2:8 [0,11)\nNodeList 1:1-2:8 [0,11)\nNodeListItem 1:1-1:4 [0,3)\nNodeParagraph 1:3-1:4 [2,3)\nNodeText 1:3-1:4 [2,3)\nNodeListItem 2:1-2:8 [4,11)\nNodeParagraph 2:3-2:8 [6,11)\nNodeText 2:3-2:5 [6,8)\nNodeCodeSpan 2:5-2:8 [8,11)\nNodeCodeSpanOpenMarker 2:5-2:6 [8,9)\nNodeCodeSpanContent 2:6-2:7 [9,10)\nNodeCodeSpanCloseMarker 2:7-2:8 [10,11)\n"},
+	{"2", "> [a](/u \"t\")\n> b\n", "NodeDocument 1:1-2:4 [0,17)\nNodeBlockquote 1:1-2:4 [0,17)\nNodeBlockquoteMarker 1:1-1:3 [0,2)\nNodeParagraph 1:3-2:4 [2,17)\nNodeLink 1:3-1:14 [2,13)\nNodeOpenBracket 1:3-1:4 [2,3)\nNodeLinkText 1:4-1:5 [3,4)\nNodeCloseBracket 1:5-1:6 [4,5)\nNodeOpenParen 1:6-1:7 [5,6)\nNodeLinkDest 1:7-1:9 [6,8)\nNodeLinkSpace 1:9-1:10 [8,9)\nNodeLinkTitle 1:11-1:12 [10,11)\nNodeCloseParen 1:13-1:14 [12,13)\nNodeSoftBreak 1:14-2:1 [13,14)\nNodeText 2:3-2:4 [16,17)\n"},
+	{"1", "# foo *bar*\r\n\r\nbaz\r\n", "NodeDocument 1:1-3:4 [0,18)\nNodeHeading 1:1-1:12 [0,11)\nNodeHeadingC8hMarker 1:1-1:3 [0,2)\nNodeText 1:3-1:7 [2,6)\nNodeEmphasis 1:7-1:12 [6,11)\nNodeEmA6kOpenMarker 1:7-1:8 [6,7)\nNodeText 1:8-1:11 [7,10)\nNodeEmA6kCloseMarker 1:11-1:12 [10,11)\nNodeParagraph 3:1-3:4 [15,18)\nNodeText 3:1-3:4 [15,18)\n"},
+	{"0", "foo\n", "NodeDocument 1:1-1:4 [0,3)\nNodeParagraph 1:1-1:4 [0,3)\nNodeText 1:1-1:4 [0,3)\n"},
+}
+
+func TestPos(t *testing.T) {
+	luteEngine := lute.New()
+
+	for _, test := range posTests {
+		tree := parse.Parse(test.name, []byte(test.from), luteEngine.Options)
+		var buf strings.Builder
+		ast.Walk(tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+			if entering {
+				buf.WriteString(fmt.Sprintf("%s %d:%d-%d:%d [%d,%d)\n", n.Type, n.StartPos.Line, n.StartPos.Column, n.EndPos.Line, n.EndPos.Column, n.StartPos.Offset, n.EndPos.Offset))
+			}
+			return ast.WalkContinue
+		})
+		if got := buf.String(); test.to != got {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, got, test.from)
+		}
+	}
+}
+
+func TestLinkRefDefPos(t *testing.T) {
+	luteEngine := lute.New()
+	tree := parse.Parse("", []byte("foo\n\n[bar]: /url \"title\"\n"), luteEngine.Options)
+	def := tree.Context.LinkRefDefs["bar"]
+	if nil == def {
+		t.Fatalf("link ref def not found")
+	}
+	if 3 != def.StartPos.Line || 1 != def.StartPos.Column || 5 != def.StartPos.Offset || 24 != def.EndPos.Offset {
+		t.Fatalf("unexpected link ref def position [%+v, %+v]", def.StartPos, def.EndPos)
+	}
+}
@@ -0,0 +1,161 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+)
+
+type reparseTest struct {
+	name       string
+	from       string
+	start, end int
+	text       string
+}
+
+var reparseTests = []reparseTest{
+
+	{"16", "---\ntitle: a\n---\n\nfoo\n", 11, 12, "b"},
+	{"15", "foo\n\nbar\n", 0, 0, "---\ntitle: a\n---\n\n"},
+
+	{"14", "[foo]\n\n[foo]: /u\n\ntext [bar]\n", 7, 17, ""},
+	{"13", "# a\n\nfoo\n\n---\n\nbar\n\n---\n\nbaz\n", 15, 15, "x"},
+
+	{"12", "foo\n\n[^1]\n\nbar\n", 10, 10, "[^1]: baz\n"},
+	{"11", "[a]\n\n[b]: /b\n\nfoo\n", 6, 7, "a"},
+	{"10", "[a]\n\nfoo\n\nbar\n", 10, 10, "\n[a]: /a\n"},
+	{"9", "foo\n\n```\n\nbar\n\n# baz\n", 5, 8, "```go"},
+	{"8", "foo\n\nbar\n```\n\nbaz\n", 9, 13, ""},
+	{"7", "a\n\nfoo\n|a|\n|-|\n\nb\n", 7, 7, "x"},
+	{"6", "- a\n\nfoo\n\n- b\n", 5, 5, "  "},
+	{"5", "- a\n\n- b\n\n- c\n", 5, 5, "* "},
+	{"4", "> a\n\nb\n\nc\n", 5, 5, "> "},
+	{"3", "a\n\nb\n\nc\n", 4, 4, "===\n"},
+	{"2", "a\r\n\r\nb\r\n\r\nc\r\n", 6, 7, "# b"},
+	{"1", "a\n\nb\n\nc\n", 3, 4, ""},
+	{"0", "a\n\nb\n\nc\n", 3, 4, "*b*"},
+}
+
+func TestReparse(t *testing.T) {
+	testReparse(t, lute.New(), reparseTests)
+}
+
+var blockQueryEmbedReparseTests = []reparseTest{
+
+	{"3", "!{{ a\n\nb\n\nc\n\nd\n\ne\n", 15, 15, "}}\n"},
+	{"2", "!{{ a\n\nb\n\nc\n\nd\n\ne\n\n}}\n", 15, 18, ""},
+	{"1", "a\n\nb\n\nc\n\nd\n\ne\n\n}}\n", 3, 3, "!{{\n"},
+	{"0", "a\n\n!{{ b }}\n\nc\n", 3, 3, "!{{ x\n"},
+}
+
+func TestReparseBlockQueryEmbed(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetBlockRef(true)
+	testReparse(t, luteEngine, blockQueryEmbedReparseTests)
+}
+
+func testReparse(t *testing.T, luteEngine *lute.Lute, tests []reparseTest) {
+	for _, test := range tests {
+		tree := parse.Parse(test.name, []byte(test.from), luteEngine.Options)
+		if err := tree.Reparse(&parse.Edit{Start: test.start, End: test.end, Text: []byte(test.text)}); nil != err {
+			t.Fatalf("test case [%s] failed: %s", test.name, err)
+		}
+		edited := test.from[:test.start] + test.text + test.from[test.end:]
+		if string(tree.Source) != edited {
+			t.Fatalf("test case [%s] failed\nexpected source\n\t%q\ngot\n\t%q", test.name, edited, tree.Source)
+		}
+		expected := dumpTree(parse.Parse(test.name, []byte(edited), luteEngine.Options))
+		if got := dumpTree(tree); expected != got {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\nedited markdown text\n\t%q", test.name, expected, got, edited)
+		}
+	}
+}
+
+func TestReparseKeepsUnaffectedBlocks(t *testing.T) {
+	luteEngine := lute.New()
+	var markdown strings.Builder
+	for i := 0; i < 100; i++ {
+		markdown.WriteString(fmt.Sprintf("paragraph %d\n\n", i))
+	}
+	tree := parse.Parse("", []byte(markdown.String()), luteEngine.Options)
+	first, last := tree.Root.FirstChild, tree.Root.LastChild
+	offset := strings.Index(markdown.String(), "paragraph 50")
+	if err := tree.Reparse(&parse.Edit{Start: offset, End: offset, Text: []byte("edited\n")}); nil != err {
+		t.Fatal(err)
+	}
+	if first != tree.Root.FirstChild || last != tree.Root.LastChild {
+		t.Fatalf("unaffected blocks should be kept")
+	}
+	if 200 != last.StartPos.Line {
+		t.Fatalf("unexpected position of last block [%+v]", last.StartPos)
+	}
+}
+
+func TestReparseRandomEdits(t *testing.T) {
+	data, err := ioutil.ReadFile("commonmark-spec.md")
+	if nil != err {
+		t.Fatalf("read spec failed: " + err.Error())
+	}
+
+	luteEngine := lute.New()
+	tree := parse.Parse("", data[:20000], luteEngine.Options)
+	r := rand.New(rand.NewSource(1))
+	pieces := []string{"", "a", "\n", "\n\n", "\r\n", "- ", "1. ", "> ", "# ", "===\n", "***", "```", "    ", "<div>", "|a|\n|-|\n", "[foo]: /bar\n", "[^1]"}
+	for i := 0; i < 300; i++ {
+		start := r.Intn(len(tree.Source) + 1)
+		end := start + r.Intn(20)
+		if end > len(tree.Source) {
+			end = len(tree.Source)
+		}
+		text := pieces[r.Intn(len(pieces))]
+		if err := tree.Reparse(&parse.Edit{Start: start, End: end, Text: []byte(text)}); nil != err {
+			t.Fatal(err)
+		}
+		expected := dumpTree(parse.Parse("", tree.Source, luteEngine.Options))
+		if got := dumpTree(tree); expected != got {
+			t.Fatalf("edit [%d] [%d, %d) %q failed", i, start, end, text)
+		}
+	}
+}
+
+func TestReparseOutOfRange(t *testing.T) {
+	tree := parse.Parse("", []byte("foo\n"), lute.New().Options)
+	if err := tree.Reparse(&parse.Edit{Start: 2, End: 8}); parse.ErrEditOutOfRange != err {
+		t.Fatalf("expected out of range error but got [%v]", err)
+	}
+}
+
+// dumpTree 输出树上所有节点的类型、源码位置和 Tokens 以及诊断信息和 Front Matter，用于比较两棵树。
+func dumpTree(tree *parse.Tree) string {
+	var buf strings.Builder
+	ast.Walk(tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if entering {
+			buf.WriteString(fmt.Sprintf("%s %d:%d-%d:%d [%d,%d) %q\n", n.Type, n.StartPos.Line, n.StartPos.Column, n.EndPos.Line, n.EndPos.Column, n.StartPos.Offset, n.EndPos.Offset, n.Tokens))
+		}
+		return ast.WalkContinue
+	})
+	for _, d := range tree.Diagnostics {
+		buf.WriteString(d.String() + "\n")
+	}
+	if nil != tree.Err {
+		buf.WriteString(tree.Err.Error() + "\n")
+	}
+	buf.WriteString(fmt.Sprint(tree.FrontMatter))
+	return buf.String()
+}
@@ -11,9 +11,11 @@
 package test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/88250/lute"
+	"github.com/88250/lute/render"
 )
 
 var sanitizerTests = []parseTest{
@@ -62,3 +64,111 @@ func TestSanitizerVditor(t *testing.T) {
 		}
 	}
 }
+
+var sanitizerUGCTests = []parseTest{
+
+	{"7", "<p style=\"text-align: center; position: fixed; background: url(x.png)\">foo</p>", "<p style=\"text-align: center\">foo</p>\n"},
+	{"6", "<pre><code class=\"language-go evil\">foo</code></pre>", "<pre><code class=\"language-go\">foo</code></pre>\n"},
+	{"5", "<iframe src=\"https://www.youtube.com/embed/foo\">bar</iframe>", "bar\n"},
+	{"4", "<form action=\"/login\"><input name=\"foo\"></form>", ""},
+	{"3", "<a href=\"/foo\" rel=\"opener\" target=\"_blank\">foo</a>", "<a href=\"/foo\" rel=\"nofollow noopener\">foo</a>\n"},
+	{"2", "<a href=\"javascript:alert(1)\">foo</a>", "<p><a>foo</a></p>\n"},
+	{"1", "[foo](javascript:alert(1)) ![bar](data:image/svg+xml;base64,foo)", "<p><a>foo</a> <img alt=\"bar\" /></p>\n"},
+	{"0", "[foo](https://b3log.org \"bar\")", "<p><a href=\"https://b3log.org\" title=\"bar\" rel=\"nofollow noopener\">foo</a></p>\n"},
+}
+
+func TestSanitizerUGC(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetSanitizePolicy(render.UGCPolicy())
+
+	for _, test := range sanitizerUGCTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+var sanitizerPolicyTests = []parseTest{
+
+	{"4", "<iframe src=\"//player.bilibili.com/player.html?aid=1\" onload=\"alert(1)\"></iframe><iframe src=\"https://evil.com/\">foo</iframe>", "<iframe src=\"//player.bilibili.com/player.html?aid=1\"></iframe>  \n"},
+	{"3", "<div class=\"foo bar-1 baz\" style=\"color: red\">foo</div>", "<div class=\"bar-1\">foo</div>\n"},
+	{"2", "<a href=\"ftp://b3log.org\">foo</a><a href=\"foo.html\">bar</a>", "<p><a href=\"ftp://b3log.org\" rel=\"nofollow noopener\">foo</a><a>bar</a></p>\n"},
+	{"1", "<script src=\"foo.js\" />foo", "foo\n"},
+	{"0", "<em>foo</em>", "<p><em>foo</em></p>\n"},
+}
+
+func TestSanitizerPolicy(t *testing.T) {
+	policy := render.NewSanitizePolicy().AllowElements("p", "em", "a", "div").AllowAttrs("href", "a").
+		AllowURLSchemes("ftp").RequireNoFollowNoOpener(true).AllowIframeHosts("bilibili.com").AllowClasses("bar-*")
+	luteEngine := lute.New()
+	luteEngine.SetSanitizePolicy(policy)
+
+	for _, test := range sanitizerPolicyTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+func TestSanitizerStrictAndTrusted(t *testing.T) {
+	markdown := "<div onclick=\"alert(1)\"><iframe src=\"https://evil.com/\"></iframe></div>\n\n[foo](javascript:alert(1))\n"
+
+	luteEngine := lute.New()
+	luteEngine.SetSanitizePolicy(render.StrictPolicy())
+	if html := luteEngine.MarkdownStr("", markdown); "<p>foo</p>\n" != html {
+		t.Fatalf("strict policy failed, got\n\t%q", html)
+	}
+
+	// 没有设置策略时使用 TrustedPolicy
+	expected := "<div><iframe src=\"https://evil.com/\"></iframe></div>\n<p><a href=\"javascript:alert(1)\">foo</a></p>\n"
+	luteEngine.SetSanitizePolicy(nil)
+	if html := luteEngine.MarkdownStr("", markdown); expected != html {
+		t.Fatalf("default policy failed, got\n\t%q", html)
+	}
+	luteEngine.SetSanitizePolicy(render.TrustedPolicy())
+	if html := luteEngine.MarkdownStr("", markdown); expected != html {
+		t.Fatalf("trusted policy failed, got\n\t%q", html)
+	}
+
+	// Vditor 渲染器同样使用配置的策略
+	luteEngine.SetSanitizePolicy(render.StrictPolicy())
+	if html := luteEngine.Md2VditorIRDOM("<em>foo</em>"); strings.Contains(html, "<em>foo</em>") {
+		t.Fatalf("strict policy in vditor failed, got\n\t%q", html)
+	}
+}
+
+func TestSanitizerUGCAllRenderers(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetSanitizePolicy(render.UGCPolicy())
+
+	renderers := []struct {
+		name   string
+		render func(string) string
+	}{
+		{"html", func(markdown string) string { return luteEngine.MarkdownStr("", markdown) }},
+		{"wysiwyg", luteEngine.Md2VditorDOM},
+		{"ir", luteEngine.Md2VditorIRDOM},
+		{"ir-block", luteEngine.Md2VditorIRBlockDOM},
+		{"sv", luteEngine.Md2VditorSVDOM},
+	}
+	markdowns := []string{
+		"[x](javascript:alert(1))",
+		"![x](javascript:alert(1))",
+		"<a href=\"javascript:alert(1)\">x</a>",
+		"foo <a href=\"javascript:alert(1)\">x</a> bar",
+		"<div>\n<img src=\"javascript:alert(1)\" onerror=\"alert(1)\">\n</div>",
+		"foo <img src=\"x\" onerror=\"alert(1)\"> bar",
+	}
+	for _, renderer := range renderers {
+		for i, markdown := range markdowns {
+			html := renderer.render(markdown)
+			for _, unsafe := range []string{"href=\"javascript:", "src=\"javascript:", "onerror=\""} {
+				if strings.Contains(html, unsafe) {
+					t.Fatalf("test case [%s %d] failed\ngot\n\t%q\noriginal markdown text\n\t%q", renderer.name, i, html, markdown)
+				}
+			}
+		}
+	}
+}
@@ -18,6 +18,8 @@ import (
 
 var spinVditorIRDOMTests = []*parseTest{
 
+	{"52", "<div data-block=\"0\" data-type=\"yaml-front-matter\" class=\"vditor-ir__node\"><span data-type=\"yaml-front-matter-open-marker\">{</span><pre class=\"vditor-ir__marker--pre\"><code data-type=\"yaml-front-matter\" class=\"language-json\">  \"foo\": 1<wbr></code></pre><span data-type=\"yaml-front-matter-close-marker\">}</span></div>", "<div data-block=\"0\" data-type=\"yaml-front-matter\" class=\"vditor-ir__node vditor-ir__node--expand\"><span data-type=\"yaml-front-matter-open-marker\">{</span><pre class=\"vditor-ir__marker--pre\"><code data-type=\"yaml-front-matter\" class=\"language-json\">  &quot;foo&quot;: 1<wbr></code></pre><span data-type=\"yaml-front-matter-close-marker\">}</span></div>"},
+	{"51", "<div data-block=\"0\" data-type=\"yaml-front-matter\" class=\"vditor-ir__node\"><span data-type=\"yaml-front-matter-open-marker\">+++</span><pre class=\"vditor-ir__marker--pre\"><code data-type=\"yaml-front-matter\" class=\"language-toml\">foo = 1<wbr></code></pre><span data-type=\"yaml-front-matter-close-marker\">+++</span></div>", "<div data-block=\"0\" data-type=\"yaml-front-matter\" class=\"vditor-ir__node vditor-ir__node--expand\"><span data-type=\"yaml-front-matter-open-marker\">+++</span><pre class=\"vditor-ir__marker--pre\"><code data-type=\"yaml-front-matter\" class=\"language-toml\">foo = 1<wbr></code></pre><span data-type=\"yaml-front-matter-close-marker\">+++</span></div>"},
 	{"50", "<blockquote data-block=\"0\"><p data-block=\"0\"><wbr><br></p></blockquote>", "<blockquote data-block=\"0\"><p data-block=\"0\"><wbr></p></blockquote>"},
 	{"49", "<p data-block=\"0\"><span data-type=\"inline-math\" class=\"vditor-ir__node\"><span class=\"vditor-ir__marker\">$<wbr></span><span class=\"vditor-ir__marker\">$</span></span></p>", "<p data-block=\"0\"><span data-type=\"inline-node\" class=\"vditor-ir__node vditor-ir__node--expand\"><span class=\"vditor-ir__marker\">$</span><code data-newline=\"1\" class=\"vditor-ir__marker vditor-ir__marker--pre\" data-type=\"math-inline\"><wbr></code><span class=\"vditor-ir__preview\" data-render=\"2\"><code class=\"language-math\"></code></span><span class=\"vditor-ir__marker\">$</span></span></p>"},
 	{"48", "<p data-block=\"0\">foo<span data-type=\"backslash\" class=\"vditor-ir__node\"><span class=\"vditor-ir__marker vditor-ir__marker--bi\">\\</span>*</span>bar<wbr></p>", "<p data-block=\"0\">foo<span data-type=\"backslash\" class=\"vditor-ir__node\"><span class=\"vditor-ir__marker vditor-ir__marker--bi\">\\</span>*</span>bar<wbr></p>"},
@@ -74,6 +76,8 @@ var spinVditorIRDOMTests = []*parseTest{
 
 func TestSpinVditorIRDOM(t *testing.T) {
 	luteEngine := lute.New()
+	luteEngine.SetTomlFrontMatter(true)
+	luteEngine.SetJSONFrontMatter(true)
 	luteEngine.ToC = true
 	luteEngine.Sanitize = true
 	luteEngine.Mark = true
@@ -18,6 +18,8 @@ import (
 
 var spinVditorSVDOMTests = []*parseTest{
 
+	{"63", "{\n  \"title\": \"Hello\"\n}‸\n", "<span data-type=\"yaml-front-matter-open-marker\" class=\"vditor-sv__marker\">{</span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span><span data-type=\"text\">  &quot;title&quot;: &quot;Hello&quot;</span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span><span data-type=\"yaml-front-matter-close-marker\" class=\"vditor-sv__marker\">}</span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span>"},
+	{"62", "+++\ntitle = \"Hello\"\n+++‸\n", "<span data-type=\"yaml-front-matter-open-marker\" class=\"vditor-sv__marker\">+++</span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span><span data-type=\"text\">title = &quot;Hello&quot;</span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span><span data-type=\"yaml-front-matter-close-marker\" class=\"vditor-sv__marker\">+++</span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span>"},
 	{"61", "![text][foo]\n\n[foo]: bar", "<span class=\"vditor-sv__marker\">!</span><span class=\"vditor-sv__marker--bracket\">[</span><span class=\"vditor-sv__marker--bracket\">text</span><span class=\"vditor-sv__marker--bracket\">]</span><span class=\"vditor-sv__marker--link\">[foo]</span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span><span class=\"vditor-sv__marker--bracket\">[</span><span class=\"vditor-sv__marker--link\" data-type=\"footnotes-link\">foo</span><span class=\"vditor-sv__marker--bracket\">]</span><span>: </span>bar<span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span>"},
 	{"60", "foo\n‸\n===", "<span data-type=\"text\" class=\"h1\">foo</span><span data-type=\"newline\" class=\"h1\"><br/><span style=\"display: none\">\n</span></span><span data-type=\"text\" class=\"h1\"><wbr></span><span data-type=\"newline\" class=\"h1\"><br/><span style=\"display: none\">\n</span></span><span class=\"vditor-sv__marker--heading h1\" data-type=\"heading-marker\">===</span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span>"},
 	{"59", "foo\n‸--", "<span data-type=\"text\" class=\"h2\">foo<wbr></span><span data-type=\"newline\" class=\"h2\"><br/><span style=\"display: none\">\n</span></span><span class=\"vditor-sv__marker--heading h2\" data-type=\"heading-marker\">---</span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span><span data-type=\"newline\"><br /><span style=\"display: none\">\n</span></span>"},
@@ -84,6 +86,8 @@ var spinVditorSVDOMTests = []*parseTest{
 
 func TestSpinVditorSVDOM(t *testing.T) {
 	luteEngine := lute.New()
+	luteEngine.SetTomlFrontMatter(true)
+	luteEngine.SetJSONFrontMatter(true)
 	luteEngine.ToC = true
 	luteEngine.Mark = true
 
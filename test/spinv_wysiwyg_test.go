@@ -18,6 +18,8 @@ import (
 
 var spinVditorDOMTests = []*parseTest{
 
+	{"149", "<div class=\"vditor-wysiwyg__block\" data-type=\"yaml-front-matter\" data-block=\"0\" data-marker=\"{\"><pre><code data-type=\"yaml-front-matter\">  \"foo\": 1<wbr></code></pre></div>", "<div class=\"vditor-wysiwyg__block\" data-type=\"yaml-front-matter\" data-block=\"0\" data-marker=\"{\"><pre><code data-type=\"yaml-front-matter\">  &quot;foo&quot;: 1<wbr></code></pre></div>"},
+	{"148", "<div class=\"vditor-wysiwyg__block\" data-type=\"yaml-front-matter\" data-block=\"0\" data-marker=\"+++\"><pre><code data-type=\"yaml-front-matter\">foo = 1<wbr></code></pre></div>", "<div class=\"vditor-wysiwyg__block\" data-type=\"yaml-front-matter\" data-block=\"0\" data-marker=\"+++\"><pre><code data-type=\"yaml-front-matter\">foo = 1<wbr></code></pre></div>"},
 	{"147", "<p data-block=\"0\"><span comment-id-1 data-type=\"comment\">f</span><span comment-id-1 comment-id-2 data-type=\"comment\">o</span><span comment-id-2 data-type=\"comment\">o</span></p>\n", "<p data-block=\"0\"><span comment-id-1 data-type=\"comment\">f</span><span comment-id-1 comment-id-2 data-type=\"comment\">o</span><span comment-id-2 data-type=\"comment\">o</span></p>"},
 	{"146", "<p data-block=\"0\"><span comment-id-1 data-type=\"comment\">f<wbr></span></p>\n", "<p data-block=\"0\"><span comment-id-1 data-type=\"comment\">f<wbr></span></p>"},
 	{"145", "<p data-block=\"0\"><mark data-marker=\"==\">markf<wbr></mark></p>", "<p data-block=\"0\">\u200b<mark data-marker=\"==\">markf<wbr></mark>\u200b</p>"},
@@ -62,7 +64,7 @@ var spinVditorDOMTests = []*parseTest{
 	{"107", "<div class=\"toc-div\" data-type=\"toc-block\"><span class=\"toc-h1\"><a class=\"toc-a\" href=\"#foo\">foo</a></span><br></div>\n\n<h1 data-block=\"0\" data-marker=\"#\">foo</h1>", "<div class=\"vditor-toc\" data-block=\"0\" data-type=\"toc-block\" contenteditable=\"false\"><span data-type=\"toc-h\">foo</span><br></div><p data-block=\"0\"></p><h1 data-block=\"0\" id=\"wysiwyg-foo\" data-marker=\"#\">foo</h1>"},
 	{"106", "<p data-block=\"0\"><sup data-type=\"footnotes-ref\" data-footnotes-label=\"^1\">1</sup></p><div data-block=\"0\" data-type=\"footnotes-block\"><ol data-type=\"footnotes-defs-ol\"><li data-type=\"footnotes-li\" data-marker=\"^1\">bar</li></ol></div>", "<p data-block=\"0\">\u200b<sup data-type=\"footnotes-ref\" data-footnotes-label=\"^1\" class=\"b3-tooltips b3-tooltips__s\" aria-label=\"bar\">1</sup>\u200b</p><div data-block=\"0\" data-type=\"footnotes-block\"><ol data-type=\"footnotes-defs-ol\"><li data-type=\"footnotes-li\" data-marker=\"^1\"><p data-block=\"0\">bar</p></li></ol></div>"},
 	{"105", "<p data-block=\"0\"><span data-type=\"link-ref\" data-link-text=\"1\" data-link-label=\"1\">1</span></p><p data-block=\"0\" data-type=\"link-ref-defs\">[1]: f<wbr></p>", "<p data-block=\"0\">\u200b<span data-type=\"link-ref\" data-link-label=\"1\">1</span>\u200b</p><div data-block=\"0\" data-type=\"link-ref-defs-block\">[1]: f<wbr>\n</div>"},
-	{"104", "<a href=\"\" title=\"baz\">foo</a>", "<p data-block=\"0\"><a href=\"\"baz\"\">foo</a></p>"},
+	{"104", "<a href=\"\" title=\"baz\">foo</a>", "<p data-block=\"0\"><a href=\"&#34;baz&#34;\">foo</a></p>"},
 	{"103", "<p data-block=\"0\"><strong data-marker=\"**\">foo\n<em data-marker=\"*\">ba<wbr></em></strong></p>", "<p data-block=\"0\"><strong data-marker=\"**\">foo\n<em data-marker=\"*\">ba<wbr></em></strong></p>"},
 	{"102", "<p data-block=\"0\"><strong data-marker=\"**\">foo<em>\u200b\nb<wbr></em></strong></p>", "<p data-block=\"0\"><strong data-marker=\"**\">foo\n<em data-marker=\"*\">b<wbr></em></strong></p>"},
 	// 101：hr 已由 Vditor 处理
@@ -172,6 +174,8 @@ var spinVditorDOMTests = []*parseTest{
 
 func TestSpinVditorDOM(t *testing.T) {
 	luteEngine := lute.New()
+	luteEngine.SetTomlFrontMatter(true)
+	luteEngine.SetJSONFrontMatter(true)
 	luteEngine.ToC = true
 	luteEngine.Sanitize = true
 	luteEngine.Mark = true
@@ -0,0 +1,141 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+)
+
+// countWriter 记录写入次数，用于确认输出是按块写入的。
+type countWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+var errWrite = errors.New("write failed")
+
+type errWriter struct{}
+
+func (w errWriter) Write(p []byte) (int, error) {
+	return 0, errWrite
+}
+
+var streamTests = []parseTest{
+
+	{"4", "foo[^1]\n\n[^1]: bar\n\n* baz\n", ""},
+	{"3", "[foo]\n\n[foo]: bar\n", ""},
+	{"2", "---\ntitle: foo\n---\n\n# bar\n\n```go\nbaz\n```\n\n|a|b|\n|-|-|\n|1|2|\n", ""},
+	{"1", "\n\n  foo  \n\n\n> bar\n\n  \n", ""},
+	{"0", "", ""},
+}
+
+func TestMarkdownTo(t *testing.T) {
+	data, err := ioutil.ReadFile("commonmark-spec.json")
+	if nil != err {
+		t.Fatalf("read spec test cases failed: " + err.Error())
+	}
+	var testcases []testcase
+	if err = json.Unmarshal(data, &testcases); nil != err {
+		t.Fatalf("read spec test caes failed: " + err.Error())
+	}
+	tests := streamTests
+	for _, test := range testcases {
+		tests = append(tests, parseTest{"spec" + strconv.Itoa(test.Example), test.Markdown, ""})
+	}
+
+	luteEngine := lute.New()
+	for _, test := range tests {
+		expected := luteEngine.Markdown(test.name, []byte(test.from))
+		w := &bytes.Buffer{}
+		if err := luteEngine.MarkdownTo(w, test.name, strings.NewReader(test.from)); nil != err {
+			t.Fatalf("test case [%s] failed: %s", test.name, err)
+		}
+		if !bytes.Equal(expected, w.Bytes()) {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, expected, w.Bytes(), test.from)
+		}
+	}
+
+	w := &countWriter{}
+	if err := luteEngine.MarkdownTo(w, "", strings.NewReader("foo\n\nbar\n\nbaz\n")); nil != err {
+		t.Fatalf("render failed: %s", err)
+	}
+	if 3 != w.writes {
+		t.Fatalf("expected 3 writes but got [%d]", w.writes)
+	}
+
+	if err := luteEngine.MarkdownTo(errWriter{}, "", strings.NewReader("foo\n\nbar\n")); errWrite != err {
+		t.Fatalf("expected write error but got [%v]", err)
+	}
+}
+
+func TestFormatTo(t *testing.T) {
+	luteEngine := lute.New()
+	tests := []parseTest{{"format", "中文English\n\n\n* foo\n* bar\n\n\n", ""}}
+	tests = append(tests, streamTests...)
+	for _, test := range formatTests {
+		tests = append(tests, parseTest{"format" + test.name, test.original, ""})
+	}
+	for _, test := range tests {
+		expected := luteEngine.Format(test.name, []byte(test.from))
+		w := &bytes.Buffer{}
+		if err := luteEngine.FormatTo(w, test.name, strings.NewReader(test.from)); nil != err {
+			t.Fatalf("test case [%s] failed: %s", test.name, err)
+		}
+		if !bytes.Equal(expected, w.Bytes()) {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, expected, w.Bytes(), test.from)
+		}
+	}
+}
+
+var nodeIDRegexp = regexp.MustCompile(`data-node-id="[^"]*"`)
+
+func TestVditorRenderTo(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.VditorWYSIWYG = true
+	newRenderers := map[string]func(tree *parse.Tree) render.StreamRenderer{
+		"ir":       func(tree *parse.Tree) render.StreamRenderer { return render.NewVditorIRRenderer(tree) },
+		"irblock":  func(tree *parse.Tree) render.StreamRenderer { return render.NewVditorIRBlockRenderer(tree) },
+		"wysiwyg":  func(tree *parse.Tree) render.StreamRenderer { return render.NewVditorRenderer(tree) },
+		"sv":       func(tree *parse.Tree) render.StreamRenderer { return render.NewVditorSVRenderer(tree) },
+		"format":   func(tree *parse.Tree) render.StreamRenderer { return render.NewFormatRenderer(tree) },
+		"markdown": func(tree *parse.Tree) render.StreamRenderer { return render.NewHtmlRenderer(tree) },
+	}
+	for _, test := range streamTests {
+		for name, newRenderer := range newRenderers {
+			expected := newRenderer(parse.Parse(test.name, []byte(test.from), luteEngine.Options)).Render()
+			expected = nodeIDRegexp.ReplaceAll(expected, nil)
+			w := &bytes.Buffer{}
+			if err := newRenderer(parse.Parse(test.name, []byte(test.from), luteEngine.Options)).RenderTo(w); nil != err {
+				t.Fatalf("test case [%s %s] failed: %s", name, test.name, err)
+			}
+			got := nodeIDRegexp.ReplaceAll(w.Bytes(), nil)
+			if !bytes.Equal(expected, got) {
+				t.Fatalf("test case [%s %s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", name, test.name, expected, got, test.from)
+			}
+		}
+	}
+}
@@ -11,6 +11,7 @@
 package test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/88250/lute"
@@ -32,3 +33,42 @@ func TestToC(t *testing.T) {
 		}
 	}
 }
+
+var tocNumberingTests = []parseTest{
+
+	{"2", "[toc]\n\n# A {#a}\n\n## A1\n", "<div class=\"vditor-toc\"><ul><li class=\"toc-h1\"><a class=\"toc-a\" href=\"#a\">1. A</a><ul><li class=\"toc-h2\"><a class=\"toc-a\" href=\"#1-1-A1\">1.1 A1</a></li></ul></li></ul></div>\n<h1 id=\"a\">1. A</h1>\n<h2 id=\"1-1-A1\">1.1 A1</h2>\n"},
+	{"1", "[toc]\n\n# A\n\n### A1\n\n## A2\n\n### A2a\n\n# B\n", "<div class=\"vditor-toc\"><ul><li class=\"toc-h1\"><a class=\"toc-a\" href=\"#1-A\">1. A</a><ul><li class=\"toc-h3\"><a class=\"toc-a\" href=\"#1-1-A1\">1.1 A1</a></li><li class=\"toc-h2\"><a class=\"toc-a\" href=\"#1-2-A2\">1.2 A2</a></li></ul></li><li class=\"toc-h1\"><a class=\"toc-a\" href=\"#2-B\">2. B</a></li></ul></div>\n<h1 id=\"1-A\">1. A</h1>\n<h3 id=\"1-1-A1\">1.1 A1</h3>\n<h2 id=\"1-2-A2\">1.2 A2</h2>\n<h3 id=\"1-2-1-A2a\">1.2.1 A2a</h3>\n<h1 id=\"2-B\">2. B</h1>\n"},
+	{"0", "> # quoted\n\n## A\n\n# B\n", "<blockquote>\n<h1 id=\"quoted\">quoted</h1>\n</blockquote>\n<h2 id=\"1-A\">1. A</h2>\n<h1 id=\"2-B\">2. B</h1>\n"},
+}
+
+func TestToCNumbering(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetToC(true)
+	luteEngine.SetHeadingNumbering(true)
+	luteEngine.SetToCList("ul")
+	luteEngine.SetToCDepth(2)
+
+	for _, test := range tocNumberingTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+var outlineTests = []parseTest{
+
+	{"1", "# A\n\n### A1\n\n## A2\n\n# B {#b}\n", `[{"Level":1,"Text":"A","ID":"A","Position":{"Line":1,"Column":1,"Offset":0},"Children":[{"Level":3,"Text":"A1","ID":"A1","Position":{"Line":3,"Column":1,"Offset":5}},{"Level":2,"Text":"A2","ID":"A2","Position":{"Line":5,"Column":1,"Offset":13}}]},{"Level":1,"Text":"B","ID":"b","Position":{"Line":7,"Column":1,"Offset":20}}]`},
+	{"0", "foo\n", "null"},
+}
+
+func TestOutline(t *testing.T) {
+	luteEngine := lute.New()
+
+	for _, test := range outlineTests {
+		outline, _ := json.Marshal(luteEngine.Outline([]byte(test.from)))
+		if test.to != string(outline) {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, outline, test.from)
+		}
+	}
+}
@@ -11,18 +11,30 @@
 package test
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/88250/lute"
+	"github.com/88250/lute/parse"
 )
 
 var yamlFrontMatterTests = []parseTest{
 
+	{"8", "+++\ntitle = \"a\"\n```\n+++\n```\n", "<p>+++<br />\ntitle = &quot;a&quot;</p>\n<pre><code class=\"highlight-chroma\">+++\n</code></pre>\n"},
+	{"7", "{\n\"a\": 1\n\n```\n}\n```\n", "<p>{<br />\n&quot;a&quot;: 1</p>\n<pre><code class=\"highlight-chroma\">}\n</code></pre>\n"},
+	{"6", "+++\n\nIntro paragraph.\n\n+++\n\nmore\n", "<p>+++</p>\n<p>Intro paragraph.</p>\n<p>+++</p>\n<p>more</p>\n"},
+	{"5", "{\nnot json at all\n\n# Title\n\n```\nfunction f() {\n}\n```\n\ntail\n", "<p>{<br />\nnot JSON at all</p>\n<h1 id=\"Title\">Title</h1>\n<pre><code class=\"highlight-chroma\">function f() {\n}\n</code></pre>\n<p>tail</p>\n"},
+	{"4", "{\nfoo\n", "<p>{<br />\nfoo</p>\n"},
+	{"3", "+++\nfoo\n", "<p>+++<br />\nfoo</p>\n"},
+	{"2", "{\n  \"title\": \"Hello World\"\n}\n\nfoo\n", "<p>foo</p>\n"},
+	{"1", "+++\ntitle = \"Hello World\"\n+++\n\nfoo\n", "<p>foo</p>\n"},
 	{"0", "---\ntitle: Hello World\n---\n", "<div class=\"vditor-yml-front-matter\">title: Hello World</div>\n"},
 }
 
 func TestYamlFrontMatter(t *testing.T) {
 	luteEngine := lute.New() // 默认已经开启 YAML Front Matter 支持
+	luteEngine.SetTomlFrontMatter(true)
+	luteEngine.SetJSONFrontMatter(true)
 
 	for _, test := range yamlFrontMatterTests {
 		html := luteEngine.MarkdownStr(test.name, test.from)
@@ -31,3 +43,137 @@ func TestYamlFrontMatter(t *testing.T) {
 		}
 	}
 }
+
+var frontMatterFormatTests = []formatTest{
+
+	{"2", "{\n  \"title\": \"Hello World\",\n  \"tags\": [\"a\", \"b\"]\n}\nfoo\n", "{\n  \"title\": \"Hello World\",\n  \"tags\": [\"a\", \"b\"]\n}\n\nfoo\n"},
+	{"1", "+++\ntitle = \"Hello World\"\n\n+++\nfoo\n", "+++\ntitle = \"Hello World\"\n+++\n\nfoo\n"},
+	{"0", "---\ntitle: Hello World\n---\nfoo\n", "---\ntitle: Hello World\n---\n\nfoo\n"},
+}
+
+func TestFrontMatterFormat(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetTomlFrontMatter(true)
+	luteEngine.SetJSONFrontMatter(true)
+
+	for _, test := range frontMatterFormatTests {
+		formatted := luteEngine.FormatStr(test.name, test.original)
+		if test.formatted != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.formatted, formatted, test.original)
+		}
+	}
+}
+
+func TestTomlJSONFrontMatterDisabled(t *testing.T) {
+	luteEngine := lute.New() // 默认不开启 TOML 和 JSON Front Matter 支持
+
+	html := luteEngine.MarkdownStr("", "+++\ntitle = \"a\"\n+++\n\nfoo\n")
+	if expected := "<p>+++<br />\ntitle = &quot;a&quot;<br />\n+++</p>\n<p>foo</p>\n"; expected != html {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, html)
+	}
+}
+
+var tomlDecodeTests = []parseTest{
+
+	{"5", "a = 1\na = 2\n", "toml: line 2: duplicate key [a]"},
+	{"4", "Intro paragraph.\n", "toml: line 1: expected [=]"},
+	{"3", "[[posts]]\nid = 1\n[[posts]]\nid = 2\n[posts.meta]\ndraft = false\n", "map[posts:[map[id:1] map[id:2 meta:map[draft:false]]]]"},
+	{"2", "s = \"\"\"\na \\\n  b\"\"\"\nl = '''\nc\\d'''\n", "map[l:c\\d s:a b]"},
+	{"1", "[author]\nname = 'D' # comment\nsite.url = \"http://b3log.org\"\n", "map[author:map[name:D site:map[url:http://b3log.org]]]"},
+	{"0", "title = \"Hello\\tWorld\"\ncount = 1_000\nratio = 0.5\ntags = [\"a\",\n  \"b\", ]\ndate = 2020-08-13T01:02:03Z\nday = 2020-08-13\nmeta = {x = 0x10, y = true}\n", "map[count:1000 date:2020-08-13 01:02:03 +0000 UTC day:2020-08-13 meta:map[x:16 y:true] ratio:0.5 tags:[a b] title:Hello\tWorld]"},
+}
+
+func TestDecodeToml(t *testing.T) {
+	for _, test := range tomlDecodeTests {
+		meta, err := parse.DecodeToml([]byte(test.from))
+		got := fmt.Sprint(meta)
+		if nil != err {
+			got = err.Error()
+		}
+		if test.to != got {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal toml text\n\t%q", test.name, test.to, got, test.from)
+		}
+	}
+}
+
+var yamlDecodeTests = []parseTest{
+
+	{"6", "a: b:c\nd: e # f: g\n", "map[a:b:c d:e]"},
+	{"5", "a: - b\n", "yaml: line 1: block sequence entries are not allowed here"},
+	{"4", "a: b:\n", "yaml: line 1: mapping values are not allowed here"},
+	{"3", "a: b: c\n", "yaml: line 1: mapping values are not allowed here"},
+	{"2", "a: |+\n  foo\n", "map[a:foo\n]"},
+	{"1", "a: >+\n  foo\n\nb: 1\n", "map[a:foo\n\n b:1]"},
+	{"0", "a: >+\n  foo\n\n", "map[a:foo\n\n]"},
+}
+
+func TestDecodeYaml(t *testing.T) {
+	for _, test := range yamlDecodeTests {
+		meta, err := parse.DecodeYaml([]byte(test.from))
+		got := fmt.Sprint(meta)
+		if nil != err {
+			got = err.Error()
+		}
+		if test.to != got {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal yaml text\n\t%q", test.name, test.to, got, test.from)
+		}
+	}
+}
+
+var yamlFrontMatterDecodeTests = []parseTest{
+
+	{"9", "---\ntitle: \"a\n  b\\\n  c\n\n  d\"\nnext: 1\n---\n", "map[next:1 title:a bc\nd]"},
+	{"8", "---\ntitle: 'it''s\n  ok'\n---\n", "map[title:it's ok]"},
+	{"7", "---\nlist:\n  - a\n    b # comment\n  - c\ndesc: a # comment\n  b: c\n---\n", "map[]"},
+	{"6", "---\ntitle: plain\n  continued\n\n  text\ndate: 2020-08-13\n---\n", "map[date:2020-08-13 00:00:00 +0000 UTC title:plain continued\ntext]"},
+
+	{"5", "---\ntitle: a\n  b: c\n---\n", "map[]"},
+	{"4", "---\ndesc: |\n  a\n  b\n\nfold: >-\n  a\n  b\n\n  c\n---\n", "map[desc:a\nb\n fold:a b\nc]"},
+	{"3", "---\nlist:\n- 1.5\n- - x\n  - y\n- k: v\n  k2: null\n---\n", "map[list:[1.5 [x y] map[k:v k2:<nil>]]]"},
+	{"2", "---\nauthor:\n  name: D # comment\n  site: http://b3log.org\n---\n", "map[author:map[name:D site:http://b3log.org]]"},
+	{"1", "---\ntags: [a, 'b c', 3, true]\nmeta: {x: 0x10, y: \"z\\n\"}\n---\n", "map[meta:map[x:16 y:z\n] tags:[a b c 3 true]]"},
+	{"0", "---\n# comment\ntitle: \"Hello: World\"\ndate: 2020-08-13\ncount: 010\n---\n", "map[count:10 date:2020-08-13 00:00:00 +0000 UTC title:Hello: World]"},
+}
+
+func TestYamlFrontMatterDecode(t *testing.T) {
+	luteEngine := lute.New()
+
+	for _, test := range yamlFrontMatterDecodeTests {
+		tree := parse.Parse(test.name, []byte(test.from), luteEngine.Options)
+		if meta := fmt.Sprint(tree.FrontMatter); test.to != meta {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, meta, test.from)
+		}
+	}
+}
+
+func TestYamlFrontMatterUpdate(t *testing.T) {
+	luteEngine := lute.New()
+
+	// 没有变化的键保留原文和注释，变化的键重新编码，删除的键连同前面的注释一起移除，新增的键追加到末尾
+	tree := parse.Parse("", []byte("---\n# site\ntitle: Hello\n# publish date\ndate: 2020-08-13\ntags: [a, b] # tags\n---\n\n# Body\n"), luteEngine.Options)
+	meta := tree.FrontMatter
+	meta["title"] = "true"
+	meta["tags"] = append(meta["tags"].([]interface{}), "c: d")
+	meta["draft"] = false
+	delete(meta, "date")
+	tree.SetFrontMatter(meta)
+	expected := "---\n# site\ntitle: \"true\"\ntags:\n  - a\n  - b\n  - \"c: d\"\ndraft: false\n---\n\n# Body\n"
+	if formatted := string(luteEngine.FormatTree(tree)); expected != formatted {
+		t.Fatalf("update front matter failed\nexpected\n\t%q\ngot\n\t%q", expected, formatted)
+	}
+	if reparsed := parse.Parse("", []byte(expected), luteEngine.Options); fmt.Sprint(meta) != fmt.Sprint(reparsed.FrontMatter) {
+		t.Fatalf("update front matter is not round-trip, got\n\t%v", reparsed.FrontMatter)
+	}
+
+	// 没有 Front Matter 的话新建，清空的话移除
+	tree = parse.Parse("", []byte("# Body\n"), luteEngine.Options)
+	tree.SetFrontMatter(map[string]interface{}{"author": map[string]string{"name": "D"}, "ratio": 1.0})
+	expected = "---\nauthor:\n  name: D\nratio: 1.0\n---\n\n# Body\n"
+	if formatted := string(luteEngine.FormatTree(tree)); expected != formatted {
+		t.Fatalf("add front matter failed\nexpected\n\t%q\ngot\n\t%q", expected, formatted)
+	}
+	tree.SetFrontMatter(nil)
+	if formatted := string(luteEngine.FormatTree(tree)); "# Body\n" != formatted {
+		t.Fatalf("remove front matter failed, got\n\t%q", formatted)
+	}
+}
@@ -25,3 +25,16 @@ func StrToBytes(str string) []byte {
 	h := [3]uintptr{x[0], x[1], x[1]}
 	return *(*[]byte)(unsafe.Pointer(&h))
 }
+
+// BytesOffset 返回 sub 在 tokens 中的起始下标，要求 sub 是 tokens 的子切片（共享底层数组），否则返回 -1。
+func BytesOffset(tokens, sub []byte) int {
+	if 1 > len(tokens) || 1 > len(sub) {
+		return -1
+	}
+	base := uintptr(unsafe.Pointer(&tokens[0]))
+	p := uintptr(unsafe.Pointer(&sub[0]))
+	if p < base || p+uintptr(len(sub)) > base+uintptr(len(tokens)) {
+		return -1
+	}
+	return int(p - base)
+}
@@ -19,3 +19,8 @@ func StrToBytes(str string) (ret []byte) {
 func BytesToStr(items []byte) string {
 	return string(items)
 }
+
+// BytesOffset 在 JavaScript 端无法比较底层数组，总是返回 -1。
+func BytesOffset(tokens, sub []byte) int {
+	return -1
+}
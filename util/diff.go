@@ -0,0 +1,248 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package util
+
+import (
+	"strconv"
+	"strings"
+)
+
+// 行差异操作类型。
+const (
+	DiffEqual  = iota // 相同
+	DiffDelete        // 仅在原文中存在
+	DiffInsert        // 仅在新文本中存在
+)
+
+// DiffLine 描述了一行差异。
+type DiffLine struct {
+	Op   int    // 操作类型
+	Text string // 行内容，包含行尾换行
+}
+
+// DiffLines 使用 Myers 算法按行比较 a 和 b，返回从 a 变为 b 的最短编辑脚本。
+func DiffLines(a, b []string) (ret []DiffLine) {
+	// 先去掉公共前后缀，减少需要搜索的范围
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	for _, line := range a[:prefix] {
+		ret = append(ret, DiffLine{DiffEqual, line})
+	}
+	ret = append(ret, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ret = append(ret, DiffLine{DiffEqual, line})
+	}
+	return
+}
+
+// maxDiffEdits 是 Myers 算法搜索的最大编辑距离，超出的话直接使用整体替换作为编辑脚本，避免比较差异很大的长文本时占用过多的内存和时间。
+const maxDiffEdits = 1024
+
+func myers(a, b []string) (ret []DiffLine) {
+	n, m := len(a), len(b)
+	max := n + m
+	if 0 == max {
+		return
+	}
+	if maxDiffEdits < max {
+		max = maxDiffEdits
+	}
+
+	offset := max + 1
+	v := make([]int, 2*max+2)
+	var trace [][]int // 每一步搜索前 v 中 [-d, d] 范围内的值
+	d := 0
+search:
+	for ; d <= max; d++ {
+		trace = append(trace, append([]int{}, v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+	if d > max {
+		return replaceLines(a, b)
+	}
+
+	// 回溯编辑路径
+	x, y := n, m
+	for ; 0 < d; d-- {
+		prev := trace[d] // prev[d+k] 为 v[k]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && prev[d+k-1] < prev[d+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := prev[d+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ret = append(ret, DiffLine{DiffEqual, a[x]})
+		}
+		if x == prevX {
+			y--
+			ret = append(ret, DiffLine{DiffInsert, b[y]})
+		} else {
+			x--
+			ret = append(ret, DiffLine{DiffDelete, a[x]})
+		}
+	}
+	for 0 < x && 0 < y {
+		x--
+		y--
+		ret = append(ret, DiffLine{DiffEqual, a[x]})
+	}
+
+	for i, j := 0, len(ret)-1; i < j; i, j = i+1, j-1 {
+		ret[i], ret[j] = ret[j], ret[i]
+	}
+	return
+}
+
+// replaceLines 返回删除 a 中所有行后插入 b 中所有行的编辑脚本。
+func replaceLines(a, b []string) (ret []DiffLine) {
+	for _, line := range a {
+		ret = append(ret, DiffLine{DiffDelete, line})
+	}
+	for _, line := range b {
+		ret = append(ret, DiffLine{DiffInsert, line})
+	}
+	return
+}
+
+// SplitLines 按行拆分 text，每行保留行尾的换行，最后一行没有换行的话也作为一行。
+func SplitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if 0 < len(lines) && "" == lines[len(lines)-1] {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// DiffHunk 描述了统一差异格式中的一个差异块，行号从 1 开始。
+type DiffHunk struct {
+	FromLine, FromCount int // 原文中的起始行号和行数
+	ToLine, ToCount     int // 新文本中的起始行号和行数
+	Lines               []DiffLine
+}
+
+// DiffHunks 将编辑脚本 diff 按照上下文行数 context 合并为差异块，间隔不超过两倍上下文行数的修改会合并到同一个差异块中。
+func DiffHunks(diff []DiffLine, context int) (ret []*DiffHunk) {
+	// 每一行在原文和新文本中对应的行号
+	fromLines, toLines := make([]int, len(diff)), make([]int, len(diff))
+	from, to := 1, 1
+	for i, line := range diff {
+		fromLines[i], toLines[i] = from, to
+		if DiffInsert != line.Op {
+			from++
+		}
+		if DiffDelete != line.Op {
+			to++
+		}
+	}
+
+	for i := 0; i < len(diff); {
+		if DiffEqual == diff[i].Op {
+			i++
+			continue
+		}
+
+		end := i // 差异块中最后一个修改行
+		for j := i + 1; j < len(diff) && j-end-1 <= 2*context; j++ {
+			if DiffEqual != diff[j].Op {
+				end = j
+			}
+		}
+		start, stop := i-context, end+context+1
+		if 0 > start {
+			start = 0
+		}
+		if len(diff) < stop {
+			stop = len(diff)
+		}
+
+		hunk := &DiffHunk{FromLine: fromLines[start], ToLine: toLines[start], Lines: diff[start:stop]}
+		for _, line := range hunk.Lines {
+			if DiffInsert != line.Op {
+				hunk.FromCount++
+			}
+			if DiffDelete != line.Op {
+				hunk.ToCount++
+			}
+		}
+		ret = append(ret, hunk)
+		i = stop
+	}
+	return
+}
+
+// UnifiedDiff 返回 a 和 b 按行比较的统一差异格式（unified diff）文本，context 为上下文行数，a 和 b 相同的话返回空字符串。
+func UnifiedDiff(fromName, toName, a, b string, context int) string {
+	hunks := DiffHunks(DiffLines(SplitLines(a), SplitLines(b)), context)
+	if 1 > len(hunks) {
+		return ""
+	}
+
+	buf := &strings.Builder{}
+	buf.WriteString("--- " + fromName + "\n")
+	buf.WriteString("+++ " + toName + "\n")
+	for _, hunk := range hunks {
+		buf.WriteString("@@ -" + hunkRange(hunk.FromLine, hunk.FromCount) + " +" + hunkRange(hunk.ToLine, hunk.ToCount) + " @@\n")
+		for _, line := range hunk.Lines {
+			switch line.Op {
+			case DiffEqual:
+				buf.WriteByte(' ')
+			case DiffDelete:
+				buf.WriteByte('-')
+			case DiffInsert:
+				buf.WriteByte('+')
+			}
+			buf.WriteString(line.Text)
+			if !strings.HasSuffix(line.Text, "\n") {
+				buf.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+	return buf.String()
+}
+
+func hunkRange(line, count int) string {
+	if 0 == count {
+		line-- // 空范围使用前一行的行号
+	}
+	if 1 == count {
+		return strconv.Itoa(line)
+	}
+	return strconv.Itoa(line) + "," + strconv.Itoa(count)
+}
@@ -21,17 +21,13 @@ import (
 func RecoverPanic(err *error) {
 	if e := recover(); nil != e {
 		stack := debug.Stack()
-		errMsg := ""
-		switch x := e.(type) {
-		case error:
-			errMsg = x.Error()
-		case string:
-			errMsg = x
-		default:
-			errMsg = "unknown panic"
-		}
 		if nil != err {
-			*err = errors.New("PANIC RECOVERED: " + errMsg + "\n\t" + string(stack) + "\n")
+			*err = errors.New("PANIC RECOVERED: " + PanicMessage(e) + "\n\t" + string(stack) + "\n")
 		}
 	}
 }
+
+// PanicStack 返回当前协程的调用栈，用于记录 panic 发生的位置。
+func PanicStack() string {
+	return string(debug.Stack())
+}
@@ -15,3 +15,8 @@ package util
 // Recover recovers a panic.
 func RecoverPanic(err *error) {
 }
+
+// PanicStack 在 JavaScript 端无法获取调用栈，返回空字符串。
+func PanicStack() string {
+	return ""
+}
@@ -25,6 +25,7 @@ import (
 
 // SpinVditorIRDOM 自旋 Vditor Instant-Rendering DOM，用于即时渲染模式下的编辑。
 func (lute *Lute) SpinVditorIRDOM(ivHTML string) (ovHTML string) {
+	lute = lute.fork()
 	lute.VditorIR = true
 	lute.VditorWYSIWYG = false
 	lute.VditorSV = false
@@ -45,6 +46,7 @@ func (lute *Lute) SpinVditorIRDOM(ivHTML string) (ovHTML string) {
 
 // HTML2VditorIRDOM 将 HTML 转换为 Vditor Instant-Rendering DOM，用于即时渲染模式下粘贴。
 func (lute *Lute) HTML2VditorIRDOM(sHTML string) (vHTML string) {
+	lute = lute.fork()
 	lute.VditorIR = true
 	lute.VditorWYSIWYG = false
 	lute.VditorSV = false
@@ -70,6 +72,7 @@ func (lute *Lute) HTML2VditorIRDOM(sHTML string) (vHTML string) {
 
 // VditorIRDOM2HTML 将 Vditor Instant-Rendering DOM 转换为 HTML，用于 Vditor.getHTML() 接口。
 func (lute *Lute) VditorIRDOM2HTML(vhtml string) (sHTML string) {
+	lute = lute.fork()
 	lute.VditorIR = true
 	lute.VditorWYSIWYG = false
 	lute.VditorSV = false
@@ -81,17 +84,52 @@ func (lute *Lute) VditorIRDOM2HTML(vhtml string) (sHTML string) {
 
 // Md2VditorIRDOM 将 markdown 转换为 Vditor Instant-Rendering DOM，用于从源码模式切换至即时渲染模式。
 func (lute *Lute) Md2VditorIRDOM(markdown string) (vHTML string) {
+	lute = lute.fork()
 	lute.VditorIR = true
 	lute.VditorWYSIWYG = false
 	lute.VditorSV = false
 
 	tree := parse.Parse("", []byte(markdown), lute.Options)
-	renderer := render.NewVditorIRRenderer(tree)
+	vHTML = lute.renderVditorIRDOM(lute.newVditorIRRenderer(tree))
+	return
+}
+
+// Md2VditorIRDOME 和 Md2VditorIRDOM 一样将 markdown 转换为 Vditor Instant-Rendering DOM，但是会恢复处理过程中发生的 panic 并返回错误。
+//
+// 解析失败返回 *parse.ParseError，渲染失败返回 *render.RenderError，其中包含了引发 panic 的节点类型，超出资源限制返回 *parse.LimitError。
+func (lute *Lute) Md2VditorIRDOME(markdown string) (vHTML string, err error) {
+	lute = lute.fork()
+	lute.VditorIR = true
+	lute.VditorWYSIWYG = false
+	lute.VditorSV = false
+
+	tree, err := parse.ParseE("", []byte(markdown), lute.Options)
+	if nil != err {
+		return
+	}
+
+	renderer := lute.newVditorIRRenderer(tree)
+	defer renderer.Recover(&err)
+	vHTML = lute.renderVditorIRDOM(renderer)
+	if err = renderer.Err(); nil != err {
+		vHTML = ""
+	}
+	return
+}
+
+// newVditorIRRenderer 构造用于渲染语法树 tree 的 Vditor Instant-Rendering DOM 渲染器，并注册用户自定义的渲染函数。
+func (lute *Lute) newVditorIRRenderer(tree *parse.Tree) (ret *render.VditorIRRenderer) {
+	ret = render.NewVditorIRRenderer(tree)
 	for nodeType, rendererFunc := range lute.Md2VditorIRDOMRendererFuncs {
-		renderer.ExtRendererFuncs[nodeType] = rendererFunc
+		ret.ExtRendererFuncs[nodeType] = rendererFunc
 	}
+	return
+}
+
+// renderVditorIRDOM 使用渲染器 renderer 渲染 Vditor Instant-Rendering DOM，开启脚注的话会在末尾追加脚注定义。
+func (lute *Lute) renderVditorIRDOM(renderer *render.VditorIRRenderer) (vHTML string) {
 	output := renderer.Render()
-	if renderer.Option.Footnotes && 0 < len(renderer.Tree.Context.FootnotesDefs) {
+	if renderer.Option.Footnotes && 0 < len(renderer.Tree.Context.FootnotesDefs) && nil == renderer.Err() {
 		output = renderer.RenderFootnotesDefs(renderer.Tree.Context)
 	}
 	vHTML = string(output)
@@ -100,6 +138,7 @@ func (lute *Lute) Md2VditorIRDOM(markdown string) (vHTML string) {
 
 // VditorIRDOM2Md 将 Vditor Instant-Rendering DOM 转换为 markdown，用于从即时渲染模式切换至源码模式。
 func (lute *Lute) VditorIRDOM2Md(htmlStr string) (markdown string) {
+	lute = lute.fork()
 	lute.VditorIR = true
 	lute.VditorWYSIWYG = false
 	lute.VditorSV = false
@@ -224,9 +263,24 @@ func (lute *Lute) genASTByVditorIRDOM(n *html.Node, tree *parse.Tree) {
 					tree.Context.Tip.AppendChild(node)
 				}
 			}
+		} else if "block-query-embed" == dataType {
+			text := lute.domText(n)
+			if node := lute.blockQueryEmbedByText(text); nil != node {
+				tree.Context.Tip.AppendChild(node)
+			} else {
+				tree.Context.Tip.AppendChild(&ast.Node{Type: ast.NodeText, Tokens: []byte(text)})
+			}
 		} else if "toc-block" == dataType {
 			node := &ast.Node{Type: ast.NodeText, Tokens: []byte("[toc]\n\n")}
 			tree.Context.Tip.AppendChild(node)
+		} else if "admonition" == dataType {
+			node := lute.admonitionByDOM(n)
+			tree.Context.Tip.AppendChild(node)
+			tree.Context.Tip = node
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				lute.genASTByVditorIRDOM(c, tree)
+			}
+			tree.Context.ParentTip()
 		} else {
 			text := lute.domText(n)
 			if util.Caret+"\n" == text { // 处理 FireFox 某些情况下产生的分段
@@ -697,12 +751,13 @@ func (lute *Lute) genASTByVditorIRDOM(n *html.Node, tree *parse.Tree) {
 			tree.Context.Tip = node
 			return
 		case "yaml-front-matter-close-marker":
-			tree.Context.Tip.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterCloseMarker, Tokens: parse.YamlFrontMatterMarker})
+			tree.Context.Tip.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterCloseMarker, Tokens: parse.FrontMatterCloseMarker(tree.Context.Tip.FrontMatterType)})
 			defer tree.Context.ParentTip()
 			return
 		case "yaml-front-matter-open-marker":
 			node.Type = ast.NodeYamlFrontMatter
-			node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterOpenMarker, Tokens: parse.YamlFrontMatterMarker})
+			node.FrontMatterType = parse.FrontMatterTypeByMarker([]byte(lute.domText(n)))
+			node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterOpenMarker, Tokens: parse.FrontMatterOpenMarker(node.FrontMatterType)})
 			tree.Context.Tip.AppendChild(node)
 			tree.Context.Tip = node
 			return
@@ -25,6 +25,7 @@ import (
 
 // SpinVditorIRBlockDOM 自旋 Vditor Instant-Rendering Block DOM，用于即时渲染块模式下的编辑。
 func (lute *Lute) SpinVditorIRBlockDOM(ivHTML string) (ovHTML string) {
+	lute = lute.fork()
 	lute.VditorIR = true
 	lute.VditorWYSIWYG = false
 	lute.VditorSV = false
@@ -43,6 +44,7 @@ func (lute *Lute) SpinVditorIRBlockDOM(ivHTML string) (ovHTML string) {
 
 // HTML2VditorIRBlockDOM 将 HTML 转换为 Vditor Instant-Rendering Block DOM，用于即时渲染块模式下粘贴。
 func (lute *Lute) HTML2VditorIRBlockDOM(sHTML string) (vHTML string) {
+	lute = lute.fork()
 	lute.VditorIR = true
 	lute.VditorWYSIWYG = false
 	lute.VditorSV = false
@@ -68,6 +70,7 @@ func (lute *Lute) HTML2VditorIRBlockDOM(sHTML string) (vHTML string) {
 
 // VditorIRBlockDOM2HTML 将 Vditor Instant-Rendering Block DOM 转换为 HTML，用于 Vditor.getHTML() 接口。
 func (lute *Lute) VditorIRBlockDOM2HTML(vhtml string) (sHTML string) {
+	lute = lute.fork()
 	lute.VditorIR = true
 	lute.VditorWYSIWYG = false
 	lute.VditorSV = false
@@ -79,6 +82,7 @@ func (lute *Lute) VditorIRBlockDOM2HTML(vhtml string) (sHTML string) {
 
 // Md2VditorIRBlockDOM 将 markdown 转换为 Vditor Instant-Rendering Block DOM，用于从源码模式切换至即时渲染块模式。
 func (lute *Lute) Md2VditorIRBlockDOM(markdown string) (vHTML string) {
+	lute = lute.fork()
 	lute.VditorIR = true
 	lute.VditorWYSIWYG = false
 	lute.VditorSV = false
@@ -98,6 +102,7 @@ func (lute *Lute) Md2VditorIRBlockDOM(markdown string) (vHTML string) {
 
 // VditorIRBlockDOM2Md 将 Vditor Instant-Rendering DOM 转换为 markdown，用于从即时渲染块模式切换至源码模式。
 func (lute *Lute) VditorIRBlockDOM2Md(htmlStr string) (markdown string) {
+	lute = lute.fork()
 	lute.VditorIR = true
 	lute.VditorWYSIWYG = false
 	lute.VditorSV = false
@@ -109,6 +114,7 @@ func (lute *Lute) VditorIRBlockDOM2Md(htmlStr string) (markdown string) {
 }
 
 func (lute *Lute) VditorIRBlockDOM2Text(htmlStr string) (text string) {
+	lute = lute.fork()
 	lute.VditorIR = true
 	lute.VditorWYSIWYG = false
 	lute.VditorSV = false
@@ -282,6 +288,21 @@ func (lute *Lute) genASTByVditorIRBlockDOM(n *html.Node, tree *parse.Tree) {
 			node := &ast.Node{Type: ast.NodeText, Tokens: []byte("[toc]\n\n")}
 			tree.Context.Tip.AppendChild(node)
 			return
+		} else if "admonition" == dataType {
+			node := lute.admonitionByDOM(n)
+			node.ID = lute.domAttrValue(n, "data-node-id")
+			if "" == node.ID {
+				node.ID = ast.NewNodeID()
+			}
+			node.KramdownIAL = [][]string{{"id", node.ID}}
+			tree.Context.Tip.AppendChild(node)
+			tree.Context.Tip = node
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				lute.genASTByVditorIRBlockDOM(c, tree)
+			}
+			tree.Context.ParentTip()
+			tree.Context.TipAppendChild(&ast.Node{Type: ast.NodeKramdownBlockIAL, Tokens: []byte("{: id=\"" + node.ID + "\"}")})
+			return
 		}
 	}
 
@@ -994,11 +1015,12 @@ func (lute *Lute) genASTByVditorIRBlockDOM(n *html.Node, tree *parse.Tree) {
 			tree.Context.Tip.AppendChild(node)
 			return
 		case "yaml-front-matter-close-marker":
-			tree.Context.Tip.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterCloseMarker, Tokens: parse.YamlFrontMatterMarker})
+			tree.Context.Tip.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterCloseMarker, Tokens: parse.FrontMatterCloseMarker(tree.Context.Tip.FrontMatterType)})
 			return
 		case "yaml-front-matter-open-marker":
+			tree.Context.Tip.FrontMatterType = parse.FrontMatterTypeByMarker([]byte(lute.domText(n)))
 			node.Type = ast.NodeYamlFrontMatterOpenMarker
-			node.Tokens = parse.MathBlockMarker
+			node.Tokens = parse.FrontMatterOpenMarker(tree.Context.Tip.FrontMatterType)
 			tree.Context.Tip.AppendChild(node)
 			return
 		case "code-block-open-marker":
@@ -1076,6 +1098,17 @@ func (lute *Lute) genASTByVditorIRBlockDOM(n *html.Node, tree *parse.Tree) {
 			tree.Context.Tip.AppendChild(node)
 			tree.Context.Tip = node
 			defer tree.Context.ParentTip()
+		case "block-query-embed":
+			text := lute.domText(n)
+			if blockQueryEmbed := lute.blockQueryEmbedByText(text); nil != blockQueryEmbed {
+				blockQueryEmbed.KramdownIAL, blockQueryEmbed.ID = node.KramdownIAL, node.ID
+				tree.Context.Tip.AppendChild(blockQueryEmbed)
+				return
+			}
+			node.Type = ast.NodeText
+			node.Tokens = []byte(text)
+			tree.Context.Tip.AppendChild(node)
+			return
 		case "block-ref-embed":
 			text := lute.domText(n)
 			if "" == text {
@@ -25,6 +25,7 @@ func (lute *Lute) SpinVditorSVDOM(markdown string) (ovHTML string) {
 		return "<span data-type=\"text\"><wbr></span>" + string(render.NewlineSV)
 	}
 
+	lute = lute.fork()
 	lute.VditorSV = true
 	lute.VditorWYSIWYG = false
 	lute.VditorIR = false
@@ -43,6 +44,7 @@ func (lute *Lute) SpinVditorSVDOM(markdown string) (ovHTML string) {
 
 // HTML2VditorSVDOM 将 HTML 转换为 Vditor Split-View DOM，用于分屏预览模式下粘贴。
 func (lute *Lute) HTML2VditorSVDOM(sHTML string) (vHTML string) {
+	lute = lute.fork()
 	lute.VditorSV = true
 	lute.VditorWYSIWYG = false
 	lute.VditorIR = false
@@ -68,6 +70,7 @@ func (lute *Lute) HTML2VditorSVDOM(sHTML string) (vHTML string) {
 
 // Md2VditorSVDOM 将 markdown 转换为 Vditor Split-View DOM，用于从源码模式切换至分屏预览模式。
 func (lute *Lute) Md2VditorSVDOM(markdown string) (vHTML string) {
+	lute = lute.fork()
 	lute.VditorSV = true
 	lute.VditorWYSIWYG = false
 	lute.VditorIR = false
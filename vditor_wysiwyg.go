@@ -25,6 +25,7 @@ import (
 
 // Md2HTML 将 markdown 转换为标准 HTML，用于源码模式预览。
 func (lute *Lute) Md2HTML(markdown string) (sHTML string) {
+	lute = lute.fork()
 	lute.VditorWYSIWYG = false
 	sHTML = lute.MarkdownStr("", markdown)
 	return
@@ -32,6 +33,7 @@ func (lute *Lute) Md2HTML(markdown string) (sHTML string) {
 
 // SpinVditorDOM 自旋 Vditor DOM，用于所见即所得模式下的编辑。
 func (lute *Lute) SpinVditorDOM(ivHTML string) (ovHTML string) {
+	lute = lute.fork()
 	lute.VditorWYSIWYG = true
 	lute.VditorIR = false
 	lute.VditorSV = false
@@ -52,6 +54,7 @@ func (lute *Lute) SpinVditorDOM(ivHTML string) (ovHTML string) {
 
 // HTML2VditorDOM 将 HTML 转换为 Vditor DOM，用于所见即所得模式下粘贴。
 func (lute *Lute) HTML2VditorDOM(sHTML string) (vHTML string) {
+	lute = lute.fork()
 	lute.VditorWYSIWYG = true
 	lute.VditorIR = false
 	lute.VditorSV = false
@@ -77,6 +80,7 @@ func (lute *Lute) HTML2VditorDOM(sHTML string) (vHTML string) {
 
 // VditorDOM2HTML 将 Vditor DOM 转换为 HTML，用于 Vditor.getHTML() 接口。
 func (lute *Lute) VditorDOM2HTML(vhtml string) (sHTML string) {
+	lute = lute.fork()
 	lute.VditorWYSIWYG = true
 	lute.VditorIR = false
 	lute.VditorSV = false
@@ -88,6 +92,7 @@ func (lute *Lute) VditorDOM2HTML(vhtml string) (sHTML string) {
 
 // Md2VditorDOM 将 markdown 转换为 Vditor DOM，用于从源码模式切换至所见即所得模式。
 func (lute *Lute) Md2VditorDOM(markdown string) (vHTML string) {
+	lute = lute.fork()
 	lute.VditorWYSIWYG = true
 	lute.VditorIR = false
 	lute.VditorSV = false
@@ -107,6 +112,7 @@ func (lute *Lute) Md2VditorDOM(markdown string) (vHTML string) {
 
 // VditorDOM2Md 将 Vditor DOM 转换为 markdown，用于从所见即所得模式切换至源码模式。
 func (lute *Lute) VditorDOM2Md(htmlStr string) (markdown string) {
+	lute = lute.fork()
 	lute.VditorWYSIWYG = true
 	lute.VditorIR = false
 	lute.VditorSV = false
@@ -248,6 +254,9 @@ func (lute *Lute) searchEmptyNodes(n *html.Node, emptyNodes *[]*html.Node) {
 		if nil != parent && (atom.Ol == parent.DataAtom || atom.Ul == parent.DataAtom || atom.Li == parent.DataAtom) {
 			n.Data = strings.TrimRight(n.Data, "\n\t ")
 		}
+		if nil != parent && (atom.Dl == parent.DataAtom || atom.Dt == parent.DataAtom || atom.Dd == parent.DataAtom) && "" == strings.TrimSpace(n.Data) {
+			n.Data = ""
+		}
 		if nil != parent && (atom.Table == parent.DataAtom || atom.Thead == parent.DataAtom || atom.Tbody == parent.DataAtom || atom.Tr == parent.DataAtom) {
 			n.Data = strings.TrimSpace(n.Data)
 		}
@@ -407,7 +416,7 @@ func (lute *Lute) genASTByVditorDOM(n *html.Node, tree *parse.Tree) {
 	dataType := lute.domAttrValue(n, "data-type")
 
 	if atom.Div == n.DataAtom {
-		if "code-block" == dataType || "html-block" == dataType || "math-block" == dataType || "yaml-front-matter" == dataType {
+		if "code-block" == dataType || "html-block" == dataType || "math-block" == dataType || "yaml-front-matter" == dataType || "block-query-embed" == dataType {
 			for c := n.FirstChild; c != nil; c = c.NextSibling {
 				lute.genASTByVditorDOM(c, tree)
 			}
@@ -451,6 +460,14 @@ func (lute *Lute) genASTByVditorDOM(n *html.Node, tree *parse.Tree) {
 		} else if "toc-block" == dataType {
 			node := &ast.Node{Type: ast.NodeText, Tokens: []byte("[toc]\n\n")}
 			tree.Context.Tip.AppendChild(node)
+		} else if "admonition" == dataType {
+			node := lute.admonitionByDOM(n)
+			tree.Context.Tip.AppendChild(node)
+			tree.Context.Tip = node
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				lute.genASTByVditorDOM(c, tree)
+			}
+			tree.Context.ParentTip()
 		}
 		return
 	}
@@ -637,6 +654,7 @@ func (lute *Lute) genASTByVditorDOM(n *html.Node, tree *parse.Tree) {
 				tree.Context.Tip.AppendChild(node)
 			case "yaml-front-matter":
 				node.Type = ast.NodeYamlFrontMatter
+				node.FrontMatterType = parse.FrontMatterTypeByMarker([]byte(lute.domAttrValue(n.Parent, "data-marker")))
 				node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterOpenMarker})
 				node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterContent, Tokens: codeTokens})
 				node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterCloseMarker})
@@ -645,6 +663,10 @@ func (lute *Lute) genASTByVditorDOM(n *html.Node, tree *parse.Tree) {
 				node.Type = ast.NodeHTMLBlock
 				node.Tokens = codeTokens
 				tree.Context.Tip.AppendChild(node)
+			case "block-query-embed":
+				if embed := lute.blockQueryEmbedByText("!{{" + string(codeTokens) + "}}\n"); nil != embed {
+					tree.Context.Tip.AppendChild(embed)
+				}
 			default:
 				node.Type = ast.NodeCodeBlock
 				node.IsFencedCodeBlock = true
@@ -1242,6 +1264,30 @@ func (lute *Lute) genASTByVditorDOM(n *html.Node, tree *parse.Tree) {
 	}
 }
 
+// admonitionByDOM 根据 Vditor DOM 提示块节点 n 上的 data-admonition、data-title 和 data-marker 属性生成提示块节点。
+func (lute *Lute) admonitionByDOM(n *html.Node) (ret *ast.Node) {
+	ret = &ast.Node{Type: ast.NodeAdmonition, AdmonitionType: lute.domAttrValue(n, "data-admonition")}
+	ret.AdmonitionTitle = []byte(lute.domAttrValue(n, "data-title"))
+	if marker := lute.domAttrValue(n, "data-marker"); ">" != marker {
+		ret.AdmonitionFenceLen = len(marker)
+		if 3 > ret.AdmonitionFenceLen {
+			ret.AdmonitionFenceLen = 3
+		}
+	}
+	return
+}
+
+// blockQueryEmbedByText 将 Vditor DOM 中内容块查询嵌入的文本 text 重新解析为内容块查询嵌入节点，解析失败的话返回 nil。
+func (lute *Lute) blockQueryEmbedByText(text string) *ast.Node {
+	text = strings.ReplaceAll(text, parse.Zwsp, "")
+	tree := parse.Parse("", []byte(text), lute.Options)
+	if embed := tree.Root.FirstChild; nil != embed && ast.NodeBlockQueryEmbed == embed.Type {
+		embed.Unlink()
+		return embed
+	}
+	return nil
+}
+
 func (lute *Lute) hasAttr(n *html.Node, attrName string) bool {
 	for _, attr := range n.Attr {
 		if attr.Key == attrName {